@@ -0,0 +1,198 @@
+/*
+ * accounting.go - accounting-style formatting and parsing of negative amounts
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strconv"
+    "strings"
+)
+
+// style of negative amounts
+type NegativeStyle uint8
+
+const (
+    // minus sign of locale ('-1,234.56')
+    NegativeSign NegativeStyle = iota
+    // parentheses ('(1,234.56)')
+    NegativeParentheses
+    // trailing minus sign ('1,234.56-')
+    NegativeTrailingMinus
+    // credit marker after negative amount ('1,234.56 CR'). Markers are
+    // English abbreviations and are not localized
+    NegativeCredit
+    // credit marker after negative amount and debit marker after
+    // positive amount ('1,234.56 CR' and '1,234.56 DR')
+    NegativeCreditDebit
+)
+
+// return true if style uses minus sign of locale before amount
+func (style NegativeStyle) leadingSign() bool {
+    return style==NegativeSign
+}
+
+// append opening part of amount
+func (style NegativeStyle) appendOpen(dst []byte, negative bool) []byte {
+    if negative && style==NegativeParentheses { dst = append(dst, '(') }
+    return dst
+}
+
+// append closing part of amount
+func (style NegativeStyle) appendClose(dst []byte, ls *LocSymbols,
+                    negative bool) []byte {
+    switch style {
+    case NegativeParentheses:
+        if negative { dst = append(dst, ')') }
+    case NegativeTrailingMinus:
+        if negative { dst = appendRune(dst, ls.minusSign()) }
+    case NegativeCredit, NegativeCreditDebit:
+        if negative {
+            dst = append(dst, " CR"...)
+        } else if style==NegativeCreditDebit {
+            dst = append(dst, " DR"...)
+        }
+    }
+    return dst
+}
+
+// append formatted amount in accounting style to dst and return extended
+// buffer. a is magnitude of amount and negative is set if amount is negative.
+func (a UDec128) AppendFormatAccounting(dst []byte, precision, displayPrecision uint,
+                    trimZeroes, negative bool, style NegativeStyle) []byte {
+    if style.leadingSign() && negative { dst = append(dst, '-') }
+    dst = style.appendOpen(dst, negative)
+    dst = a.AppendFormat(dst, precision, displayPrecision, trimZeroes)
+    return style.appendClose(dst, &plainLocFmt.Symbols, negative)
+}
+
+// format amount in accounting style
+func (a UDec128) FormatAccounting(precision, displayPrecision uint,
+                    trimZeroes, negative bool, style NegativeStyle) string {
+    var buf [64]byte
+    return string(a.AppendFormatAccounting(buf[:0], precision, displayPrecision,
+                                           trimZeroes, negative, style))
+}
+
+// append formatted amount in accounting style including locale to dst
+// and return extended buffer (like '(1.234,56)' or '1 234,56 CR').
+// CR and DR markers are not localized.
+func (a UDec128) AppendLocaleFormatAccounting(dst []byte, lang string,
+                    precision, displayPrecision uint, trimZeroes, noSep1000,
+                    negative bool, style NegativeStyle) []byte {
+    l := getLocFmt(lang)
+    var sign rune
+    if style.leadingSign() && negative { sign = l.Symbols.minusSign() }
+    dst = style.appendOpen(dst, negative)
+    dst = l.Symbols.appendPrefix(dst, sign, StyleDecimal, false)
+    dst = a.AppendLocaleFormat(dst, lang, precision, displayPrecision,
+                               trimZeroes, noSep1000)
+    dst = l.Symbols.appendSuffix(dst, sign, StyleDecimal, false)
+    return style.appendClose(dst, &l.Symbols, negative)
+}
+
+// format amount in accounting style including locale
+func (a UDec128) LocaleFormatAccounting(lang string, precision, displayPrecision uint,
+                    trimZeroes, noSep1000, negative bool, style NegativeStyle) string {
+    var buf [128]byte
+    return string(a.AppendLocaleFormatAccounting(buf[:0], lang, precision,
+                    displayPrecision, trimZeroes, noSep1000, negative, style))
+}
+
+// return true if string has suffix ignoring case of ASCII letters
+func hasSuffixFold(str, suffix string) bool {
+    return len(str)>=len(suffix) && strings.EqualFold(str[len(str)-len(suffix):], suffix)
+}
+
+// strip accounting markers: parentheses, CR and DR (English only for
+// all locales). Return string without
+// markers, true if marker has been found, true if marker denotes negative
+// amount and false if markers are malformed.
+func stripAccountingMarkers(str string) (string, bool, bool, bool) {
+    str = strings.TrimFunc(str, isSpaceRune)
+    switch {
+    case strings.HasPrefix(str, "("):
+        if !strings.HasSuffix(str, ")") { return str, false, false, false }
+        str = strings.TrimFunc(str[1:len(str)-1], isSpaceRune)
+        return str, true, true, !strings.ContainsAny(str, "()")
+    case strings.HasSuffix(str, ")"):
+        return str, false, false, false
+    case hasSuffixFold(str, "CR"):
+        return strings.TrimRightFunc(str[:len(str)-2], isSpaceRune), true, true, true
+    case hasSuffixFold(str, "DR"):
+        return strings.TrimRightFunc(str[:len(str)-2], isSpaceRune), true, false, true
+    }
+    return str, false, false, true
+}
+
+// parse amount in accounting style: with parentheses, with trailing minus,
+// with CR or DR marker or with sign. Return magnitude, true if amount is
+// negative and error (nil if no error).
+func localeParseUDec128Accounting(l *LocFmt, g Grouping, fg FracGrouping,
+                    str string, precision uint, rounding bool,
+                    style NumberStyle, strict bool) (UDec128, bool, error) {
+    str, marked, markNegative, ok := stripAccountingMarkers(str)
+    if !ok {
+        if strict { return UDec128{}, false, &ParseError{ 0, "unmatched parenthesis" } }
+        return UDec128{}, false, strconv.ErrSyntax
+    }
+    v, negative, err := localeParseUDec128Style(l, g, fg, str, precision, rounding,
+                                                style, strict)
+    if err!=nil { return UDec128{}, false, err }
+    if !marked { return v, negative, nil }
+    if negative {
+        // sign and marker together
+        if strict { return UDec128{}, false, &ParseError{ 0, "repeated sign" } }
+        return UDec128{}, false, strconv.ErrSyntax
+    }
+    return v, markNegative, nil
+}
+
+// parse amount in accounting style (like '(1,234.56)', '1,234.56-',
+// '1234.56 CR' or '1234.56 DR'). Comma can be used as thousand separator.
+// Return magnitude, true if amount is negative and error (nil if no error).
+func ParseUDec128Accounting(str string, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    return localeParseUDec128Accounting(englishLocFmt, GroupingThousands,
+                        FracGrouping{}, str, precision, rounding, StyleDecimal, false)
+}
+
+// parse amount in accounting style from bytes
+func ParseUDec128AccountingBytes(str []byte, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    return ParseUDec128Accounting(string(str), precision, rounding)
+}
+
+// parse amount in accounting style including locale (like '(1.234,56)').
+// CR and DR markers are recognized in English for all locales.
+// Return magnitude, true if amount is negative and error (nil if no error).
+func LocaleParseUDec128Accounting(lang, str string, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    l := getLocFmt(lang)
+    return localeParseUDec128Accounting(l, l.Grouping, l.FracGrouping, str,
+                        precision, rounding, StyleDecimal, false)
+}
+
+// parse amount in accounting style including locale from bytes
+func LocaleParseUDec128AccountingBytes(lang string, str []byte, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    return LocaleParseUDec128Accounting(lang, string(str), precision, rounding)
+}
@@ -0,0 +1,184 @@
+/*
+ * accounting_test.go - tests for accounting-style negative amounts
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type UDec128FmtAccountingTC struct {
+    lang string
+    value UDec128
+    negative bool
+    style NegativeStyle
+    expected string
+}
+
+func TestUDec128FormatAccounting(t *testing.T) {
+    testCases := []UDec128FmtAccountingTC {
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, true, NegativeSign,
+                    "-1234.56" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, true, NegativeParentheses,
+                    "(1234.56)" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, false, NegativeParentheses,
+                    "1234.56" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, true, NegativeTrailingMinus,
+                    "1234.56-" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, true, NegativeCredit,
+                    "1234.56 CR" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, false, NegativeCredit,
+                    "1234.56" },
+        UDec128FmtAccountingTC{ "", UDec128{ 123456, 0 }, false, NegativeCreditDebit,
+                    "1234.56 DR" },
+        UDec128FmtAccountingTC{ "en", UDec128{ 123456, 0 }, true, NegativeSign,
+                    "-1,234.56" },
+        UDec128FmtAccountingTC{ "en", UDec128{ 123456, 0 }, true, NegativeParentheses,
+                    "(1,234.56)" },
+        UDec128FmtAccountingTC{ "de", UDec128{ 123456, 0 }, true, NegativeParentheses,
+                    "(1.234,56)" },
+        UDec128FmtAccountingTC{ "de", UDec128{ 123456, 0 }, true, NegativeTrailingMinus,
+                    "1.234,56-" },
+        UDec128FmtAccountingTC{ "fi", UDec128{ 123456, 0 }, true, NegativeTrailingMinus,
+                    "1\u00a0234,56−" },
+        UDec128FmtAccountingTC{ "fi", UDec128{ 123456, 0 }, true, NegativeSign,
+                    "−1\u00a0234,56" },
+        UDec128FmtAccountingTC{ "pl", UDec128{ 123456, 0 }, true, NegativeCredit,
+                    "1234,56 CR" },
+        UDec128FmtAccountingTC{ "en", UDec128{ 0, 0 }, false, NegativeCreditDebit,
+                    "0.0 DR" },
+    }
+    for i, tc := range testCases {
+        var result string
+        var v UDec128
+        var negative bool
+        var err error
+        if tc.lang=="" {
+            result = tc.value.FormatAccounting(2, 2, false, tc.negative, tc.style)
+            v, negative, err = ParseUDec128Accounting(result, 2, false)
+        } else {
+            result = tc.value.LocaleFormatAccounting(tc.lang, 2, 2, false, false,
+                                                     tc.negative, tc.style)
+            v, negative, err = LocaleParseUDec128Accounting(tc.lang, result, 2, false)
+        }
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.value, tc.expected, result)
+        }
+        if tc.value!=v || tc.negative!=negative || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v!=%v,%v,%v",
+                     i, result, tc.value, tc.negative, v, negative, err)
+        }
+    }
+}
+
+type UDec128ParseAccountingTC struct {
+    lang, str string
+    expected UDec128
+    negative bool
+    err error
+}
+
+func TestParseUDec128Accounting(t *testing.T) {
+    testCases := []UDec128ParseAccountingTC {
+        UDec128ParseAccountingTC{ "", "(12.5)", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", " ( 12.5 ) ", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "12.5-", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "-12.5", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "12.5", UDec128{ 1250, 0 }, false, nil },
+        UDec128ParseAccountingTC{ "", "12.5CR", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "12.5 cr", UDec128{ 1250, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "12.5 Dr", UDec128{ 1250, 0 }, false, nil },
+        UDec128ParseAccountingTC{ "", "(1,234.56)", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "1,234.56-", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "1,234.56 CR", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "", "(12.5", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "12.5)", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "((12.5))", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "(-12.5)", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "12.5- CR", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "()", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "CR", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "", "12.5 CR DR", UDec128{}, false, strconv.ErrSyntax },
+        UDec128ParseAccountingTC{ "en", "(1,234.56)", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "en", "1,234.56 CR", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "de", "1.234,56-", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "fr", "(1 234,56)", UDec128{ 123456, 0 }, true, nil },
+        UDec128ParseAccountingTC{ "fr", "1 234,56 DR", UDec128{ 123456, 0 }, false, nil },
+    }
+    for i, tc := range testCases {
+        var result UDec128
+        var negative bool
+        var err error
+        if tc.lang=="" {
+            result, negative, err = ParseUDec128AccountingBytes([]byte(tc.str), 2, false)
+        } else {
+            result, negative, err = LocaleParseUDec128AccountingBytes(tc.lang,
+                            []byte(tc.str), 2, false)
+        }
+        if tc.expected!=result || tc.negative!=negative || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v,%v!=%v,%v,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.err, result, negative, err)
+        }
+    }
+}
+
+func TestFormatterParserAccounting(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Lang: "en", Precision: 2, DisplayPrecision: 2,
+                Negative: NegativeParentheses, Width: 12 })
+    if result := f.FormatSigned(UDec128{ 123456, 0 }, true); result!="  (1,234.56)" {
+        t.Errorf("Result mismatch: parentheses: %q", result)
+    }
+    f = NewFormatter(FormatterOptions{ Precision: 2, DisplayPrecision: 2,
+                Negative: NegativeCreditDebit })
+    if result := f.FormatSigned(UDec128{ 5, 0 }, false); result!="0.05 DR" {
+        t.Errorf("Result mismatch: debit: %q", result)
+    }
+    f = NewFormatter(FormatterOptions{ Lang: "de", Precision: 2, DisplayPrecision: 2,
+                Negative: NegativeTrailingMinus, Style: StylePercent })
+    if result := f.FormatSigned(UDec128{ 5, 0 }, true); result!="5,00\u00a0%-" {
+        t.Errorf("Result mismatch: percent: %q", result)
+    }
+    p := NewParser(ParserOptions{ Lang: "en", Precision: 2, Accounting: true })
+    v, negative, err := p.ParseSigned("(1,234.56)")
+    if v!=(UDec128{ 123456, 0 }) || !negative || err!=nil {
+        t.Errorf("Result mismatch: parser: %v,%v,%v", v, negative, err)
+    }
+    if _, err := p.Parse("(1,234.56)"); err!=strconv.ErrSyntax {
+        t.Errorf("Error mismatch: parser unsigned: %v", err)
+    }
+    v, err = p.ParseBytes([]byte("1,234.56 DR"))
+    if v!=(UDec128{ 123456, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: parser debit: %v,%v", v, err)
+    }
+    p = NewParser(ParserOptions{ Precision: 2, Accounting: true, Strict: true })
+    if _, _, err := p.ParseSigned("(12.5"); err==nil {
+        t.Errorf("Error mismatch: strict: %v", err)
+    }
+    p = NewParser(ParserOptions{ Precision: 2, Accounting: true,
+                Normalize: NormalizeAll })
+    v, negative, err = p.ParseSigned("(１２.５)")
+    if v!=(UDec128{ 1250, 0 }) || !negative || err!=nil {
+        t.Errorf("Result mismatch: normalize: %v,%v,%v", v, negative, err)
+    }
+}
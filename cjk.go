@@ -0,0 +1,350 @@
+/*
+ * cjk.go - Chinese and Japanese numerals
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "strconv"
+    "unicode/utf8"
+)
+
+// style of Chinese or Japanese numerals
+type CJKStyle uint8
+
+const (
+    // simplified Chinese numerals (一万二千三百四十五点六七)
+    CJKSimplified CJKStyle = iota
+    // traditional Chinese numerals (一萬二千三百四十五點六七)
+    CJKTraditional
+    // Japanese numerals (一万二千三百四十五・六七)
+    CJKJapanese
+    // simplified Chinese financial numerals (daxie: 壹万贰仟叁佰肆拾伍)
+    CJKSimplifiedFinancial
+    // traditional Chinese financial numerals (daxie: 壹萬貳仟參佰肆拾伍)
+    CJKTraditionalFinancial
+    // Japanese financial numerals (daiji: 壱萬弐千参百四拾五)
+    CJKJapaneseFinancial
+)
+
+// set of numerals
+type cjkNumerals struct {
+    digits [10]rune
+    // ten, hundred, thousand
+    small [3]rune
+    // myriad units: 10^4, 10^8, ..., 10^36
+    units [9]rune
+    point rune
+    // put zero in gaps (Chinese)
+    zeroGap bool
+    // omit one before ten, hundred and thousand (Japanese)
+    omitOne bool
+    // omit one before ten at start of number (Chinese)
+    omitOneTen bool
+}
+
+var cjkNumeralSets = [...]cjkNumerals{
+    CJKSimplified: cjkNumerals{
+        digits: [10]rune{ '零', '一', '二', '三', '四', '五', '六', '七', '八', '九' },
+        small: [3]rune{ '十', '百', '千' },
+        units: [9]rune{ '万', '亿', '兆', '京', '垓', '秭', '穰', '沟', '涧' },
+        point: '点', zeroGap: true, omitOneTen: true },
+    CJKTraditional: cjkNumerals{
+        digits: [10]rune{ '零', '一', '二', '三', '四', '五', '六', '七', '八', '九' },
+        small: [3]rune{ '十', '百', '千' },
+        units: [9]rune{ '萬', '億', '兆', '京', '垓', '秭', '穰', '溝', '澗' },
+        point: '點', zeroGap: true, omitOneTen: true },
+    CJKJapanese: cjkNumerals{
+        digits: [10]rune{ '〇', '一', '二', '三', '四', '五', '六', '七', '八', '九' },
+        small: [3]rune{ '十', '百', '千' },
+        units: [9]rune{ '万', '億', '兆', '京', '垓', '𥝱', '穣', '溝', '澗' },
+        point: '・', omitOne: true },
+    CJKSimplifiedFinancial: cjkNumerals{
+        digits: [10]rune{ '零', '壹', '贰', '叁', '肆', '伍', '陆', '柒', '捌', '玖' },
+        small: [3]rune{ '拾', '佰', '仟' },
+        units: [9]rune{ '万', '亿', '兆', '京', '垓', '秭', '穰', '沟', '涧' },
+        point: '点', zeroGap: true },
+    CJKTraditionalFinancial: cjkNumerals{
+        digits: [10]rune{ '零', '壹', '貳', '參', '肆', '伍', '陸', '柒', '捌', '玖' },
+        small: [3]rune{ '拾', '佰', '仟' },
+        units: [9]rune{ '萬', '億', '兆', '京', '垓', '秭', '穰', '溝', '澗' },
+        point: '點', zeroGap: true },
+    CJKJapaneseFinancial: cjkNumerals{
+        digits: [10]rune{ '零', '壱', '弐', '参', '四', '五', '六', '七', '八', '九' },
+        small: [3]rune{ '拾', '百', '千' },
+        units: [9]rune{ '萬', '億', '兆', '京', '垓', '𥝱', '穣', '溝', '澗' },
+        point: '・' },
+}
+
+func (style CJKStyle) numerals() *cjkNumerals {
+    if int(style)>=len(cjkNumeralSets) { return &cjkNumeralSets[CJKSimplified] }
+    return &cjkNumeralSets[style]
+}
+
+// append integer part given as ASCII digits (without leading zeroes)
+func (n *cjkNumerals) appendInt(dst []byte, s []byte) []byte {
+    if len(s)==0 || (len(s)==1 && s[0]=='0') {
+        return appendRune(dst, n.digits[0])
+    }
+    started := false
+    // zero should be put before next digit
+    zero := false
+    for i := 0; i < len(s); i++ {
+        pos := len(s)-1-i
+        d := s[i]-'0'
+        if d==0 {
+            if started { zero = true }
+        } else {
+            if zero && n.zeroGap { dst = appendRune(dst, n.digits[0]) }
+            zero = false
+            small := pos&3
+            omit := small!=0 && d==1 && (n.omitOne ||
+                        (n.omitOneTen && !started && small==1))
+            if !omit { dst = appendRune(dst, n.digits[d]) }
+            if small!=0 { dst = appendRune(dst, n.small[small-1]) }
+            started = true
+        }
+        if pos!=0 && pos&3==0 {
+            // end of myriad group, put unit if group is not zero
+            groupStart := i-3
+            if groupStart<0 { groupStart = 0 }
+            if !bytes.Equal(s[groupStart:i+1], []byte("0000")[:i+1-groupStart]) {
+                dst = appendRune(dst, n.units[pos/4-1])
+                // unit replaces trailing zeroes of group
+                zero = false
+            }
+        }
+    }
+    return dst
+}
+
+// append number formatted with Chinese or Japanese numerals with myriad units
+// to dst and return extended buffer. Digits of fraction are put after point.
+func (a UDec128) AppendFormatCJK(dst []byte, precision, displayPrecision uint,
+                    trimZeroes bool, style CJKStyle) []byte {
+    n := style.numerals()
+    var buf [64]byte
+    s := a.AppendFormat(buf[:0], precision, displayPrecision, trimZeroes)
+    commaIdx := bytes.IndexByte(s, '.')
+    if commaIdx==-1 { commaIdx = len(s) }
+    dst = n.appendInt(dst, s[:commaIdx])
+    // AppendFormat keeps '.0' after trimming zeroes
+    if trimZeroes && commaIdx+2==len(s) && s[commaIdx+1]=='0' { return dst }
+    if commaIdx!=len(s) {
+        dst = appendRune(dst, n.point)
+        for _, c := range s[commaIdx+1:] {
+            dst = appendRune(dst, n.digits[c-'0'])
+        }
+    }
+    return dst
+}
+
+// format number with Chinese or Japanese numerals with myriad units
+func (a UDec128) FormatCJK(precision, displayPrecision uint, trimZeroes bool,
+                    style CJKStyle) string {
+    var buf [256]byte
+    return string(a.AppendFormatCJK(buf[:0], precision, displayPrecision,
+                                    trimZeroes, style))
+}
+
+// value of CJK rune: digit (0-9), small unit (10^1-10^3),
+// myriad unit (10^4-10^36) or point
+type cjkRuneKind uint8
+
+const (
+    cjkInvalid cjkRuneKind = iota
+    cjkDigit
+    cjkSmall
+    cjkUnit
+    cjkPoint
+)
+
+// return kind of rune and its value (digit or power of ten). Runes of all
+// styles and some variants ('两', '陌', '阡') are accepted
+func cjkRuneValue(r rune) (cjkRuneKind, int) {
+    if r>='0' && r<='9' { return cjkDigit, int(r-'0') }
+    switch r {
+    case '两', '兩':
+        return cjkDigit, 2
+    case '陌':
+        return cjkSmall, 2
+    case '阡':
+        return cjkSmall, 3
+    case '.', '．', '点', '點', '・':
+        return cjkPoint, 0
+    }
+    for i := range cjkNumeralSets {
+        n := &cjkNumeralSets[i]
+        for d, dr := range n.digits {
+            if r==dr { return cjkDigit, d }
+        }
+        for k, sr := range n.small {
+            if r==sr { return cjkSmall, k+1 }
+        }
+        for k, ur := range n.units {
+            if r==ur { return cjkUnit, (k+1)*4 }
+        }
+    }
+    return cjkInvalid, 0
+}
+
+// state of parsing CJK numerals
+type cjkParseState struct {
+    // digits of integer part (index is power of ten)
+    digits [40]byte
+    // digits of current myriad group (index is power of ten)
+    group [4]byte
+    // current digit, -1 if not given
+    digit int
+    // last small unit and last myriad unit (to check order)
+    lastSmall, lastUnit int
+    // myriad unit before last myriad unit, myriad unit of previous rune
+    // (zero if previous rune is not unit)
+    prevUnit, runeUnit int
+    // units have been found, current group has digits
+    hasUnits, hasGroup bool
+    // digits before first unit or positional digits (without units),
+    // like '二〇二〇'
+    positional []byte
+    afterPoint bool
+    frac []byte
+}
+
+// put myriad group to digits at power of ten
+func (st *cjkParseState) putGroup(pow int) error {
+    if st.digit>0 {
+        st.group[0] = byte(st.digit)
+        st.hasGroup = true
+    }
+    if !st.hasGroup { return strconv.ErrSyntax }
+    if pow+3>=len(st.digits) { return strconv.ErrRange }
+    copy(st.digits[pow:pow+4], st.group[:])
+    st.group = [4]byte{}
+    st.digit = -1
+    st.hasGroup = false
+    st.lastSmall = 4
+    return nil
+}
+
+func (st *cjkParseState) putRune(r rune) error {
+    kind, v := cjkRuneValue(r)
+    if st.afterPoint {
+        if kind!=cjkDigit { return strconv.ErrSyntax }
+        st.frac = append(st.frac, '0'+byte(v))
+        return nil
+    }
+    runeUnit := st.runeUnit
+    st.runeUnit = 0
+    if (kind==cjkSmall || kind==cjkUnit) && !st.hasUnits {
+        // first unit, only one digit can be before it
+        if len(st.positional)>1 { return strconv.ErrSyntax }
+        st.hasUnits = true
+    }
+    switch kind {
+    case cjkDigit:
+        if !st.hasUnits {
+            st.positional = append(st.positional, '0'+byte(v))
+        } else if st.digit>0 {
+            // two digits without unit between them
+            return strconv.ErrSyntax
+        }
+        st.digit = v
+    case cjkSmall:
+        if v>=st.lastSmall { return strconv.ErrSyntax }
+        if st.digit<=0 { st.digit = 1 }
+        st.group[v] = byte(st.digit)
+        st.hasGroup = true
+        st.digit = -1
+        st.lastSmall = v
+    case cjkUnit:
+        if runeUnit!=0 && v>runeUnit {
+            // compound unit (万亿 is 10^4*10^8): multiply last group
+            pow := st.lastUnit+v
+            if st.prevUnit!=0 && pow>=st.prevUnit { return strconv.ErrSyntax }
+            if pow+3>=len(st.digits) { return strconv.ErrRange }
+            copy(st.digits[pow:pow+4], st.digits[st.lastUnit:st.lastUnit+4])
+            for k := st.lastUnit; k<st.lastUnit+4; k++ { st.digits[k] = 0 }
+            st.lastUnit = pow
+            st.runeUnit = v
+            break
+        }
+        if st.lastUnit!=0 && v>=st.lastUnit { return strconv.ErrSyntax }
+        if err := st.putGroup(v); err!=nil { return err }
+        st.prevUnit = st.lastUnit
+        st.lastUnit = v
+        st.runeUnit = v
+    case cjkPoint:
+        st.afterPoint = true
+    default:
+        return strconv.ErrSyntax
+    }
+    return nil
+}
+
+// finish parsing and return value
+func (st *cjkParseState) finish(precision uint, rounding bool) (UDec128, error) {
+    var buf [128]byte
+    os := buf[:0]
+    if st.hasUnits {
+        if st.digit>=0 || st.hasGroup {
+            if err := st.putGroup(0); err!=nil { return UDec128{}, err }
+        }
+        i := len(st.digits)-1
+        for ; i>0 && st.digits[i]==0; i-- { }
+        for ; i>=0; i-- { os = append(os, '0'+st.digits[i]) }
+    } else {
+        if len(st.positional)==0 && len(st.frac)==0 { return UDec128{}, strconv.ErrSyntax }
+        os = append(os, st.positional...)
+    }
+    if st.afterPoint {
+        if len(st.frac)==0 { return UDec128{}, strconv.ErrSyntax }
+        os = append(os, '.')
+        os = append(os, st.frac...)
+    }
+    return ParseUDec128Bytes(os, precision, rounding)
+}
+
+// parse number written with Chinese or Japanese numerals (any style,
+// including financial forms). Numerals with myriad units (一万二千)
+// and positional numerals (二〇二〇) are accepted.
+// Return value and error (nil if no error)
+func ParseUDec128CJK(str string, precision uint, rounding bool) (UDec128, error) {
+    st := cjkParseState{ digit: -1, lastSmall: 4 }
+    if len(str)==0 { return UDec128{}, strconv.ErrSyntax }
+    for _, r := range str {
+        if err := st.putRune(r); err!=nil { return UDec128{}, err }
+    }
+    return st.finish(precision, rounding)
+}
+
+// parse number written with Chinese or Japanese numerals from bytes.
+// Return value and error (nil if no error)
+func ParseUDec128CJKBytes(str []byte, precision uint, rounding bool) (UDec128, error) {
+    st := cjkParseState{ digit: -1, lastSmall: 4 }
+    if len(str)==0 { return UDec128{}, strconv.ErrSyntax }
+    for len(str)>0 {
+        r, size := utf8.DecodeRune(str)
+        if err := st.putRune(r); err!=nil { return UDec128{}, err }
+        str = str[size:]
+    }
+    return st.finish(precision, rounding)
+}
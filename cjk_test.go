@@ -0,0 +1,135 @@
+/*
+ * cjk_test.go - tests for Chinese and Japanese numerals
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type UDec128FmtCJKTC struct {
+    value UDec128
+    precision, displayPrecision uint
+    trimZeroes bool
+    style CJKStyle
+    expected string
+}
+
+func TestUDec128FormatCJK(t *testing.T) {
+    testCases := []UDec128FmtCJKTC {
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKSimplified,
+                    "一万二千三百四十五点六七" },
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKTraditional,
+                    "一萬二千三百四十五點六七" },
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKJapanese,
+                    "一万二千三百四十五・六七" },
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKSimplifiedFinancial,
+                    "壹万贰仟叁佰肆拾伍点陆柒" },
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKTraditionalFinancial,
+                    "壹萬貳仟參佰肆拾伍點陸柒" },
+        UDec128FmtCJKTC{ UDec128{ 1234567, 0 }, 2, 2, false, CJKJapaneseFinancial,
+                    "壱萬弐千参百四拾五・六七" },
+        UDec128FmtCJKTC{ UDec128{ 0, 0 }, 2, 2, true, CJKSimplified, "零" },
+        UDec128FmtCJKTC{ UDec128{ 0, 0 }, 2, 2, true, CJKJapanese, "〇" },
+        UDec128FmtCJKTC{ UDec128{ 5, 0 }, 2, 2, false, CJKSimplified, "零点零五" },
+        UDec128FmtCJKTC{ UDec128{ 1500, 0 }, 2, 2, true, CJKSimplified, "十五" },
+        UDec128FmtCJKTC{ UDec128{ 1500, 0 }, 2, 2, true, CJKSimplifiedFinancial,
+                    "壹拾伍" },
+        UDec128FmtCJKTC{ UDec128{ 110, 0 }, 0, 0, false, CJKSimplified, "一百一十" },
+        UDec128FmtCJKTC{ UDec128{ 110, 0 }, 0, 0, false, CJKJapanese, "百十" },
+        UDec128FmtCJKTC{ UDec128{ 1005, 0 }, 0, 0, false, CJKSimplified, "一千零五" },
+        UDec128FmtCJKTC{ UDec128{ 1005, 0 }, 0, 0, false, CJKJapanese, "千五" },
+        UDec128FmtCJKTC{ UDec128{ 10005, 0 }, 0, 0, false, CJKTraditional, "一萬零五" },
+        UDec128FmtCJKTC{ UDec128{ 100000, 0 }, 0, 0, false, CJKSimplified, "十万" },
+        UDec128FmtCJKTC{ UDec128{ 100010000, 0 }, 0, 0, false, CJKSimplified,
+                    "一亿零一万" },
+        UDec128FmtCJKTC{ UDec128{ 100003456, 0 }, 0, 0, false, CJKSimplified,
+                    "一亿零三千四百五十六" },
+        UDec128FmtCJKTC{ UDec128{ 20003456, 0 }, 0, 0, false, CJKSimplified,
+                    "二千万三千四百五十六" },
+        UDec128FmtCJKTC{ UDec128{ 20000345, 0 }, 0, 0, false, CJKSimplified,
+                    "二千万零三百四十五" },
+        UDec128FmtCJKTC{ UDec128{ 1000000000000, 0 }, 0, 0, false, CJKJapanese, "一兆" },
+        UDec128FmtCJKTC{ UDec128{ 0, 1 }, 0, 0, false, CJKJapanese,
+                    "千八百四十四京六千七百四十四兆七百三十七億九百五十五万千六百十六" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.FormatCJK(tc.precision, tc.displayPrecision,
+                                     tc.trimZeroes, tc.style)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v)->%q!=%q",
+                     i, tc.value, tc.expected, result)
+        }
+        v, err := ParseUDec128CJK(result, tc.precision, false)
+        if v!=tc.value || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v!=%v,%v",
+                     i, result, tc.value, v, err)
+        }
+    }
+}
+
+type UDec128ParseCJKTC struct {
+    str string
+    precision uint
+    expected UDec128
+    err error
+}
+
+func TestParseUDec128CJK(t *testing.T) {
+    testCases := []UDec128ParseCJKTC {
+        UDec128ParseCJKTC{ "一万二千三百四十五点六七", 2, UDec128{ 1234567, 0 }, nil },
+        UDec128ParseCJKTC{ "壹萬貳仟", 0, UDec128{ 12000, 0 }, nil },
+        UDec128ParseCJKTC{ "两千", 0, UDec128{ 2000, 0 }, nil },
+        UDec128ParseCJKTC{ "一千二百三十四万五千六百七十八", 0,
+                    UDec128{ 12345678, 0 }, nil },
+        UDec128ParseCJKTC{ "二〇二〇", 0, UDec128{ 2020, 0 }, nil },
+        UDec128ParseCJKTC{ "三・一四", 2, UDec128{ 314, 0 }, nil },
+        UDec128ParseCJKTC{ "点五", 1, UDec128{ 5, 0 }, nil },
+        UDec128ParseCJKTC{ "一点二三四五", 2, UDec128{ 123, 0 }, nil },
+        UDec128ParseCJKTC{ "", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "万", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "二三百", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一万亿", 0, UDec128{ 1000000000000, 0 }, nil },
+        UDec128ParseCJKTC{ "1万亿", 0, UDec128{ 1000000000000, 0 }, nil },
+        UDec128ParseCJKTC{ "三万亿二千万", 0, UDec128{ 3000020000000, 0 }, nil },
+        UDec128ParseCJKTC{ "一亿一万亿", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一亿万", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一百二千", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一千二三", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一点", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一点五点", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一点十", 0, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseCJKTC{ "一x", 0, UDec128{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, err := ParseUDec128CJK(tc.str, tc.precision, false)
+        if tc.expected!=result || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v!=%v,%v",
+                     i, tc.str, tc.expected, tc.err, result, err)
+        }
+        result, err = ParseUDec128CJKBytes([]byte(tc.str), tc.precision, false)
+        if tc.expected!=result || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parseBytes(%q)->%v,%v!=%v,%v",
+                     i, tc.str, tc.expected, tc.err, result, err)
+        }
+    }
+}
@@ -0,0 +1,292 @@
+/*
+ * compact.go - compact formatting of numbers (like '1.2K', '1,2 Mrd.')
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+)
+
+// style of compact number
+type CompactStyle uint8
+
+const (
+    // short names of magnitudes (like '1.2M')
+    CompactShort CompactStyle = iota
+    // long names of magnitudes (like '1.2 million')
+    CompactLong
+)
+
+// default number of significant digits of compact number
+const defaultCompactSigDigits = 2
+
+// name of magnitude used from exp to exponent of next entry
+type compactEntry struct {
+    // decimal exponent of divisor
+    exp uint8
+    name UnitName
+}
+
+// compact patterns of language (like CLDR compact decimal formats)
+type compactPatterns struct {
+    short, long []compactEntry
+    // separator between number and short and long name
+    shortSep, longSep string
+    // plural category of number given as integer part and fraction
+    plural func(intPart, frac []byte) PluralCategory
+}
+
+func compactName(one, other string) UnitName {
+    return UnitName{ One: one, Other: other }
+}
+
+// one if integer is 1 without fraction
+func pluralOneInt(intPart, frac []byte) PluralCategory {
+    if len(frac)==0 && len(intPart)==1 && intPart[0]=='1' { return PluralOne }
+    return PluralOther
+}
+
+// one if integer part is 0 or 1
+func pluralFrench(intPart, frac []byte) PluralCategory {
+    if len(intPart)==1 && intPart[0]<='1' { return PluralOne }
+    return PluralOther
+}
+
+func pluralPolish(intPart, frac []byte) PluralCategory {
+    if len(frac)!=0 { return PluralOther }
+    v, one := lastDigits(intPart, 2)
+    switch {
+    case one:
+        return PluralOne
+    case v%10>=2 && v%10<=4 && (v<12 || v>14):
+        return PluralFew
+    }
+    return PluralMany
+}
+
+func pluralNone(intPart, frac []byte) PluralCategory {
+    return PluralOther
+}
+
+var englishCompact = compactPatterns{
+    short: []compactEntry{ { 3, compactName("", "K") }, { 6, compactName("", "M") },
+        { 9, compactName("", "B") }, { 12, compactName("", "T") } },
+    long: []compactEntry{ { 3, compactName("", "thousand") },
+        { 6, compactName("", "million") }, { 9, compactName("", "billion") },
+        { 12, compactName("", "trillion") } },
+    shortSep: "", longSep: " ", plural: pluralOneInt,
+}
+
+var chineseCompactEntries = []compactEntry{ { 4, compactName("", "万") },
+        { 8, compactName("", "亿") }, { 12, compactName("", "万亿") } }
+
+var chineseTradCompactEntries = []compactEntry{ { 4, compactName("", "萬") },
+        { 8, compactName("", "億") }, { 12, compactName("", "兆") } }
+
+var japaneseCompactEntries = []compactEntry{ { 4, compactName("", "万") },
+        { 8, compactName("", "億") }, { 12, compactName("", "兆") },
+        { 16, compactName("", "京") } }
+
+var koreanCompactEntries = []compactEntry{ { 3, compactName("", "천") },
+        { 4, compactName("", "만") }, { 8, compactName("", "억") },
+        { 12, compactName("", "조") } }
+
+var chineseTradCompact = compactPatterns{
+    short: chineseTradCompactEntries, long: chineseTradCompactEntries,
+    plural: pluralNone }
+
+// compact patterns of built-in languages
+var builtinCompactPatterns map[string]*compactPatterns = map[string]*compactPatterns{
+    "en": &englishCompact,
+    "en-IN": &compactPatterns{
+        short: []compactEntry{ { 3, compactName("", "K") }, { 5, compactName("", "L") },
+            { 7, compactName("", "Cr") } },
+        long: []compactEntry{ { 3, compactName("", "thousand") },
+            { 5, compactName("", "lakh") }, { 7, compactName("", "crore") } },
+        shortSep: "", longSep: " ", plural: pluralOneInt,
+    },
+    "de": &compactPatterns{
+        short: []compactEntry{ { 6, compactName("", "Mio.") },
+            { 9, compactName("", "Mrd.") }, { 12, compactName("", "Bio.") } },
+        long: []compactEntry{ { 3, compactName("", "Tausend") },
+            { 6, compactName("Million", "Millionen") },
+            { 9, compactName("Milliarde", "Milliarden") },
+            { 12, compactName("Billion", "Billionen") } },
+        shortSep: "\u00a0", longSep: " ", plural: pluralOneInt,
+    },
+    "fr": &compactPatterns{
+        short: []compactEntry{ { 3, compactName("", "k") }, { 6, compactName("", "M") },
+            { 9, compactName("", "Md") }, { 12, compactName("", "Bn") } },
+        long: []compactEntry{ { 3, compactName("", "mille") },
+            { 6, compactName("million", "millions") },
+            { 9, compactName("milliard", "milliards") },
+            { 12, compactName("billion", "billions") } },
+        shortSep: "\u00a0", longSep: " ", plural: pluralFrench,
+    },
+    "es": &compactPatterns{
+        short: []compactEntry{ { 3, compactName("", "mil") },
+            { 6, compactName("", "M") }, { 12, compactName("", "B") } },
+        long: []compactEntry{ { 3, compactName("", "mil") },
+            { 6, compactName("millón", "millones") },
+            { 9, compactName("", "mil millones") },
+            { 12, compactName("billón", "billones") } },
+        shortSep: "\u00a0", longSep: " ", plural: pluralOneInt,
+    },
+    "pl": &compactPatterns{
+        short: []compactEntry{ { 3, compactName("", "tys.") },
+            { 6, compactName("", "mln") }, { 9, compactName("", "mld") },
+            { 12, compactName("", "bln") } },
+        long: []compactEntry{
+            { 3, UnitName{ One: "tysiąc", Few: "tysiące", Many: "tysięcy",
+                    Other: "tysiąca" } },
+            { 6, UnitName{ One: "milion", Few: "miliony", Many: "milionów",
+                    Other: "miliona" } },
+            { 9, UnitName{ One: "miliard", Few: "miliardy", Many: "miliardów",
+                    Other: "miliarda" } },
+            { 12, UnitName{ One: "bilion", Few: "biliony", Many: "bilionów",
+                    Other: "biliona" } } },
+        shortSep: "\u00a0", longSep: " ", plural: pluralPolish,
+    },
+    "ja": &compactPatterns{ short: japaneseCompactEntries,
+        long: japaneseCompactEntries, plural: pluralNone },
+    "ko": &compactPatterns{ short: koreanCompactEntries,
+        long: koreanCompactEntries, plural: pluralNone },
+    "zh": &compactPatterns{ short: chineseCompactEntries,
+        long: chineseCompactEntries, plural: pluralNone },
+    "zh-Hant": &chineseTradCompact,
+    "zh-TW": &chineseTradCompact,
+    "zh-HK": &chineseTradCompact,
+    "zh-MO": &chineseTradCompact,
+}
+
+// return compact patterns for language. If no patterns for language
+// then English patterns are returned
+func getCompactPatterns(lang string) *compactPatterns {
+    if t, ok := parseLangTag(lang); ok {
+        for _, name := range t.fallbacks() {
+            if p, ok := builtinCompactPatterns[name]; ok { return p }
+        }
+    }
+    return &englishCompact
+}
+
+// return entry of magnitude or nil if number is not compacted
+func findCompactEntry(entries []compactEntry, mag int) *compactEntry {
+    var e *compactEntry
+    for i := range entries {
+        if int(entries[i].exp)>mag { break }
+        e = &entries[i]
+    }
+    return e
+}
+
+// append number divided by magnitude and rounded to sigDigits significant
+// digits (but not less than all digits of integer part) to dst and
+// return extended buffer and entry of magnitude. Number is formatted
+// like AppendFormat with trimmed trailing zeroes in fraction.
+// Entry of magnitude is chosen after rounding, thus 999950 gives '1' and
+// entry for millions rather than '1000' and entry for thousands.
+func appendCompactNumber(dst []byte, a UDec128, precision uint, sigDigits int,
+                entries []compactEntry, mode RoundingMode) ([]byte, *compactEntry) {
+    if sigDigits<=0 { sigDigits = defaultCompactSigDigits }
+    mag := 0
+    if !a.IsZero() { mag = len(udec128Digits(a))-1-int(precision) }
+    for {
+        e := findCompactEntry(entries, mag)
+        exp := 0
+        if e!=nil { exp = int(e.exp) }
+        intDigits := mag-exp+1
+        frac := sigDigits-intDigits
+        if frac<0 { frac = 0 }
+        shift := int(precision)+exp-frac
+        if shift<0 {
+            frac += shift
+            shift = 0
+        }
+        r := udec128RoundDivPow10(a, uint(shift), mode)
+        if !r.IsZero() && len(udec128Digits(r))>intDigits+frac {
+            // rounding carried into next magnitude
+            mag++
+            continue
+        }
+        start := len(dst)
+        dst = r.AppendFormat(dst, uint(frac), uint(frac), true)
+        if bytes.HasSuffix(dst[start:], []byte(".0")) { dst = dst[:len(dst)-2] }
+        return dst, e
+    }
+}
+
+func appendCompact(dst []byte, l *LocFmt, a UDec128, precision uint, sigDigits int,
+                style CompactStyle, mode RoundingMode, p *compactPatterns) []byte {
+    entries, sep := p.short, p.shortSep
+    if style==CompactLong { entries, sep = p.long, p.longSep }
+    var buf [64]byte
+    s, e := appendCompactNumber(buf[:0], a, precision, sigDigits, entries, mode)
+    dst = appendLocalized(dst, l, localeGrouping(l, false), l.FracGrouping, s)
+    if e==nil { return dst }
+    intPart, frac := s, s[len(s):]
+    if i := bytes.IndexByte(s, '.'); i!=-1 { intPart, frac = s[:i], s[i+1:] }
+    name := e.name.Form(p.plural(intPart, frac))
+    if name=="" { return dst }
+    dst = append(dst, sep...)
+    return append(dst, name...)
+}
+
+// append number in compact form (like '1.2K' or '1.2 thousand') to dst and
+// return extended buffer. sigDigits is number of significant digits
+// (if zero then 2), all digits of integer part are always put.
+// Number is rounded by rounding mode.
+func (a UDec128) AppendFormatCompact(dst []byte, precision uint, sigDigits int,
+                    style CompactStyle, mode RoundingMode) []byte {
+    return appendCompact(dst, plainLocFmt, a, precision, sigDigits, style, mode,
+                         &englishCompact)
+}
+
+// format number in compact form
+func (a UDec128) FormatCompact(precision uint, sigDigits int, style CompactStyle,
+                    mode RoundingMode) string {
+    var buf [64]byte
+    return string(a.AppendFormatCompact(buf[:0], precision, sigDigits, style, mode))
+}
+
+// append number with sign in compact form including locale (like '1,2 Mrd.'
+// or '1,2万') to dst and return extended buffer. If no compact names for
+// language then English names are used.
+func (a UDec128) AppendLocaleFormatCompact(dst []byte, lang string, precision uint,
+                    sigDigits int, style CompactStyle, negative bool,
+                    mode RoundingMode) []byte {
+    l := getLocFmt(lang)
+    var sign rune
+    if negative { sign = l.Symbols.minusSign() }
+    dst = l.Symbols.appendPrefix(dst, sign, StyleDecimal, false)
+    dst = appendCompact(dst, l, a, precision, sigDigits, style, mode,
+                        getCompactPatterns(lang))
+    return l.Symbols.appendSuffix(dst, sign, StyleDecimal, false)
+}
+
+// format number with sign in compact form including locale
+func (a UDec128) LocaleFormatCompact(lang string, precision uint, sigDigits int,
+                    style CompactStyle, negative bool, mode RoundingMode) string {
+    var buf [128]byte
+    return string(a.AppendLocaleFormatCompact(buf[:0], lang, precision, sigDigits,
+                                              style, negative, mode))
+}
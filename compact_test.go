@@ -0,0 +1,180 @@
+/*
+ * compact_test.go - tests for compact formatting of numbers
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "testing"
+)
+
+type UDec128FmtCompactTC struct {
+    value UDec128
+    precision uint
+    sigDigits int
+    style CompactStyle
+    mode RoundingMode
+    expected string
+}
+
+func TestUDec128FormatCompact(t *testing.T) {
+    testCases := []UDec128FmtCompactTC {
+        UDec128FmtCompactTC{ UDec128{ 0, 0 }, 2, 0, CompactShort, RoundHalfUp, "0" },
+        UDec128FmtCompactTC{ UDec128{ 999, 0 }, 0, 0, CompactShort, RoundHalfUp, "999" },
+        UDec128FmtCompactTC{ UDec128{ 1000, 0 }, 0, 0, CompactShort, RoundHalfUp, "1K" },
+        UDec128FmtCompactTC{ UDec128{ 123456, 0 }, 2, 0, CompactShort, RoundHalfUp,
+                    "1.2K" },
+        UDec128FmtCompactTC{ UDec128{ 1250, 0 }, 0, 0, CompactShort, RoundHalfEven,
+                    "1.2K" },
+        UDec128FmtCompactTC{ UDec128{ 1250, 0 }, 0, 0, CompactShort, RoundHalfUp,
+                    "1.3K" },
+        UDec128FmtCompactTC{ UDec128{ 12345, 0 }, 0, 0, CompactShort, RoundHalfUp, "12K" },
+        UDec128FmtCompactTC{ UDec128{ 9960, 0 }, 0, 0, CompactShort, RoundHalfUp, "10K" },
+        UDec128FmtCompactTC{ UDec128{ 999950, 0 }, 0, 0, CompactShort, RoundHalfUp, "1M" },
+        UDec128FmtCompactTC{ UDec128{ 999950, 0 }, 0, 3, CompactShort, RoundHalfUp, "1M" },
+        UDec128FmtCompactTC{ UDec128{ 999950, 0 }, 0, 3, CompactShort, RoundDown,
+                    "999K" },
+        UDec128FmtCompactTC{ UDec128{ 999499, 0 }, 0, 0, CompactShort, RoundHalfUp,
+                    "999K" },
+        UDec128FmtCompactTC{ UDec128{ 1234567, 0 }, 0, 4, CompactShort, RoundHalfUp,
+                    "1.235M" },
+        UDec128FmtCompactTC{ UDec128{ 1234567, 0 }, 0, 10, CompactShort, RoundHalfUp,
+                    "1.234567M" },
+        UDec128FmtCompactTC{ UDec128{ 1500000000, 0 }, 0, 0, CompactLong, RoundHalfUp,
+                    "1.5 billion" },
+        UDec128FmtCompactTC{ UDec128{ 1234567890123456, 0 }, 0, 0, CompactShort,
+                    RoundHalfUp, "1235T" },
+        UDec128FmtCompactTC{ UDec128{ 0, 1 }, 0, 0, CompactLong, RoundHalfUp,
+                    "18446744 trillion" },
+        UDec128FmtCompactTC{ UDec128{ 1234, 0 }, 2, 0, CompactShort, RoundHalfUp, "12" },
+        UDec128FmtCompactTC{ UDec128{ 123, 0 }, 2, 0, CompactShort, RoundHalfUp, "1.2" },
+        UDec128FmtCompactTC{ UDec128{ 99, 0 }, 5, 0, CompactShort, RoundHalfUp,
+                    "0.00099" },
+        UDec128FmtCompactTC{ UDec128{ 999, 0 }, 5, 0, CompactShort, RoundHalfUp,
+                    "0.01" },
+        UDec128FmtCompactTC{ UDec128{ 996, 0 }, 2, 0, CompactShort, RoundHalfUp, "10" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.FormatCompact(tc.precision, tc.sigDigits, tc.style, tc.mode)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v)->%q!=%q",
+                     i, tc.value, tc.expected, result)
+        }
+    }
+}
+
+type UDec128LocFmtCompactTC struct {
+    lang string
+    value UDec128
+    precision uint
+    style CompactStyle
+    negative bool
+    expected string
+}
+
+func TestUDec128LocaleFormatCompact(t *testing.T) {
+    testCases := []UDec128LocFmtCompactTC {
+        UDec128LocFmtCompactTC{ "en", UDec128{ 1234567890123456, 0 }, 0, CompactShort,
+                    false, "1,235T" },
+        UDec128LocFmtCompactTC{ "en", UDec128{ 1000000, 0 }, 0, CompactLong,
+                    false, "1 million" },
+        UDec128LocFmtCompactTC{ "en", UDec128{ 123456, 0 }, 0, CompactShort,
+                    true, "-123K" },
+        UDec128LocFmtCompactTC{ "en-IN", UDec128{ 123456, 0 }, 0, CompactShort,
+                    false, "1.2L" },
+        UDec128LocFmtCompactTC{ "en-IN", UDec128{ 123456789, 0 }, 0, CompactLong,
+                    false, "12 crore" },
+        UDec128LocFmtCompactTC{ "de", UDec128{ 123456, 0 }, 0, CompactShort,
+                    false, "123.456" },
+        UDec128LocFmtCompactTC{ "de", UDec128{ 1234567, 0 }, 0, CompactShort,
+                    false, "1,2\u00a0Mio." },
+        UDec128LocFmtCompactTC{ "de-AT", UDec128{ 1234567890, 0 }, 0, CompactShort,
+                    false, "1,2\u00a0Mrd." },
+        UDec128LocFmtCompactTC{ "de", UDec128{ 1000000, 0 }, 0, CompactLong,
+                    false, "1 Million" },
+        UDec128LocFmtCompactTC{ "de", UDec128{ 1234567, 0 }, 0, CompactLong,
+                    false, "1,2 Millionen" },
+        UDec128LocFmtCompactTC{ "fr", UDec128{ 1234567, 0 }, 0, CompactLong,
+                    false, "1,2 million" },
+        UDec128LocFmtCompactTC{ "fr", UDec128{ 2000000, 0 }, 0, CompactLong,
+                    false, "2 millions" },
+        UDec128LocFmtCompactTC{ "fr", UDec128{ 1234, 0 }, 0, CompactShort,
+                    false, "1,2\u00a0k" },
+        UDec128LocFmtCompactTC{ "es", UDec128{ 1500000000, 0 }, 0, CompactShort,
+                    false, "1500\u00a0M" },
+        UDec128LocFmtCompactTC{ "es", UDec128{ 1500000000, 0 }, 0, CompactLong,
+                    false, "1,5 mil millones" },
+        UDec128LocFmtCompactTC{ "es", UDec128{ 1000000, 0 }, 0, CompactLong,
+                    false, "1 millón" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 1000, 0 }, 0, CompactLong,
+                    false, "1 tysiąc" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 1234, 0 }, 0, CompactLong,
+                    false, "1,2 tysiąca" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 2000000, 0 }, 0, CompactLong,
+                    false, "2 miliony" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 12000000, 0 }, 0, CompactLong,
+                    false, "12 milionów" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 22000000, 0 }, 0, CompactLong,
+                    false, "22 miliony" },
+        UDec128LocFmtCompactTC{ "pl", UDec128{ 123456, 0 }, 0, CompactShort,
+                    false, "123\u00a0tys." },
+        UDec128LocFmtCompactTC{ "ja", UDec128{ 1234, 0 }, 0, CompactShort,
+                    false, "1,234" },
+        UDec128LocFmtCompactTC{ "ja", UDec128{ 12345, 0 }, 0, CompactShort,
+                    false, "1.2万" },
+        UDec128LocFmtCompactTC{ "ja", UDec128{ 1500000000, 0 }, 0, CompactShort,
+                    false, "15億" },
+        UDec128LocFmtCompactTC{ "zh", UDec128{ 123456789, 0 }, 0, CompactShort,
+                    false, "1.2亿" },
+        UDec128LocFmtCompactTC{ "zh-TW", UDec128{ 123456789, 0 }, 0, CompactShort,
+                    false, "1.2億" },
+        UDec128LocFmtCompactTC{ "ko", UDec128{ 9960, 0 }, 0, CompactShort,
+                    false, "1만" },
+        UDec128LocFmtCompactTC{ "fi", UDec128{ 1234567, 0 }, 2, CompactShort,
+                    true, "−12K" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.LocaleFormatCompact(tc.lang, tc.precision, 0, tc.style,
+                                               tc.negative, RoundHalfUp)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.value, tc.expected, result)
+        }
+    }
+}
+
+func TestFormatterCompact(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Lang: "de", Precision: 2, Compact: true })
+    if result := f.FormatSigned(UDec128{ 123456789, 0 }, true);
+            result!="-1,2\u00a0Mio." {
+        t.Errorf("Result mismatch: de: %q", result)
+    }
+    f = NewFormatter(FormatterOptions{ Precision: 2, Compact: true,
+                CompactStyle: CompactLong, SigDigits: 3, Width: 14 })
+    if result := f.Format(UDec128{ 123456789, 0 }); result!="  1.23 million" {
+        t.Errorf("Result mismatch: plain: %q", result)
+    }
+    f = NewFormatter(FormatterOptions{ Lang: "en", Compact: true, PlusSign: true,
+                Rounding: RoundDown })
+    if result := f.Format(UDec128{ 999950, 0 }); result!="+999K" {
+        t.Errorf("Result mismatch: en: %q", result)
+    }
+}
@@ -0,0 +1,426 @@
+/*
+ * currency.go - currency-aware formatting and parsing
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "errors"
+    "sort"
+    "strings"
+    "sync"
+    "unicode"
+    "unicode/utf8"
+)
+
+var (
+    ErrUnknownCurrency = errors.New("godec128: unknown currency")
+    ErrInvalidCurrency = errors.New("godec128: invalid currency")
+)
+
+// currency (ISO 4217)
+type Currency struct {
+    // ISO 4217 code (like 'USD')
+    Code string
+    // symbol (like 'CA$') and narrow symbol (like '$'), if empty then code is used
+    Symbol, NarrowSymbol string
+    // number of digits of minor unit
+    Digits uint
+}
+
+// display of currency
+type CurrencyDisplay uint8
+
+const (
+    // symbol of currency (like 'CA$')
+    CurrencyDisplaySymbol CurrencyDisplay = iota
+    // narrow symbol of currency (like '$')
+    CurrencyDisplayNarrow
+    // ISO 4217 code (like 'CAD')
+    CurrencyDisplayCode
+)
+
+func (ls *LocSymbols) currencyPattern() string {
+    if ls.CurrencyPattern=="" { return "¤#" }
+    return ls.CurrencyPattern
+}
+
+// built-in currencies (symbols from CLDR root and English locale)
+var builtinCurrencies map[string]*Currency = map[string]*Currency{
+    "AED": &Currency{ "AED", "AED", "AED", 2 },
+    "ARS": &Currency{ "ARS", "ARS", "$", 2 },
+    "AUD": &Currency{ "AUD", "A$", "$", 2 },
+    "BHD": &Currency{ "BHD", "BHD", "BHD", 3 },
+    "BRL": &Currency{ "BRL", "R$", "R$", 2 },
+    "CAD": &Currency{ "CAD", "CA$", "$", 2 },
+    "CHF": &Currency{ "CHF", "CHF", "CHF", 2 },
+    "CLP": &Currency{ "CLP", "CLP", "$", 0 },
+    "CNY": &Currency{ "CNY", "CN¥", "¥", 2 },
+    "COP": &Currency{ "COP", "COP", "$", 2 },
+    "CZK": &Currency{ "CZK", "CZK", "Kč", 2 },
+    "DKK": &Currency{ "DKK", "DKK", "kr", 2 },
+    "EGP": &Currency{ "EGP", "EGP", "E£", 2 },
+    "EUR": &Currency{ "EUR", "€", "€", 2 },
+    "GBP": &Currency{ "GBP", "£", "£", 2 },
+    "HKD": &Currency{ "HKD", "HK$", "$", 2 },
+    "HUF": &Currency{ "HUF", "HUF", "Ft", 2 },
+    "IDR": &Currency{ "IDR", "IDR", "Rp", 2 },
+    "ILS": &Currency{ "ILS", "₪", "₪", 2 },
+    "INR": &Currency{ "INR", "₹", "₹", 2 },
+    "IQD": &Currency{ "IQD", "IQD", "IQD", 3 },
+    "ISK": &Currency{ "ISK", "ISK", "kr", 0 },
+    "JOD": &Currency{ "JOD", "JOD", "JOD", 3 },
+    "JPY": &Currency{ "JPY", "¥", "¥", 0 },
+    "KRW": &Currency{ "KRW", "₩", "₩", 0 },
+    "KWD": &Currency{ "KWD", "KWD", "KWD", 3 },
+    "LYD": &Currency{ "LYD", "LYD", "LYD", 3 },
+    "MXN": &Currency{ "MXN", "MX$", "$", 2 },
+    "NOK": &Currency{ "NOK", "NOK", "kr", 2 },
+    "NZD": &Currency{ "NZD", "NZ$", "$", 2 },
+    "OMR": &Currency{ "OMR", "OMR", "OMR", 3 },
+    "PHP": &Currency{ "PHP", "₱", "₱", 2 },
+    "PLN": &Currency{ "PLN", "PLN", "zł", 2 },
+    "PYG": &Currency{ "PYG", "PYG", "₲", 0 },
+    "RON": &Currency{ "RON", "RON", "lei", 2 },
+    "RUB": &Currency{ "RUB", "RUB", "₽", 2 },
+    "SAR": &Currency{ "SAR", "SAR", "SAR", 2 },
+    "SEK": &Currency{ "SEK", "SEK", "kr", 2 },
+    "SGD": &Currency{ "SGD", "SGD", "$", 2 },
+    "THB": &Currency{ "THB", "THB", "฿", 2 },
+    "TND": &Currency{ "TND", "TND", "TND", 3 },
+    "TRY": &Currency{ "TRY", "TRY", "₺", 2 },
+    "TWD": &Currency{ "TWD", "NT$", "$", 2 },
+    "UAH": &Currency{ "UAH", "UAH", "₴", 2 },
+    "UGX": &Currency{ "UGX", "UGX", "UGX", 0 },
+    "USD": &Currency{ "USD", "$", "$", 2 },
+    "VND": &Currency{ "VND", "₫", "₫", 0 },
+    "XAF": &Currency{ "XAF", "FCFA", "FCFA", 0 },
+    "XOF": &Currency{ "XOF", "F CFA", "F CFA", 0 },
+    "ZAR": &Currency{ "ZAR", "ZAR", "R", 2 },
+}
+
+// symbols of currencies specific for locales
+var builtinLocaleCurrencySymbols map[string]map[string]string = map[string]map[string]string{
+    "cs": { "CZK": "Kč" },
+    "da": { "DKK": "kr." },
+    "en-AU": { "AUD": "$", "USD": "US$" },
+    "en-CA": { "CAD": "$", "USD": "US$" },
+    "en-IN": { "INR": "₹" },
+    "en-NZ": { "NZD": "$", "USD": "US$" },
+    "es-MX": { "MXN": "$", "USD": "USD" },
+    "fr-CA": { "CAD": "$", "USD": "$\u00a0US" },
+    "hu": { "HUF": "Ft" },
+    "ja": { "JPY": "￥", "CNY": "元" },
+    "nb": { "NOK": "kr" },
+    "no": { "NOK": "kr" },
+    "pl": { "PLN": "zł" },
+    "pt": { "BRL": "R$" },
+    "ru": { "RUB": "₽" },
+    "sv": { "SEK": "kr" },
+    "tr": { "TRY": "₺" },
+    "uk": { "UAH": "₴" },
+    "zh": { "CNY": "¥", "JPY": "JP¥" },
+    "zh-Hant": { "TWD": "$", "CNY": "CN¥" },
+    "zh-TW": { "TWD": "$", "CNY": "CN¥" },
+}
+
+// currency patterns of regional variants that differ from language
+var builtinRegionalCurrencyPatterns map[string]string = map[string]string{
+    "de-AT": "¤\u00a0#",
+    "de-CH": "¤\u00a0#",
+    "de-LI": "¤\u00a0#",
+    "es-419": "¤#",
+    "es-MX": "¤#",
+    "es-US": "¤#",
+    "it-CH": "¤\u00a0#",
+}
+
+// maximal number of cached currency tokens of languages
+const maxCachedCurrencyTokens = 256
+
+type currencyRegistry struct {
+    sync.RWMutex
+    custom map[string]*Currency
+    // cached currency tokens for languages
+    tokens map[string][]currencyToken
+}
+
+var currencies = currencyRegistry{ custom: make(map[string]*Currency),
+    tokens: make(map[string][]currencyToken) }
+
+// return true if code is valid ISO 4217 code (three uppercase letters)
+func isCurrencyCode(code string) bool {
+    if len(code)!=3 { return false }
+    for i := 0; i < 3; i++ {
+        if code[i]<'A' || code[i]>'Z' { return false }
+    }
+    return true
+}
+
+// register currency. Registered currency overrides built-in currency
+// with same code.
+func RegisterCurrency(c Currency) error {
+    if !isCurrencyCode(c.Code) { return ErrInvalidCurrency }
+    currencies.Lock()
+    currencies.custom[c.Code] = &c
+    currencies.tokens = make(map[string][]currencyToken)
+    currencies.Unlock()
+    return nil
+}
+
+// unregister currency registered by RegisterCurrency
+func UnregisterCurrency(code string) {
+    currencies.Lock()
+    delete(currencies.custom, code)
+    currencies.tokens = make(map[string][]currencyToken)
+    currencies.Unlock()
+}
+
+// return currency for ISO 4217 code. If currency is unknown then
+// ErrUnknownCurrency is returned.
+func LookupCurrency(code string) (*Currency, error) {
+    currencies.RLock()
+    c, ok := currencies.custom[code]
+    currencies.RUnlock()
+    if ok { return c, nil }
+    if c, ok := builtinCurrencies[code]; ok { return c, nil }
+    return nil, ErrUnknownCurrency
+}
+
+// return codes of all available currencies (sorted)
+func availableCurrencies() []string {
+    currencies.RLock()
+    codes := make([]string, 0, len(builtinCurrencies)+len(currencies.custom))
+    for code := range builtinCurrencies { codes = append(codes, code) }
+    for code := range currencies.custom {
+        if _, ok := builtinCurrencies[code]; !ok { codes = append(codes, code) }
+    }
+    currencies.RUnlock()
+    sort.Strings(codes)
+    return codes
+}
+
+// return symbols of currencies specific for locale (most specific first)
+func localeCurrencySymbols(lang string) []map[string]string {
+    t, ok := parseLangTag(lang)
+    if !ok { return nil }
+    var out []map[string]string
+    for _, name := range t.fallbacks() {
+        if m, ok := builtinLocaleCurrencySymbols[name]; ok { out = append(out, m) }
+    }
+    return out
+}
+
+// return symbol of currency for locale and display
+func currencySymbol(c *Currency, lang string, display CurrencyDisplay) string {
+    switch display {
+    case CurrencyDisplayCode:
+        return c.Code
+    case CurrencyDisplayNarrow:
+        if c.NarrowSymbol!="" { return c.NarrowSymbol }
+    }
+    for _, m := range localeCurrencySymbols(lang) {
+        if s, ok := m[c.Code]; ok { return s }
+    }
+    if c.Symbol=="" { return c.Code }
+    return c.Symbol
+}
+
+// append part of currency pattern with replaced currency sign. If symbol
+// is put directly next to number and its adjacent character is letter then
+// no-break space is put between symbol and number (CLDR currency spacing).
+func appendCurrencyPart(dst []byte, part, symbol string, before bool) []byte {
+    for i, r := range part {
+        if r!='¤' {
+            dst = appendRune(dst, r)
+            continue
+        }
+        if before {
+            dst = append(dst, symbol...)
+            last, _ := utf8.DecodeLastRuneInString(symbol)
+            if i+utf8.RuneLen(r)==len(part) && unicode.IsLetter(last) {
+                dst = appendRune(dst, 0xa0)
+            }
+        } else {
+            first, _ := utf8.DecodeRuneInString(symbol)
+            if i==0 && unicode.IsLetter(first) { dst = appendRune(dst, 0xa0) }
+            dst = append(dst, symbol...)
+        }
+    }
+    return dst
+}
+
+// append amount with sign and currency including locale (like '$1,234.56'
+// or '1.234,56 €') to dst and return extended buffer. Amount is rounded
+// to digits of minor unit of currency by rounding mode. If currency is
+// unknown then ErrUnknownCurrency is returned.
+func (a UDec128) AppendLocaleFormatCurrency(dst []byte, lang, code string,
+                    precision uint, display CurrencyDisplay, negative bool,
+                    mode RoundingMode) ([]byte, error) {
+    c, err := LookupCurrency(code)
+    if err!=nil { return dst, err }
+    l := getLocFmt(lang)
+    symbol := currencySymbol(c, lang, display)
+    var sign rune
+    if negative { sign = l.Symbols.minusSign() }
+    dst = l.Symbols.appendPrefix(dst, sign, StyleDecimal, false)
+    pattern := l.Symbols.currencyPattern()
+    i := strings.IndexByte(pattern, '#')
+    if i==-1 { i = len(pattern) }
+    dst = appendCurrencyPart(dst, pattern[:i], symbol, true)
+    var buf [64]byte
+    s := a.appendFormatFixed(buf[:0], precision, c.Digits, mode)
+    dst = appendLocalized(dst, l, l.Grouping, l.FracGrouping, s)
+    if i<len(pattern) { dst = appendCurrencyPart(dst, pattern[i+1:], symbol, false) }
+    return l.Symbols.appendSuffix(dst, sign, StyleDecimal, false), nil
+}
+
+// format amount with sign and currency including locale
+func (a UDec128) LocaleFormatCurrency(lang, code string, precision uint,
+                    display CurrencyDisplay, negative bool,
+                    mode RoundingMode) (string, error) {
+    var buf [128]byte
+    s, err := a.AppendLocaleFormatCurrency(buf[:0], lang, code, precision, display,
+                                           negative, mode)
+    return string(s), err
+}
+
+// currency token (symbol or code) recognized while parsing
+type currencyToken struct {
+    token, code string
+}
+
+// return tokens of currencies for locale in order of priority: symbols
+// of locale, symbols, codes and narrow symbols. Narrow symbols shared
+// by many currencies (like 'kr') have empty code. Tokens are cached for
+// language and must not be modified.
+func currencyTokens(lang string) []currencyToken {
+    currencies.RLock()
+    tokens, ok := currencies.tokens[lang]
+    currencies.RUnlock()
+    if ok { return tokens }
+    tokens = buildCurrencyTokens(lang)
+    currencies.Lock()
+    if len(currencies.tokens)>=maxCachedCurrencyTokens {
+        currencies.tokens = make(map[string][]currencyToken)
+    }
+    currencies.tokens[lang] = tokens
+    currencies.Unlock()
+    return tokens
+}
+
+func buildCurrencyTokens(lang string) []currencyToken {
+    var tokens []currencyToken
+    for _, m := range localeCurrencySymbols(lang) {
+        for code, s := range m { tokens = append(tokens, currencyToken{ s, code }) }
+    }
+    codes := availableCurrencies()
+    cs := make([]*Currency, len(codes))
+    for i, code := range codes { cs[i], _ = LookupCurrency(code) }
+    for _, c := range cs {
+        if c.Symbol!="" { tokens = append(tokens, currencyToken{ c.Symbol, c.Code }) }
+    }
+    for _, c := range cs { tokens = append(tokens, currencyToken{ c.Code, c.Code }) }
+    narrow := make(map[string]int)
+    for _, c := range cs {
+        if c.NarrowSymbol!="" { narrow[c.NarrowSymbol]++ }
+    }
+    for _, c := range cs {
+        switch narrow[c.NarrowSymbol] {
+        case 0:
+        case 1:
+            tokens = append(tokens, currencyToken{ c.NarrowSymbol, c.Code })
+        default:
+            // ambiguous symbol, put only once
+            tokens = append(tokens, currencyToken{ c.NarrowSymbol, "" })
+            narrow[c.NarrowSymbol] = 0
+        }
+    }
+    return tokens
+}
+
+// return true if rune can be put between currency and end of amount
+func isCurrencyAdjacentRune(l *LocFmt, r rune) bool {
+    return isSpaceRune(r) || r==LRM || r==RLM || r==ALM || r=='-' || r=='+' ||
+            r=='−' || r==l.Symbols.minusSign() || r==l.Symbols.plusSign()
+}
+
+// find currency token at start or at end of amount. Return code of
+// currency and start and end of token (empty token if not found, empty
+// code if token is ambiguous)
+func findCurrencyToken(l *LocFmt, lang, str string) (string, int, int) {
+    tokens := currencyTokens(lang)
+    // find at start
+    start := 0
+    for start<len(str) {
+        r, size := utf8.DecodeRuneInString(str[start:])
+        if !isCurrencyAdjacentRune(l, r) { break }
+        start += size
+    }
+    best := -1
+    for i, t := range tokens {
+        if strings.HasPrefix(str[start:], t.token) &&
+                (best==-1 || len(t.token)>len(tokens[best].token)) {
+            best = i
+        }
+    }
+    if best!=-1 { return tokens[best].code, start, start+len(tokens[best].token) }
+    // find at end
+    end := len(str)
+    for end>0 {
+        r, size := utf8.DecodeLastRuneInString(str[:end])
+        if !isCurrencyAdjacentRune(l, r) { break }
+        end -= size
+    }
+    for i, t := range tokens {
+        if strings.HasSuffix(str[:end], t.token) &&
+                (best==-1 || len(t.token)>len(tokens[best].token)) {
+            best = i
+        }
+    }
+    if best!=-1 { return tokens[best].code, end-len(tokens[best].token), end }
+    return "", 0, 0
+}
+
+// parse amount with currency including locale (like '$1,234.56', '-1.234,56 €'
+// or 'CHF 1’234.55'). Currency symbol or ISO 4217 code can be put before
+// or after amount. Return value, true if amount is negative, code of currency
+// (empty if no currency in string or if symbol is ambiguous, like 'kr'
+// without Scandinavian locale) and error (nil if no error).
+func LocaleParseCurrency(lang, str string, precision uint,
+                    rounding bool) (UDec128, bool, string, error) {
+    l := getLocFmt(lang)
+    code, start, end := findCurrencyToken(l, lang, str)
+    if end>start {
+        before := strings.TrimRightFunc(str[:start], isSpaceRune)
+        str = before + strings.TrimLeftFunc(str[end:], isSpaceRune)
+    }
+    v, negative, err := localeParseUDec128Style(l, l.Grouping, l.FracGrouping, str,
+                                precision, rounding, StyleDecimal, false)
+    if err!=nil { return UDec128{}, false, "", err }
+    return v, negative, code, nil
+}
+
+// parse amount with currency including locale from bytes
+func LocaleParseCurrencyBytes(lang string, str []byte, precision uint,
+                    rounding bool) (UDec128, bool, string, error) {
+    return LocaleParseCurrency(lang, string(str), precision, rounding)
+}
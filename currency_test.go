@@ -0,0 +1,206 @@
+/*
+ * currency_test.go - tests for currency formatting and parsing
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type UDec128LocFmtCurrencyTC struct {
+    lang, code string
+    value UDec128
+    precision uint
+    display CurrencyDisplay
+    negative bool
+    expected string
+}
+
+func TestUDec128LocaleFormatCurrency(t *testing.T) {
+    testCases := []UDec128LocFmtCurrencyTC {
+        UDec128LocFmtCurrencyTC{ "en", "USD", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "$1,234.56" },
+        UDec128LocFmtCurrencyTC{ "en", "USD", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplayCode, true, "-USD\u00a01,234.56" },
+        UDec128LocFmtCurrencyTC{ "de", "EUR", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "1.234,56\u00a0€" },
+        UDec128LocFmtCurrencyTC{ "de", "EUR", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplaySymbol, true, "-1.234,56\u00a0€" },
+        UDec128LocFmtCurrencyTC{ "de-CH", "CHF", UDec128{ 123455, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "CHF\u00a01’234.55" },
+        UDec128LocFmtCurrencyTC{ "en", "CHF", UDec128{ 123455, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "CHF\u00a01,234.55" },
+        UDec128LocFmtCurrencyTC{ "en", "JPY", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "¥1,235" },
+        UDec128LocFmtCurrencyTC{ "ja", "JPY", UDec128{ 1234, 0 }, 0,
+                    CurrencyDisplaySymbol, false, "￥1,234" },
+        UDec128LocFmtCurrencyTC{ "en", "BHD", UDec128{ 12345, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "BHD\u00a0123.450" },
+        UDec128LocFmtCurrencyTC{ "en", "CAD", UDec128{ 100, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "CA$1.00" },
+        UDec128LocFmtCurrencyTC{ "en", "CAD", UDec128{ 100, 0 }, 2,
+                    CurrencyDisplayNarrow, false, "$1.00" },
+        UDec128LocFmtCurrencyTC{ "en-CA", "CAD", UDec128{ 100, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "$1.00" },
+        UDec128LocFmtCurrencyTC{ "fr-CA", "USD", UDec128{ 100, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "1,00\u00a0$\u00a0US" },
+        UDec128LocFmtCurrencyTC{ "fr", "EUR", UDec128{ 123456, 0 }, 2,
+                    CurrencyDisplayCode, false, "1\u00a0234,56\u00a0EUR" },
+        UDec128LocFmtCurrencyTC{ "pl", "PLN", UDec128{ 1234567, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "12\u00a0345,67\u00a0zł" },
+        UDec128LocFmtCurrencyTC{ "pt", "BRL", UDec128{ 12345, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "R$\u00a0123,45" },
+        UDec128LocFmtCurrencyTC{ "nl", "EUR", UDec128{ 12345, 0 }, 2,
+                    CurrencyDisplaySymbol, true, "-€\u00a0123,45" },
+        UDec128LocFmtCurrencyTC{ "en-IN", "INR", UDec128{ 1234567890, 0 }, 2,
+                    CurrencyDisplaySymbol, false, "₹1,23,45,678.90" },
+        UDec128LocFmtCurrencyTC{ "en", "USD", UDec128{ 5, 0 }, 0,
+                    CurrencyDisplaySymbol, false, "$5.00" },
+        UDec128LocFmtCurrencyTC{ "en", "BHD", UDec128{ 5, 0 }, 1,
+                    CurrencyDisplaySymbol, false, "BHD\u00a00.500" },
+        UDec128LocFmtCurrencyTC{ "en", "USD", UDec128{ 99999, 0 }, 3,
+                    CurrencyDisplaySymbol, false, "$100.00" },
+    }
+    for i, tc := range testCases {
+        result, err := tc.value.LocaleFormatCurrency(tc.lang, tc.code, tc.precision,
+                                    tc.display, tc.negative, RoundHalfUp)
+        if tc.expected!=result || err!=nil {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v,%v)->%q!=%q,%v",
+                     i, tc.lang, tc.code, tc.value, tc.expected, result, err)
+        }
+        // parse formatted amount and format it again
+        v, negative, code, err := LocaleParseCurrency(tc.lang, result, tc.precision,
+                                                      false)
+        if tc.display==CurrencyDisplayNarrow { code = tc.code }
+        if err!=nil || negative!=tc.negative || code!=tc.code {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v!=%v,%v,%v",
+                     i, result, tc.negative, tc.code, negative, code, err)
+            continue
+        }
+        result2, _ := v.LocaleFormatCurrency(tc.lang, tc.code, tc.precision,
+                                    tc.display, negative, RoundHalfUp)
+        if result2!=result {
+            t.Errorf("Result mismatch: %d: reformat(%q)->%q", i, result, result2)
+        }
+    }
+    if _, err := (UDec128{ 1, 0 }).LocaleFormatCurrency("en", "XXY", 0,
+                CurrencyDisplaySymbol, false, RoundHalfUp); err!=ErrUnknownCurrency {
+        t.Errorf("Error mismatch: unknown: %v", err)
+    }
+}
+
+type LocaleParseCurrencyTC struct {
+    lang, str string
+    expected UDec128
+    negative bool
+    code string
+    err error
+}
+
+func TestLocaleParseCurrency(t *testing.T) {
+    testCases := []LocaleParseCurrencyTC {
+        LocaleParseCurrencyTC{ "en", "$1,234.56", UDec128{ 123456, 0 }, false,
+                    "USD", nil },
+        LocaleParseCurrencyTC{ "en", "-$1.00", UDec128{ 100, 0 }, true, "USD", nil },
+        LocaleParseCurrencyTC{ "en", "$-1.00", UDec128{ 100, 0 }, true, "USD", nil },
+        LocaleParseCurrencyTC{ "en", "USD 5", UDec128{ 500, 0 }, false, "USD", nil },
+        LocaleParseCurrencyTC{ "en", "5 EUR", UDec128{ 500, 0 }, false, "EUR", nil },
+        LocaleParseCurrencyTC{ "en", "CA$3.50", UDec128{ 350, 0 }, false, "CAD", nil },
+        LocaleParseCurrencyTC{ "en-CA", "$3.50", UDec128{ 350, 0 }, false, "CAD", nil },
+        LocaleParseCurrencyTC{ "en", "¥1,235", UDec128{ 123500, 0 }, false, "JPY", nil },
+        LocaleParseCurrencyTC{ "de", "1.234,56 €", UDec128{ 123456, 0 }, false,
+                    "EUR", nil },
+        LocaleParseCurrencyTC{ "de", "-1.234,56\u00a0€", UDec128{ 123456, 0 }, true,
+                    "EUR", nil },
+        LocaleParseCurrencyTC{ "de-CH", "CHF 1’234.55", UDec128{ 123455, 0 }, false,
+                    "CHF", nil },
+        LocaleParseCurrencyTC{ "de-CH", "CHF 1'234.55", UDec128{ 123455, 0 }, false,
+                    "CHF", nil },
+        LocaleParseCurrencyTC{ "pl", "12 345,67 zł", UDec128{ 1234567, 0 }, false,
+                    "PLN", nil },
+        LocaleParseCurrencyTC{ "pl", "12 345,67 PLN", UDec128{ 1234567, 0 }, false,
+                    "PLN", nil },
+        LocaleParseCurrencyTC{ "en", "12.5", UDec128{ 1250, 0 }, false, "", nil },
+        // narrow symbol of many currencies
+        LocaleParseCurrencyTC{ "en", "kr 12.50", UDec128{ 1250, 0 }, false, "", nil },
+        LocaleParseCurrencyTC{ "en", "12.50 kr", UDec128{ 1250, 0 }, false, "", nil },
+        LocaleParseCurrencyTC{ "sv", "12,50 kr", UDec128{ 1250, 0 }, false, "SEK", nil },
+        LocaleParseCurrencyTC{ "en", "$", UDec128{}, false, "", strconv.ErrSyntax },
+        LocaleParseCurrencyTC{ "en", "$1$", UDec128{}, false, "", strconv.ErrSyntax },
+        LocaleParseCurrencyTC{ "en", "1 XYZ", UDec128{}, false, "", strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, negative, code, err := LocaleParseCurrency(tc.lang, tc.str, 2, false)
+        if tc.expected!=result || tc.negative!=negative || tc.code!=code ||
+                tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v,%q,%v!=%v,%v,%q,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.code, tc.err,
+                     result, negative, code, err)
+        }
+        result, negative, code, err = LocaleParseCurrencyBytes(tc.lang,
+                        []byte(tc.str), 2, false)
+        if tc.expected!=result || tc.negative!=negative || tc.code!=code ||
+                tc.err!=err {
+            t.Errorf("Result mismatch: %d: parseBytes(%q)->%v,%v,%q,%v!=%v,%v,%q,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.code, tc.err,
+                     result, negative, code, err)
+        }
+    }
+}
+
+func TestRegisterCurrency(t *testing.T) {
+    // cached tokens are refreshed after registration
+    if _, _, _, err := LocaleParseCurrency("en", "₿1", 8, false); err==nil {
+        t.Errorf("Unregistered currency is parsed")
+    }
+    if err := RegisterCurrency(Currency{ Code: "XBT", Symbol: "₿", Digits: 8 });
+            err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterCurrency("XBT")
+    if err := RegisterCurrency(Currency{ Code: "xbt" }); err!=ErrInvalidCurrency {
+        t.Errorf("Error mismatch: invalid: %v", err)
+    }
+    result, err := UDec128{ 15, 0 }.LocaleFormatCurrency("en", "XBT", 1,
+                CurrencyDisplaySymbol, false, RoundHalfUp)
+    if result!="₿1.50000000" || err!=nil {
+        t.Errorf("Result mismatch: format: %q,%v", result, err)
+    }
+    result, err = UDec128{ 15, 0 }.LocaleFormatCurrency("en", "XBT", 1,
+                CurrencyDisplayNarrow, false, RoundHalfUp)
+    if result!="₿1.50000000" || err!=nil {
+        t.Errorf("Result mismatch: narrow: %q,%v", result, err)
+    }
+    v, _, code, err := LocaleParseCurrency("en", "₿0.00000001", 8, false)
+    if v!=(UDec128{ 1, 0 }) || code!="XBT" || err!=nil {
+        t.Errorf("Result mismatch: parse: %v,%q,%v", v, code, err)
+    }
+    c, err := LookupCurrency("XBT")
+    if c==nil || c.Digits!=8 || err!=nil {
+        t.Errorf("Result mismatch: lookup: %v,%v", c, err)
+    }
+    UnregisterCurrency("XBT")
+    if _, _, _, err := LocaleParseCurrency("en", "₿1", 8, false); err==nil {
+        t.Errorf("Unregistered currency is parsed")
+    }
+}
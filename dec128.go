@@ -210,58 +210,83 @@ func UDec128DivFull(hi, lo, b UDec128) UDec128 {
     return UDec128(q)
 }
 
-var zeroPart []byte = []byte("0.000000000000000000000000000")
-
-// new format routine with additional displayPrecision argument.
-func (a UDec128) FormatNew(precision, displayPrecision uint, trimZeroes bool) string {
-    if a[0]==0 && a[1]==0 { return "0.0" }
-    if precision==0 { return goint128.UInt128(a).Format() }
-    str := goint128.UInt128(a).FormatBytes()
-    slen := len(str)
-    i := slen
-    if slen <= int(precision) {
-        if trimZeroes {
-            for i--; i>=0; i-- {
-                if str[i]!='0' { break }
-            }
-            i++
+// append digits of 128-bit unsigned integer to buffer (from end of buffer)
+// and return slice of buffer with digits
+func uint128DigitsBuf(buf *[40]byte, a goint128.UInt128) []byte {
+    i := len(buf)
+    hi, lo := a[1], a[0]
+    for hi!=0 {
+        // divide by 10**19 and put 19 digits of remainder
+        var r uint64
+        hi, r = bits.Div64(0, hi, 10000000000000000000)
+        lo, r = bits.Div64(r, lo, 10000000000000000000)
+        for k := 0; k < 19; k++ {
+            i--
+            buf[i] = '0'+byte(r%10)
+            r /= 10
         }
-        var os strings.Builder
-        os.Write(zeroPart[:2+int(precision)-slen])
-        os.Write(str[:i])
-        return os.String()
     }
-    if trimZeroes {
-        for i--; i>=slen-int(precision); i-- {
-            if str[i]!='0' { break }
-        }
-        i++
+    for {
+        i--
+        buf[i] = '0'+byte(lo%10)
+        lo /= 10
+        if lo==0 { break }
     }
-    var os strings.Builder
-    os.Grow(i)
-    os.Write(str[:slen-int(precision)])
-    dotPos := os.Len()
-    os.WriteByte('.')
-    if (trimZeroes && precision<displayPrecision) || precision==displayPrecision {
-        os.Write(str[slen-int(precision):i])
-    } else if precision>displayPrecision {
-        x := slen-int(precision)+int(displayPrecision) // fix
-        if trimZeroes {
-            if x>i { x = i }
-            for x--; str[x]=='0'; x-- { }
-            x++
-        }
-        os.Write(str[slen-int(precision):x])
+    return buf[i:]
+}
+
+// append formatted number with additional displayPrecision argument to dst
+// and return extended buffer. Does not allocate if dst has enough capacity.
+func (a UDec128) AppendFormat(dst []byte, precision, displayPrecision uint,
+                              trimZeroes bool) []byte {
+    if a[0]==0 && a[1]==0 { return append(dst, '0', '.', '0') }
+    var buf [40]byte
+    str := uint128DigitsBuf(&buf, goint128.UInt128(a))
+    if precision==0 { return append(dst, str...) }
+    nz := 0 // number of leading zeroes in fraction part
+    if len(str) > int(precision) {
+        dst = append(dst, str[:len(str)-int(precision)]...)
+        str = str[len(str)-int(precision):]
     } else {
-        os.Write(str[slen-int(precision):i])
-        for i:=0; i < int(displayPrecision-precision); i++ {
-            os.WriteByte('0')
+        dst = append(dst, '0')
+        nz = int(precision)-len(str)
+    }
+    dst = append(dst, '.')
+    dotPos := len(dst)
+    // cut digits if displayPrecision is lesser
+    n := int(precision)
+    if displayPrecision<precision { n = int(displayPrecision) }
+    if n<=nz {
+        nz = n
+        str = str[:0]
+    } else if n-nz < len(str) {
+        str = str[:n-nz]
+    }
+    if trimZeroes {
+        i := len(str)
+        for ; i>0 && str[i-1]=='0'; i-- { }
+        str = str[:i]
+        if i==0 { nz = 0 }
+    }
+    for ; nz>0; nz-- {
+        dst = append(dst, '0')
+    }
+    dst = append(dst, str...)
+    if !trimZeroes {
+        for i := precision; i < displayPrecision; i++ {
+            dst = append(dst, '0')
         }
     }
-    if os.Len()==dotPos+1 {
-        os.WriteByte('0')
+    if len(dst)==dotPos {
+        dst = append(dst, '0')
     }
-    return os.String()
+    return dst
+}
+
+// new format routine with additional displayPrecision argument.
+func (a UDec128) FormatNew(precision, displayPrecision uint, trimZeroes bool) string {
+    var buf [64]byte
+    return string(a.AppendFormat(buf[:0], precision, displayPrecision, trimZeroes))
 }
 
 // format number
@@ -272,56 +297,9 @@ func (a UDec128) Format(precision uint, trimZeroes bool) string {
 // new format routine with additional displayPrecision argument. Format to bytes
 func (a UDec128) FormatNewBytes(precision, displayPrecision uint,
                                 trimZeroes bool) []byte {
-    if a[0]==0 && a[1]==0 { return zeroPart[:3] }
-    if precision==0 { return goint128.UInt128(a).FormatBytes() }
-    str := goint128.UInt128(a).FormatBytes()
-    slen := len(str)
-    i := slen
-    if slen <= int(precision) {
-        if trimZeroes {
-            for i--; i>=0; i-- {
-                if str[i]!='0' { break }
-            }
-            i++
-        }
-        l := 2+int(precision)-slen
-        os := make([]byte, l+i)
-        copy(os[:l], zeroPart[:l])
-        copy(os[l:], str[:i])
-        return os
-    }
-    if trimZeroes {
-        for i--; i>=slen-int(precision); i-- {
-            if str[i]!='0' { break }
-        }
-        i++
-    }
-    os := make([]byte, i+1)
-    l := slen-int(precision)
-    copy(os[:l], str[:l])
-    dotPos := l
-    os[l] = '.'
-    copy(os[l+1:], str[slen-int(precision):i])
-    if !(trimZeroes && precision<displayPrecision) && precision!=displayPrecision {
-        if precision>displayPrecision {
-            x := slen+1-int(precision)+int(displayPrecision) // fix
-            if trimZeroes {
-                if x>i+1 { x = i+1 }
-                for x--; os[x]=='0'; x-- { }
-                x++
-            }
-            os = os[:x]
-        } else {
-            copy(os[l+1:], str[slen-int(precision):i])
-            for i:=0; i < int(displayPrecision-precision); i++ {
-                os = append(os, '0')
-            }
-        }
-    }
-    if len(os)==dotPos+1 {
-        os = append(os, '0')
-    }
-    return os
+    var buf [64]byte
+    s := a.AppendFormat(buf[:0], precision, displayPrecision, trimZeroes)
+    return append([]byte(nil), s...)
 }
 
 // format number to bytes
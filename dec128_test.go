@@ -616,6 +616,56 @@ func TestUDec128Format(t *testing.T) {
     }
 }
 
+func TestUDec128AppendFormat(t *testing.T) {
+    testCases := []UDec128Fmt2TC {
+        UDec128Fmt2TC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15, 15, false,
+            "217224419425.143693331510191" },
+        UDec128Fmt2TC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15, 10, true,
+            "217224419425.1436933315" },
+        UDec128Fmt2TC{ UDec128{ 0x5f75348b0131b2f0, 0xb3af0f }, 15, 17, false,
+            "217224419425.14369333151000000" },
+        UDec128Fmt2TC{ UDec128{ 0, 0 }, 15, 15, false, "0.0" },
+        UDec128Fmt2TC{ UDec128{ 1984593924560, 0 }, 15, 15, true,
+            "0.00198459392456" },
+        UDec128Fmt2TC{ UDec128{ 1984593924560, 0 }, 15, 5, false, "0.00198" },
+        UDec128Fmt2TC{ UDec128{ 1984593924560, 0 }, 15, 17, false,
+            "0.00198459392456000" },
+        UDec128Fmt2TC{ UDec128{ 1984593924560, 0 }, 15, 2, false, "0.00" },
+        UDec128Fmt2TC{ UDec128{ 1984593924560, 0 }, 15, 2, true, "0.0" },
+        UDec128Fmt2TC{ UDec128{ 33000000000000000, 0 }, 15, 0, false, "33.0" },
+        UDec128Fmt2TC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 0, 0, false,
+            "217224419425143693331510191" },
+        UDec128Fmt2TC{ UDec128{ 0xffffffffffffffff, 0xffffffffffffffff }, 0, 0, false,
+            "340282366920938463463374607431768211455" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        result := tc.a.AppendFormat([]byte("xx"), tc.precision, tc.dispPrecision,
+                                    tc.trimZeroes)
+        if "xx"+tc.expected!=string(result) {
+            t.Errorf("Result mismatch: %d: appendFmt(%v)->%v!=%v",
+                     i, tc.a, tc.expected, string(result))
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d: %v!=%v", i, a, tc.a)
+        }
+        result2 := tc.a.FormatNew(tc.precision, tc.dispPrecision, tc.trimZeroes)
+        if tc.expected!=result2 {
+            t.Errorf("Result mismatch: %d: fmt(%v)->%v!=%v",
+                     i, tc.a, tc.expected, result2)
+        }
+    }
+    
+    a := UDec128{ 0x5f75348b0131b3af, 0xb3af0f }
+    buf := make([]byte, 0, 64)
+    allocs := testing.AllocsPerRun(100, func() {
+        buf = a.AppendFormat(buf[:0], 15, 12, false)
+    })
+    if allocs!=0 {
+        t.Errorf("AppendFormat allocates: %v", allocs)
+    }
+}
+
 type UDec128ParseTC struct {
     str string
     precision uint
@@ -795,3 +845,18 @@ func TestFloat64ToUDec128(t *testing.T) {
         }
     }
 }
+
+func BenchmarkUDec128FormatBytes(b *testing.B) {
+    a := UDec128{ 7341542494928938945, 938491 }
+    for i := 0; i < b.N; i++ {
+        a.FormatBytes(8, false)
+    }
+}
+
+func BenchmarkUDec128AppendFormat(b *testing.B) {
+    a := UDec128{ 7341542494928938945, 938491 }
+    buf := make([]byte, 0, 64)
+    for i := 0; i < b.N; i++ {
+        buf = a.AppendFormat(buf[:0], 8, 8, false)
+    }
+}
@@ -0,0 +1,309 @@
+/*
+ * detect.go - detection of decimal and thousand separators
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "errors"
+    "strconv"
+    "unicode/utf8"
+)
+
+var (
+    ErrAmbiguousSeparator = errors.New("godec128: ambiguous decimal separator")
+)
+
+// confidence of detection of separators
+type Confidence uint8
+
+const (
+    // convention can not be determined (number without separators)
+    ConfidenceLow Confidence = iota
+    // convention is guessed from number of digits
+    ConfidenceMedium
+    // convention is certain
+    ConfidenceHigh
+)
+
+// detected convention of separators
+type SeparatorGuess struct {
+    // decimal separator, zero if not determined
+    Comma rune
+    // thousand separator, zero if not found
+    Sep1000 rune
+    Confidence Confidence
+}
+
+// return true if rune can be only thousand separator
+func isGroupOnlySep(r rune) bool {
+    return isSpaceRune(r) || r=='\'' || r=='’' || r=='_'
+}
+
+// separator found in number
+type detectSep struct {
+    r rune
+    // number of digits before separator
+    digits int
+}
+
+// scan number and return separators and number of all digits
+func detectScan(str []byte) ([]detectSep, int, error) {
+    var seps []detectSep
+    digits := 0
+    for len(str)>0 {
+        r, size := utf8.DecodeRune(str)
+        switch {
+        case r>='0' && r<='9':
+            digits++
+        case r=='.' || r==',' || isGroupOnlySep(r):
+            if digits==0 { return nil, 0, strconv.ErrSyntax }
+            seps = append(seps, detectSep{ r, digits })
+        default:
+            return nil, 0, strconv.ErrSyntax
+        }
+        str = str[size:]
+    }
+    if digits==0 { return nil, 0, strconv.ErrSyntax }
+    return seps, digits, nil
+}
+
+// check groups between thousand separators: first group has one to three
+// digits, last group has three digits, other groups have three digits or
+// two digits (Indian grouping). intDigits is number of digits of integer part.
+func checkDetectGroups(seps []detectSep, sep1000 rune, intDigits int) error {
+    prev, size := 0, 0
+    for _, s := range seps {
+        if s.r!=sep1000 { continue }
+        n := s.digits-prev
+        if prev==0 {
+            if n>3 { return strconv.ErrSyntax }
+        } else {
+            if n!=2 && n!=3 { return strconv.ErrSyntax }
+            if size!=0 && size!=n { return strconv.ErrSyntax }
+            size = n
+        }
+        prev = s.digits
+    }
+    if intDigits-prev!=3 { return strconv.ErrSyntax }
+    return nil
+}
+
+// detect separators in single number
+func detectSeparators(str []byte) (SeparatorGuess, error) {
+    seps, digits, err := detectScan(str)
+    if err!=nil { return SeparatorGuess{}, err }
+    g, err := guessSeparators(str, seps, digits)
+    if err!=nil || g.Sep1000==0 { return g, err }
+    intDigits := digits
+    for _, s := range seps {
+        if s.r==g.Comma { intDigits = s.digits }
+    }
+    if err := checkDetectGroups(seps, g.Sep1000, intDigits); err!=nil {
+        return SeparatorGuess{}, err
+    }
+    return g, nil
+}
+
+// guess separators from separators found in number (without checking groups)
+func guessSeparators(str []byte, seps []detectSep, digits int) (SeparatorGuess, error) {
+    if len(seps)==0 { return SeparatorGuess{}, nil }
+    last := seps[len(seps)-1]
+    // separator that can be only thousand separator
+    var groupSep rune
+    for _, s := range seps {
+        if isGroupOnlySep(s.r) {
+            if groupSep!=0 && groupSep!=s.r { return SeparatorGuess{}, strconv.ErrSyntax }
+            groupSep = s.r
+        }
+    }
+    if isGroupOnlySep(last.r) {
+        // all separators must be thousand separators
+        for _, s := range seps {
+            if s.r!=groupSep { return SeparatorGuess{}, strconv.ErrSyntax }
+        }
+        return SeparatorGuess{ Sep1000: groupSep, Confidence: ConfidenceHigh }, nil
+    }
+    // last separator is '.' or ','
+    count := 0
+    for _, s := range seps {
+        if s.r==last.r { count++ }
+    }
+    other := rune('.')
+    if last.r=='.' { other = ',' }
+    hasOther := false
+    for _, s := range seps {
+        if s.r==other { hasOther = true }
+    }
+    if count>1 {
+        // repeated separator is thousand separator
+        if hasOther || groupSep!=0 { return SeparatorGuess{}, strconv.ErrSyntax }
+        return SeparatorGuess{ Comma: other, Sep1000: last.r,
+                    Confidence: ConfidenceHigh }, nil
+    }
+    if hasOther {
+        if groupSep!=0 { return SeparatorGuess{}, strconv.ErrSyntax }
+        return SeparatorGuess{ Comma: last.r, Sep1000: other,
+                    Confidence: ConfidenceHigh }, nil
+    }
+    if groupSep!=0 {
+        return SeparatorGuess{ Comma: last.r, Sep1000: groupSep,
+                    Confidence: ConfidenceHigh }, nil
+    }
+    fracDigits := digits-last.digits
+    switch {
+    case fracDigits!=3:
+        return SeparatorGuess{ Comma: last.r, Confidence: ConfidenceHigh }, nil
+    case last.digits>3:
+        // groups can not have more than three digits
+        return SeparatorGuess{ Comma: last.r, Confidence: ConfidenceMedium }, nil
+    case last.digits==1 && str[0]=='0':
+        // leading zero before thousand separator is not used
+        return SeparatorGuess{ Comma: last.r, Confidence: ConfidenceHigh }, nil
+    }
+    // like '1,234' or '12.345'
+    return SeparatorGuess{ Sep1000: last.r }, ErrAmbiguousSeparator
+}
+
+// detect decimal and thousand separators from single number. If number
+// is ambiguous (like '1,234') then ErrAmbiguousSeparator is returned.
+func DetectSeparators(str string) (SeparatorGuess, error) {
+    return detectSeparators([]byte(str))
+}
+
+// detect decimal and thousand separators from sample of numbers
+// (for example column of spreadsheet). Ambiguous numbers are resolved by
+// other numbers. Malformed numbers (like 'abc' or blank) are skipped.
+// If numbers have conflicting conventions or all numbers are ambiguous then
+// ErrAmbiguousSeparator is returned. If no number is usable then
+// strconv.ErrSyntax is returned.
+func DetectSeparatorsSample(samples []string) (SeparatorGuess, error) {
+    var result SeparatorGuess
+    // separators of ambiguous numbers
+    var ambiguousSeps []rune
+    usable := false
+    for _, str := range samples {
+        g, err := detectSeparators([]byte(str))
+        if err==ErrAmbiguousSeparator {
+            usable = true
+            found := false
+            for _, r := range ambiguousSeps {
+                if r==g.Sep1000 { found = true }
+            }
+            if !found { ambiguousSeps = append(ambiguousSeps, g.Sep1000) }
+            continue
+        }
+        if err!=nil { continue }
+        usable = true
+        if g.Comma!=0 {
+            if result.Comma!=0 && result.Comma!=g.Comma {
+                return SeparatorGuess{}, ErrAmbiguousSeparator
+            }
+            result.Comma = g.Comma
+        }
+        if g.Sep1000!=0 {
+            if result.Sep1000!=0 && result.Sep1000!=g.Sep1000 {
+                return SeparatorGuess{}, ErrAmbiguousSeparator
+            }
+            result.Sep1000 = g.Sep1000
+        }
+        if g.Confidence>result.Confidence { result.Confidence = g.Confidence }
+    }
+    if !usable { return SeparatorGuess{}, strconv.ErrSyntax }
+    if result.Comma!=0 && result.Comma==result.Sep1000 {
+        return SeparatorGuess{}, ErrAmbiguousSeparator
+    }
+    for _, r := range ambiguousSeps {
+        switch {
+        case result.Comma==r || result.Sep1000==r:
+            // already determined
+        case result.Comma!=0 && result.Sep1000==0:
+            // decimal separator is other
+            result.Sep1000 = r
+        case result.Sep1000!=0 && result.Comma==0:
+            // thousand separator is other
+            result.Comma = r
+        default:
+            return result, ErrAmbiguousSeparator
+        }
+    }
+    if result.Comma==0 && result.Sep1000!=0 {
+        // complete decimal separator if thousand separator is '.' or ','
+        switch result.Sep1000 {
+        case '.':
+            result.Comma = ','
+        case ',':
+            result.Comma = '.'
+        }
+    }
+    return result, nil
+}
+
+// parse number with detected separators. Positions of thousand separators
+// are checked like in detection.
+func (g SeparatorGuess) ParseBytes(str []byte, precision uint,
+                    rounding bool) (UDec128, error) {
+    if len(str)==0 { return UDec128{}, strconv.ErrSyntax }
+    os := make([]byte, 0, len(str))
+    var seps []detectSep
+    digits, intDigits := 0, -1
+    for len(str)>0 {
+        r, size := utf8.DecodeRune(str)
+        switch {
+        case r>='0' && r<='9':
+            os = append(os, byte(r))
+            digits++
+        case r==g.Comma && r!=0:
+            os = append(os, '.')
+            if intDigits<0 { intDigits = digits }
+        case r==g.Sep1000 && r!=0:
+            // skip thousand separator
+            if digits==0 { return UDec128{}, strconv.ErrSyntax }
+            seps = append(seps, detectSep{ r, digits })
+        default:
+            return UDec128{}, strconv.ErrSyntax
+        }
+        str = str[size:]
+    }
+    if len(seps)!=0 {
+        if intDigits<0 { intDigits = digits }
+        if err := checkDetectGroups(seps, g.Sep1000, intDigits); err!=nil {
+            return UDec128{}, err
+        }
+    }
+    return ParseUDec128Bytes(os, precision, rounding)
+}
+
+// parse number with detected separators
+func (g SeparatorGuess) Parse(str string, precision uint,
+                    rounding bool) (UDec128, error) {
+    return g.ParseBytes([]byte(str), precision, rounding)
+}
+
+// detect separators from number and parse number. Return value, detected
+// separators and error (nil if no error)
+func ParseUDec128Detect(str string, precision uint,
+                    rounding bool) (UDec128, SeparatorGuess, error) {
+    g, err := detectSeparators([]byte(str))
+    if err!=nil { return UDec128{}, g, err }
+    v, err := g.Parse(str, precision, rounding)
+    return v, g, err
+}
@@ -0,0 +1,151 @@
+/*
+ * detect_test.go - tests for detection of decimal and thousand separators
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type DetectSeparatorsTC struct {
+    str string
+    expected SeparatorGuess
+    value UDec128
+    err error
+}
+
+func TestParseUDec128Detect(t *testing.T) {
+    testCases := []DetectSeparatorsTC {
+        DetectSeparatorsTC{ "1.234,56", SeparatorGuess{ ',', '.', ConfidenceHigh },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1,234.56", SeparatorGuess{ '.', ',', ConfidenceHigh },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1 234,56", SeparatorGuess{ ',', ' ', ConfidenceHigh },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1\u00a0234,56", SeparatorGuess{ ',', 0xa0, ConfidenceHigh },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1'234.56", SeparatorGuess{ '.', '\'', ConfidenceHigh },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1,234,567", SeparatorGuess{ '.', ',', ConfidenceHigh },
+                    UDec128{ 123456700, 0 }, nil },
+        DetectSeparatorsTC{ "1.234.567", SeparatorGuess{ ',', '.', ConfidenceHigh },
+                    UDec128{ 123456700, 0 }, nil },
+        DetectSeparatorsTC{ "12,5", SeparatorGuess{ ',', 0, ConfidenceHigh },
+                    UDec128{ 1250, 0 }, nil },
+        DetectSeparatorsTC{ "0,125", SeparatorGuess{ ',', 0, ConfidenceHigh },
+                    UDec128{ 12, 0 }, nil },
+        DetectSeparatorsTC{ "1234.567", SeparatorGuess{ '.', 0, ConfidenceMedium },
+                    UDec128{ 123456, 0 }, nil },
+        DetectSeparatorsTC{ "1 234", SeparatorGuess{ 0, ' ', ConfidenceHigh },
+                    UDec128{ 123400, 0 }, nil },
+        DetectSeparatorsTC{ "1234", SeparatorGuess{ 0, 0, ConfidenceLow },
+                    UDec128{ 123400, 0 }, nil },
+        DetectSeparatorsTC{ "1,234", SeparatorGuess{ 0, ',', ConfidenceLow },
+                    UDec128{}, ErrAmbiguousSeparator },
+        DetectSeparatorsTC{ "12.345", SeparatorGuess{ 0, '.', ConfidenceLow },
+                    UDec128{}, ErrAmbiguousSeparator },
+        DetectSeparatorsTC{ "1,234.5,6", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1 234'5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "12,34,567.5", SeparatorGuess{ '.', ',', ConfidenceHigh },
+                    UDec128{ 123456750, 0 }, nil },
+        DetectSeparatorsTC{ "1..5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1,,234.5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1,234,5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1.2.456,5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1234,567.5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1,234,56,789", SeparatorGuess{}, UDec128{},
+                    strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1 23", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ ",5", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "1x", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+        DetectSeparatorsTC{ "", SeparatorGuess{}, UDec128{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, g, err := ParseUDec128Detect(tc.str, 2, false)
+        if tc.expected!=g || tc.value!=result || tc.err!=err {
+            t.Errorf("Result mismatch: %d: detect(%q)->%v,%v,%v!=%v,%v,%v",
+                     i, tc.str, tc.expected, tc.value, tc.err, g, result, err)
+        }
+    }
+}
+
+type DetectSampleTC struct {
+    samples []string
+    expected SeparatorGuess
+    err error
+}
+
+func TestDetectSeparatorsSample(t *testing.T) {
+    testCases := []DetectSampleTC {
+        DetectSampleTC{ []string{ "1,234", "12,5", "7" },
+                    SeparatorGuess{ ',', 0, ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "1,234", "12.5" },
+                    SeparatorGuess{ '.', ',', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "1.234", "1 234,5" },
+                    SeparatorGuess{}, ErrAmbiguousSeparator },
+        DetectSampleTC{ []string{ "1.234", "5.678,9" },
+                    SeparatorGuess{ ',', '.', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "1,234", "1.234.567" },
+                    SeparatorGuess{ ',', '.', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "1,234", "2,345" },
+                    SeparatorGuess{}, ErrAmbiguousSeparator },
+        DetectSampleTC{ []string{ "1,5", "2.5" },
+                    SeparatorGuess{}, ErrAmbiguousSeparator },
+        DetectSampleTC{ []string{ "1,234", "10" },
+                    SeparatorGuess{}, ErrAmbiguousSeparator },
+        DetectSampleTC{ []string{ "12", "10" },
+                    SeparatorGuess{ 0, 0, ConfidenceLow }, nil },
+        DetectSampleTC{ []string{ "1'234", "10" },
+                    SeparatorGuess{ 0, '\'', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "1,234,567", "10" },
+                    SeparatorGuess{ '.', ',', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "abc", "1.234,5", "", " " },
+                    SeparatorGuess{ ',', '.', ConfidenceHigh }, nil },
+        DetectSampleTC{ []string{ "abc", "" }, SeparatorGuess{}, strconv.ErrSyntax },
+        DetectSampleTC{ nil, SeparatorGuess{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        g, err := DetectSeparatorsSample(tc.samples)
+        if tc.err!=err || (err==nil && tc.expected!=g) {
+            t.Errorf("Result mismatch: %d: detect(%q)->%v,%v!=%v,%v",
+                     i, tc.samples, tc.expected, tc.err, g, err)
+        }
+    }
+    // parse column with detected convention
+    column := []string{ "1.234", "5.678,9", "12" }
+    g, err := DetectSeparatorsSample(column)
+    if err!=nil { t.Fatalf("Unexpected error: %v", err) }
+    expected := []UDec128{ UDec128{ 12340, 0 }, UDec128{ 56789, 0 }, UDec128{ 120, 0 } }
+    for i, str := range column {
+        v, err := g.ParseBytes([]byte(str), 1, false)
+        if v!=expected[i] || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v", i, str, v, err)
+        }
+    }
+    // groups are checked in parsing
+    for i, str := range []string{ "12.34", "1.2345", ".123", "1.234,5.678",
+                "1.234.56" } {
+        if v, err := g.ParseBytes([]byte(str), 1, false); err!=strconv.ErrSyntax {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v", i, str, v, err)
+        }
+    }
+}
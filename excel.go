@@ -0,0 +1,526 @@
+/*
+ * excel.go - Excel (OOXML) number format codes
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+var ErrInvalidFormatCode = errors.New("godec128: invalid format code")
+
+type excelTokenKind uint8
+
+const (
+    excelLiteral excelTokenKind = iota
+    // digit placeholder of integer part, fraction or exponent
+    excelIntDigit
+    excelFracDigit
+    excelExpDigit
+    excelPoint
+    excelPercent
+    excelExponent
+    excelGeneral
+)
+
+type excelToken struct {
+    kind excelTokenKind
+    // placeholder ('0', '#' or '?'), exponent character ('E' or 'e')
+    ch byte
+    // put plus sign in exponent
+    plus bool
+    text string
+}
+
+// condition of section (like '[>=100]')
+type excelCondition struct {
+    op string
+    value string
+    negative bool
+}
+
+// section of format code
+type excelSection struct {
+    tokens []excelToken
+    color string
+    cond *excelCondition
+    // put separators between groups of integer part
+    grouping bool
+    // number of scaling commas (division by 1000) and percent signs
+    scale, percent int
+    // number of digit placeholders
+    nInt, nFrac, nExp int
+    hasExp bool
+}
+
+// compiled Excel number format code
+type ExcelFormat struct {
+    code string
+    loc *LocFmt
+    sections []excelSection
+    conditional bool
+}
+
+// English separators used if no language is given
+var englishLocFmt *LocFmt = latnLocFmt('.', ',', 0)
+
+var excelColors = []string{ "Black", "Blue", "Cyan", "Green", "Magenta", "Red",
+        "White", "Yellow" }
+
+// parse content of brackets in format code
+func (sec *excelSection) parseBracket(s string) error {
+    for _, c := range excelColors {
+        if strings.EqualFold(s, c) {
+            sec.color = c
+            return nil
+        }
+    }
+    if len(s)>5 && strings.EqualFold(s[:5], "Color") {
+        if n, err := strconv.Atoi(s[5:]); err==nil && n>=1 && n<=56 {
+            sec.color = "Color"+s[5:]
+            return nil
+        }
+        return ErrInvalidFormatCode
+    }
+    if len(s)>0 && s[0]=='$' {
+        // currency and locale (like '[$€-407]'), put currency symbol
+        sym := s[1:]
+        if i := strings.IndexByte(sym, '-'); i!=-1 { sym = sym[:i] }
+        sec.tokens = append(sec.tokens, excelToken{ kind: excelLiteral, text: sym })
+        return nil
+    }
+    for _, op := range []string{ "<=", ">=", "<>", "<", ">", "=" } {
+        if !strings.HasPrefix(s, op) { continue }
+        v := strings.TrimSpace(s[len(op):])
+        cond := &excelCondition{ op: op }
+        if strings.HasPrefix(v, "-") {
+            cond.negative = true
+            v = v[1:]
+        }
+        if _, err := ParseUDec128(v, 0, true); err!=nil { return ErrInvalidFormatCode }
+        cond.value = v
+        sec.cond = cond
+        return nil
+    }
+    return ErrInvalidFormatCode
+}
+
+func isExcelPlaceholder(c byte) bool {
+    return c=='0' || c=='#' || c=='?'
+}
+
+// return true if rune can be put in format code without quotes
+func isExcelLiteralRune(r rune) bool {
+    return r>=0x80 || strings.ContainsRune("$-+/():!^&'~{}<>= ", r)
+}
+
+// compile section of format code
+func compileExcelSection(code string) (excelSection, error) {
+    var sec excelSection
+    afterPoint := false
+    literal := func(text string) {
+        sec.tokens = append(sec.tokens, excelToken{ kind: excelLiteral, text: text })
+    }
+    for i := 0; i < len(code); {
+        c := code[i]
+        switch {
+        case c=='"':
+            end := strings.IndexByte(code[i+1:], '"')
+            if end==-1 { return sec, ErrInvalidFormatCode }
+            literal(code[i+1:i+1+end])
+            i += end+2
+        case c=='\\' || c=='_' || c=='*':
+            if i+1>=len(code) { return sec, ErrInvalidFormatCode }
+            _, size := utf8.DecodeRuneInString(code[i+1:])
+            switch c {
+            case '\\':
+                literal(code[i+1:i+1+size])
+            case '_':
+                // space of width of character
+                literal(" ")
+            }
+            // fill ('*') is ignored
+            i += 1+size
+        case c=='[':
+            end := strings.IndexByte(code[i:], ']')
+            if end==-1 { return sec, ErrInvalidFormatCode }
+            if err := sec.parseBracket(code[i+1:i+end]); err!=nil { return sec, err }
+            i += end+1
+        case isExcelPlaceholder(c):
+            t := excelToken{ kind: excelIntDigit, ch: c }
+            switch {
+            case sec.hasExp:
+                t.kind = excelExpDigit
+                sec.nExp++
+            case afterPoint:
+                t.kind = excelFracDigit
+                sec.nFrac++
+            default:
+                sec.nInt++
+            }
+            sec.tokens = append(sec.tokens, t)
+            i++
+        case c=='.':
+            if afterPoint || sec.hasExp { return sec, ErrInvalidFormatCode }
+            afterPoint = true
+            sec.tokens = append(sec.tokens, excelToken{ kind: excelPoint })
+            i++
+        case c==',':
+            n := len(sec.tokens)
+            prevDigit := n>0 && (sec.tokens[n-1].kind==excelIntDigit ||
+                    sec.tokens[n-1].kind==excelFracDigit)
+            switch {
+            case prevDigit && !afterPoint && i+1<len(code) &&
+                    isExcelPlaceholder(code[i+1]):
+                sec.grouping = true
+            case prevDigit || (i>0 && code[i-1]==',' && sec.scale>0):
+                sec.scale++
+            default:
+                literal(",")
+            }
+            i++
+        case c=='%':
+            sec.percent++
+            sec.tokens = append(sec.tokens, excelToken{ kind: excelPercent })
+            i++
+        case (c=='E' || c=='e') && i+1<len(code) &&
+                (code[i+1]=='+' || code[i+1]=='-'):
+            if sec.hasExp { return sec, ErrInvalidFormatCode }
+            sec.hasExp = true
+            sec.tokens = append(sec.tokens, excelToken{ kind: excelExponent, ch: c,
+                            plus: code[i+1]=='+' })
+            i += 2
+        case len(code)-i>=7 && strings.EqualFold(code[i:i+7], "General"):
+            sec.tokens = append(sec.tokens, excelToken{ kind: excelGeneral })
+            i += 7
+        case c=='@':
+            // text placeholder, not used by numbers
+            i++
+        case c=='/' && ((i>0 && isExcelPlaceholder(code[i-1])) ||
+                (i+1<len(code) && isExcelPlaceholder(code[i+1]))):
+            // fractions (like '# ?/?') are not supported
+            return sec, ErrInvalidFormatCode
+        default:
+            r, size := utf8.DecodeRuneInString(code[i:])
+            if !isExcelLiteralRune(r) { return sec, ErrInvalidFormatCode }
+            literal(code[i:i+size])
+            i += size
+        }
+    }
+    if sec.hasExp && (sec.nExp==0 || sec.nInt+sec.nFrac==0) {
+        return sec, ErrInvalidFormatCode
+    }
+    return sec, nil
+}
+
+// split format code into sections separated by ';' (outside quotes and brackets)
+func splitExcelSections(code string) []string {
+    var sections []string
+    start := 0
+    quoted, bracket := false, false
+    for i := 0; i < len(code); i++ {
+        switch c := code[i]; {
+        case quoted:
+            if c=='"' { quoted = false }
+        case bracket:
+            if c==']' { bracket = false }
+        case c=='"':
+            quoted = true
+        case c=='[':
+            bracket = true
+        case c=='\\' || c=='_' || c=='*':
+            i++
+        case c==';':
+            sections = append(sections, code[start:i])
+            start = i+1
+        }
+    }
+    return append(sections, code[start:])
+}
+
+// compile Excel number format code (like '#,##0.00;[Red](#,##0.00);"-"'
+// or '0.000E+00') with separators of locale (English if lang is empty). Format code can have up to
+// four sections: positive numbers, negative numbers, zero and text.
+// If format code is malformed then ErrInvalidFormatCode is returned.
+func CompileExcelFormat(code, lang string) (*ExcelFormat, error) {
+    parts := splitExcelSections(code)
+    if len(parts)>4 { return nil, ErrInvalidFormatCode }
+    // text section is not used by numbers
+    if len(parts)==4 { parts = parts[:3] }
+    f := &ExcelFormat{ code: code, loc: englishLocFmt }
+    if lang!="" { f.loc = getLocFmt(lang) }
+    for _, p := range parts {
+        sec, err := compileExcelSection(p)
+        if err!=nil { return nil, err }
+        if sec.cond!=nil { f.conditional = true }
+        f.sections = append(f.sections, sec)
+    }
+    return f, nil
+}
+
+// return format code
+func (f *ExcelFormat) String() string {
+    return f.code
+}
+
+// compare signed numbers (negative flag of zero is ignored)
+func cmpSigned(a UDec128, aneg bool, b UDec128, bneg bool) int {
+    aneg = aneg && !a.IsZero()
+    bneg = bneg && !b.IsZero()
+    switch {
+    case aneg && !bneg:
+        return -1
+    case !aneg && bneg:
+        return 1
+    case aneg:
+        return b.Cmp(a)
+    }
+    return a.Cmp(b)
+}
+
+// return true if value satisfies condition
+func (cond *excelCondition) match(a UDec128, precision uint, negative bool) bool {
+    v, err := ParseUDec128(cond.value, precision, true)
+    if err!=nil { return false }
+    c := cmpSigned(a, negative, v, cond.negative)
+    switch cond.op {
+    case "<":
+        return c<0
+    case "<=":
+        return c<=0
+    case ">":
+        return c>0
+    case ">=":
+        return c>=0
+    case "<>":
+        return c!=0
+    }
+    return c==0
+}
+
+// return true if condition is satisfied only by negative numbers
+func (cond *excelCondition) onlyNegative() bool {
+    zero := strings.Trim(cond.value, "0.")==""
+    return (cond.op=="<" && (cond.negative || zero)) ||
+            (cond.op=="<=" && cond.negative && !zero)
+}
+
+// choose section for value and return it and true if minus sign should be put
+func (f *ExcelFormat) section(a UDec128, precision uint,
+                    negative bool) (*excelSection, bool) {
+    negative = negative && !a.IsZero()
+    n := len(f.sections)
+    if f.conditional {
+        for i := range f.sections {
+            sec := &f.sections[i]
+            if sec.cond==nil || sec.cond.match(a, precision, negative) {
+                return sec, negative && (sec.cond==nil || !sec.cond.onlyNegative())
+            }
+        }
+        return &f.sections[n-1], negative
+    }
+    switch {
+    case a.IsZero() && n>=3:
+        return &f.sections[2], false
+    case negative && n>=2:
+        return &f.sections[1], false
+    }
+    return &f.sections[0], negative
+}
+
+// return color of section used to format value (like 'Red' or 'Color10')
+// or empty string if section has no color
+func (f *ExcelFormat) Color(a UDec128, precision uint, negative bool) string {
+    sec, _ := f.section(a, precision, negative)
+    return sec.color
+}
+
+// get digit of number at position (from first digit) or zero if outside number
+func digitAt(digits []byte, pos int) byte {
+    if pos<0 || pos>=len(digits) { return '0' }
+    return digits[pos]
+}
+
+// compute integer part, fraction and exponent of number for section
+func (sec *excelSection) split(a UDec128, precision uint) ([]byte, []byte, int) {
+    digits := udec128Digits(a)
+    if a.IsZero() { digits = digits[:0] }
+    // position of point in digits
+    intLen := len(digits)-int(precision) + 2*sec.percent - 3*sec.scale
+    exp := 0
+    if sec.hasExp && len(digits)!=0 {
+        for {
+            mantInt := sec.nInt
+            if mantInt==0 { mantInt = 1 }
+            if sec.nInt>1 && sec.firstIntDigit()=='#' {
+                // engineering notation: exponent is multiple of integer digits
+                exp = intLen-1
+                if exp>=0 {
+                    exp -= exp%sec.nInt
+                } else {
+                    exp -= (sec.nInt - (-exp)%sec.nInt)%sec.nInt
+                }
+            } else {
+                exp = intLen-mantInt
+            }
+            keep := intLen-exp+sec.nFrac
+            rounded, carry := roundDigitsHalfUp(append([]byte(nil), digits...), keep)
+            if !carry {
+                digits = rounded
+                break
+            }
+            // rounding carried into next digit, choose exponent again
+            digits = rounded
+            intLen++
+        }
+        intLen -= exp
+    } else {
+        var carry bool
+        digits, carry = roundDigitsHalfUp(digits, intLen+sec.nFrac)
+        if carry { intLen++ }
+    }
+    var intPart []byte
+    for i := 0; i < intLen; i++ {
+        d := digitAt(digits, i)
+        if len(intPart)==0 && d=='0' { continue }
+        intPart = append(intPart, d)
+    }
+    frac := make([]byte, sec.nFrac)
+    for i := range frac { frac[i] = digitAt(digits, intLen+i) }
+    return intPart, frac, exp
+}
+
+// return first placeholder of integer part
+func (sec *excelSection) firstIntDigit() byte {
+    for _, t := range sec.tokens {
+        if t.kind==excelIntDigit { return t.ch }
+    }
+    return 0
+}
+
+// append digits to placeholders aligned to right. n is number of placeholders
+// and k is index of placeholder. If grouping is enabled then separators are put.
+func (f *ExcelFormat) appendAligned(dst []byte, digits []byte, n, k int, ch byte,
+                    g Grouping) []byte {
+    m := len(digits)
+    emit := func(pos int, d byte) {
+        dst = appendRune(dst, f.loc.Digits[d-'0'])
+        if pos>0 && g.Enabled() && g.isBoundary(pos) {
+            dst = appendRune(dst, f.loc.Sep1000)
+        }
+    }
+    if k==0 && m>n {
+        // put digits that exceed placeholders
+        for i := 0; i < m-n; i++ { emit(m-1-i, digits[i]) }
+    }
+    pos := n-1-k
+    switch {
+    case pos<m:
+        emit(pos, digits[m-1-pos])
+    case ch=='0':
+        emit(pos, '0')
+    case ch=='?':
+        dst = append(dst, ' ')
+    }
+    return dst
+}
+
+// append number formatted by format code including locale to dst and return
+// extended buffer. a is magnitude of number and negative is set if number
+// is negative.
+func (f *ExcelFormat) AppendFormat(dst []byte, a UDec128, precision uint,
+                    negative bool) []byte {
+    sec, minus := f.section(a, precision, negative)
+    if minus { dst = appendRune(dst, f.loc.Symbols.minusSign()) }
+    intPart, frac, exp := sec.split(a, precision)
+    var g Grouping
+    if sec.grouping && !sec.hasExp && f.loc.Sep1000!=0 {
+        g = f.loc.Grouping
+        if !g.Enabled() { g = GroupingThousands }
+    }
+    // trailing zeroes of fraction put by '#' and '?'
+    fracEnd := len(frac)
+    fracChars := make([]byte, 0, len(frac))
+    for _, t := range sec.tokens {
+        if t.kind==excelFracDigit { fracChars = append(fracChars, t.ch) }
+    }
+    for fracEnd>0 && frac[fracEnd-1]=='0' && fracChars[fracEnd-1]!='0' { fracEnd-- }
+    var expDigits []byte
+    if sec.hasExp {
+        e := exp
+        if e<0 { e = -e }
+        expDigits = strconv.AppendInt(nil, int64(e), 10)
+        if e==0 { expDigits = expDigits[:0] }
+    }
+    intIdx, fracIdx, expIdx := 0, 0, 0
+    for _, t := range sec.tokens {
+        switch t.kind {
+        case excelLiteral:
+            dst = append(dst, t.text...)
+        case excelIntDigit:
+            dst = f.appendAligned(dst, intPart, sec.nInt, intIdx, t.ch, g)
+            intIdx++
+        case excelFracDigit:
+            switch {
+            case fracIdx<fracEnd:
+                dst = appendRune(dst, f.loc.Digits[frac[fracIdx]-'0'])
+            case t.ch=='?':
+                dst = append(dst, ' ')
+            }
+            fracIdx++
+        case excelPoint:
+            if sec.nInt==0 {
+                for i := range intPart {
+                    dst = f.appendAligned(dst, intPart, len(intPart), i, '#', g)
+                }
+            }
+            dst = appendRune(dst, f.loc.Comma)
+        case excelPercent:
+            dst = appendRune(dst, f.loc.Symbols.styleSign(StylePercent))
+        case excelExponent:
+            dst = append(dst, t.ch)
+            if exp<0 {
+                dst = appendRune(dst, f.loc.Symbols.minusSign())
+            } else if t.plus {
+                dst = appendRune(dst, f.loc.Symbols.plusSign())
+            }
+        case excelExpDigit:
+            dst = f.appendAligned(dst, expDigits, sec.nExp, expIdx, t.ch, GroupingNone)
+            expIdx++
+        case excelGeneral:
+            var buf [64]byte
+            s := a.AppendFormat(buf[:0], precision, precision, true)
+            if len(s)>2 && s[len(s)-2]=='.' && s[len(s)-1]=='0' { s = s[:len(s)-2] }
+            dst = appendLocalized(dst, f.loc, GroupingNone, FracGrouping{}, s)
+        }
+    }
+    return dst
+}
+
+// format number by format code
+func (f *ExcelFormat) Format(a UDec128, precision uint, negative bool) string {
+    var buf [128]byte
+    return string(f.AppendFormat(buf[:0], a, precision, negative))
+}
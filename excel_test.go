@@ -0,0 +1,125 @@
+/*
+ * excel_test.go - tests for Excel (OOXML) number format codes
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "testing"
+)
+
+type UDec128ExcelFormatTC struct {
+    code string
+    lang string
+    value UDec128
+    precision uint
+    negative bool
+    expected string
+    expectedColor string
+}
+
+func TestExcelFormat(t *testing.T) {
+    testCases := []UDec128ExcelFormatTC {
+        UDec128ExcelFormatTC{ `#,##0.00;[Red](#,##0.00);"-"`, "",
+                    UDec128{ 123456789, 0 }, 2, false, "1,234,567.89", "" },
+        UDec128ExcelFormatTC{ `#,##0.00;[Red](#,##0.00);"-"`, "",
+                    UDec128{ 123456789, 0 }, 2, true, "(1,234,567.89)", "Red" },
+        UDec128ExcelFormatTC{ `#,##0.00;[Red](#,##0.00);"-"`, "",
+                    UDec128{ 0, 0 }, 2, false, "-", "" },
+        UDec128ExcelFormatTC{ `#,##0.00;[Red](#,##0.00);"-"`, "de",
+                    UDec128{ 123456789, 0 }, 2, true, "(1.234.567,89)", "Red" },
+        UDec128ExcelFormatTC{ `#,##0.00`, "", UDec128{ 5, 0 }, 3, false, "0.01", "" },
+        UDec128ExcelFormatTC{ `#,##0.00`, "", UDec128{ 5, 0 }, 3, true, "-0.01", "" },
+        UDec128ExcelFormatTC{ `#,##0.00`, "", UDec128{ 9999996, 0 }, 3, false,
+                    "10,000.00", "" },
+        UDec128ExcelFormatTC{ `0.000E+00`, "", UDec128{ 123456, 0 }, 2, false,
+                    "1.235E+03", "" },
+        UDec128ExcelFormatTC{ `0.000E+00`, "", UDec128{ 123, 0 }, 5, false,
+                    "1.230E-03", "" },
+        UDec128ExcelFormatTC{ `0.00E+00`, "", UDec128{ 9996, 0 }, 0, false,
+                    "1.00E+04", "" },
+        UDec128ExcelFormatTC{ `0.00E+00`, "", UDec128{ 0, 0 }, 2, false,
+                    "0.00E+00", "" },
+        UDec128ExcelFormatTC{ `##0.0E+0`, "", UDec128{ 123456, 0 }, 0, false,
+                    "123.5E+3", "" },
+        UDec128ExcelFormatTC{ `##0.0E+0`, "", UDec128{ 12345, 0 }, 0, false,
+                    "12.3E+3", "" },
+        UDec128ExcelFormatTC{ `0%`, "", UDec128{ 1234, 0 }, 4, false, "12%", "" },
+        UDec128ExcelFormatTC{ `0.0%`, "de", UDec128{ 1234, 0 }, 4, false, "12,3%", "" },
+        UDec128ExcelFormatTC{ `#,##0,,"M"`, "", UDec128{ 1234567890, 0 }, 0, false,
+                    "1,235M", "" },
+        UDec128ExcelFormatTC{ `#,##0.0,"K"`, "", UDec128{ 1250, 0 }, 0, false,
+                    "1.3K", "" },
+        UDec128ExcelFormatTC{ `000-00-0000`, "", UDec128{ 123456789, 0 }, 0, false,
+                    "123-45-6789", "" },
+        UDec128ExcelFormatTC{ `00000`, "", UDec128{ 42, 0 }, 0, false, "00042", "" },
+        UDec128ExcelFormatTC{ `#.##`, "", UDec128{ 1250, 0 }, 3, false, "1.25", "" },
+        UDec128ExcelFormatTC{ `#.##`, "", UDec128{ 1000, 0 }, 3, false, "1.", "" },
+        UDec128ExcelFormatTC{ `#.##`, "", UDec128{ 500, 0 }, 3, false, ".5", "" },
+        UDec128ExcelFormatTC{ `0.0??`, "", UDec128{ 1200, 0 }, 3, false, "1.2  ", "" },
+        UDec128ExcelFormatTC{ `???.??`, "", UDec128{ 125, 0 }, 2, false, "  1.25", "" },
+        UDec128ExcelFormatTC{ `\$#,##0.00_);\(\$#,##0.00\)`, "",
+                    UDec128{ 123456, 0 }, 2, false, "$1,234.56 ", "" },
+        UDec128ExcelFormatTC{ `\$#,##0.00_);\(\$#,##0.00\)`, "",
+                    UDec128{ 123456, 0 }, 2, true, "($1,234.56)", "" },
+        UDec128ExcelFormatTC{ `[$€-407] #,##0.00`, "de", UDec128{ 123456, 0 }, 2, false,
+                    "€ 1.234,56", "" },
+        UDec128ExcelFormatTC{ `[Blue][>=1000]#,##0;[Red][<0]0.0;0.00`, "",
+                    UDec128{ 12345, 0 }, 1, false, "1,235", "Blue" },
+        UDec128ExcelFormatTC{ `[Blue][>=1000]#,##0;[Red][<0]0.0;0.00`, "",
+                    UDec128{ 12345, 0 }, 1, true, "1234.5", "Red" },
+        UDec128ExcelFormatTC{ `[Blue][>=1000]#,##0;[Red][<0]0.0;0.00`, "",
+                    UDec128{ 125, 0 }, 1, false, "12.50", "" },
+        UDec128ExcelFormatTC{ `[>100]"big";[<=-100]"small";0`, "",
+                    UDec128{ 5, 0 }, 0, true, "-5", "" },
+        UDec128ExcelFormatTC{ `General`, "", UDec128{ 12345, 0 }, 2, false,
+                    "123.45", "" },
+        UDec128ExcelFormatTC{ `General`, "de", UDec128{ 12300, 0 }, 2, true,
+                    "-123", "" },
+        UDec128ExcelFormatTC{ `0;-0;0;@`, "", UDec128{ 7, 0 }, 0, true, "-7", "" },
+        UDec128ExcelFormatTC{ `[Color10]0`, "", UDec128{ 7, 0 }, 0, false, "7", "Color10" },
+    }
+    for i, tc := range testCases {
+        f, err := CompileExcelFormat(tc.code, tc.lang)
+        if err!=nil {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+            continue
+        }
+        result := f.Format(tc.value, tc.precision, tc.negative)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+        color := f.Color(tc.value, tc.precision, tc.negative)
+        if tc.expectedColor!=color {
+            t.Errorf("Color mismatch: %d: %v: %v->%v", i, tc, tc.expectedColor, color)
+        }
+    }
+}
+
+func TestExcelFormatInvalid(t *testing.T) {
+    testCases := []string{ `0.00"abc`, `[Red0`, `[Purple]0`, `[Color99]0`, `0.0.0`,
+            `0E+`, `E+00`, `0;0;0;@;0`, `abc0`, `0\`, `0 ?/?`, `# ??/??`, `0/#` }
+    for i, tc := range testCases {
+        if _, err := CompileExcelFormat(tc, ""); err!=ErrInvalidFormatCode {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+        }
+    }
+}
@@ -0,0 +1,189 @@
+/*
+ * exponent.go - exponent notation with locale
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "unicode/utf8"
+)
+
+var superscriptDigits = [10]rune{ '⁰', '¹', '²', '³', '⁴', '⁵', '⁶', '⁷', '⁸', '⁹' }
+
+const (
+    superscriptMinus rune = '⁻'
+    superscriptPlus rune = '⁺'
+    // maximal number of digits of exponent
+    maxExponentDigits = 3
+)
+
+func (ls *LocSymbols) exponential() string {
+    if ls.Exponential=="" { return "E" }
+    return ls.Exponential
+}
+
+func (ls *LocSymbols) superscriptingExponent() rune {
+    if ls.SuperscriptingExponent==0 { return '×' }
+    return ls.SuperscriptingExponent
+}
+
+// return value of superscript digit or -1 if rune is not superscript digit
+func superscriptDigitValue(r rune) int {
+    for i, d := range superscriptDigits {
+        if r==d { return i }
+    }
+    return -1
+}
+
+// return value of ASCII digit or digit of locale or -1 if rune is not digit
+func localeDigitValue(l *LocFmt, r rune) int {
+    if r>='0' && r<='9' { return int(r-'0') }
+    for i, d := range l.Digits {
+        if r==d { return i }
+    }
+    return -1
+}
+
+// append number in scientific notation including locale to dst.
+// exp is decimal exponent of first digit
+func appendLocaleSci(dst []byte, l *LocFmt, digits []byte, exp int,
+                    superscript bool) []byte {
+    var buf [64]byte
+    s := append(buf[:0], digits[0])
+    if len(digits)>1 {
+        s = append(s, '.')
+        s = append(s, digits[1:]...)
+    }
+    dst = appendLocalized(dst, l, GroupingNone, FracGrouping{}, s)
+    if superscript {
+        dst = appendRune(dst, l.Symbols.superscriptingExponent())
+        dst = appendRune(dst, l.Digits[1])
+        dst = appendRune(dst, l.Digits[0])
+        if exp<0 { dst = appendRune(dst, superscriptMinus) }
+    } else {
+        dst = append(dst, l.Symbols.exponential()...)
+        if exp<0 { dst = appendRune(dst, l.Symbols.minusSign()) }
+    }
+    if exp<0 { exp = -exp }
+    var ebuf [8]byte
+    es := ebuf[:0]
+    for ; exp>=10; exp /= 10 { es = append(es, byte(exp%10)) }
+    es = append(es, byte(exp))
+    for i := len(es)-1; i>=0; i-- {
+        if superscript {
+            dst = appendRune(dst, superscriptDigits[es[i]])
+        } else {
+            dst = appendRune(dst, l.Digits[es[i]])
+        }
+    }
+    return dst
+}
+
+// append number with sign in scientific notation including locale to dst
+// and return extended buffer. sigDigits is number of significant digits
+// (if zero then all significant digits without trailing zeroes are put).
+// If superscript is set then exponent is formatted with superscript digits
+// (like '1,5×10³'), otherwise exponential symbol of locale is used (like '1,5E3').
+func (a UDec128) AppendLocaleFormatSci(dst []byte, lang string, precision uint,
+                    sigDigits int, negative, superscript bool) []byte {
+    l := getLocFmt(lang)
+    var sign rune
+    if negative { sign = l.Symbols.minusSign() }
+    dst = l.Symbols.appendPrefix(dst, sign, StyleDecimal, false)
+    digits, exp := sciDigitsRounded(a, precision, sigDigits)
+    dst = appendLocaleSci(dst, l, digits, exp, superscript)
+    return l.Symbols.appendSuffix(dst, sign, StyleDecimal, false)
+}
+
+// format number with sign in scientific notation including locale
+func (a UDec128) LocaleFormatSci(lang string, precision uint, sigDigits int,
+                    negative, superscript bool) string {
+    var buf [128]byte
+    return string(a.AppendLocaleFormatSci(buf[:0], lang, precision, sigDigits,
+                                          negative, superscript))
+}
+
+// find exponent at end of number. Exponent can be given with exponential
+// symbol of locale, 'E', 'e', '×10^' or with superscript digits after '×10'.
+// Return length of mantissa, exponent and true if exponent has been found.
+func localeExponent(l *LocFmt, str []byte) (int, int, bool) {
+    end := len(str)
+    r, size := utf8.DecodeLastRune(str[:end])
+    if superscriptDigitValue(r)>=0 {
+        // superscript exponent: '×10³'
+        exp, mul, n := 0, 1, 0
+        for ; end>0; n++ {
+            r, size = utf8.DecodeLastRune(str[:end])
+            d := superscriptDigitValue(r)
+            if d<0 { break }
+            exp += d*mul
+            mul *= 10
+            end -= size
+        }
+        if n>maxExponentDigits { return len(str), 0, false }
+        r, size = utf8.DecodeLastRune(str[:end])
+        if r==superscriptMinus || r==superscriptPlus {
+            if r==superscriptMinus { exp = -exp }
+            end -= size
+        }
+        // power of ten
+        for _, d := range [2]int{ 0, 1 } {
+            r, size = utf8.DecodeLastRune(str[:end])
+            if localeDigitValue(l, r)!=d { return len(str), 0, false }
+            end -= size
+        }
+        r, size = utf8.DecodeLastRune(str[:end])
+        if r!=l.Symbols.superscriptingExponent() && r!='×' && r!='·' {
+            return len(str), 0, false
+        }
+        end -= size
+        if end==0 { return len(str), 0, false }
+        return end, exp, true
+    }
+    exp, mul, n := 0, 1, 0
+    for ; end>0; n++ {
+        r, size = utf8.DecodeLastRune(str[:end])
+        d := localeDigitValue(l, r)
+        if d<0 { break }
+        exp += d*mul
+        mul *= 10
+        end -= size
+    }
+    if n==0 || n>maxExponentDigits { return len(str), 0, false }
+    r, size = utf8.DecodeLastRune(str[:end])
+    ls := &l.Symbols
+    switch r {
+    case ls.minusSign(), '-', '−':
+        exp = -exp
+        end -= size
+    case ls.plusSign(), '+':
+        end -= size
+    }
+    for _, marker := range [...]string{ ls.exponential(), "E", "e", "×10^" } {
+        if bytes.HasSuffix(str[:end], []byte(marker)) {
+            end -= len(marker)
+            if end==0 { return len(str), 0, false }
+            return end, exp, true
+        }
+    }
+    return len(str), 0, false
+}
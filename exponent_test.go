@@ -0,0 +1,178 @@
+/*
+ * exponent_test.go - tests for exponent notation with locale
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "strings"
+    "testing"
+)
+
+type UDec128LocFmtSciTC struct {
+    lang string
+    value UDec128
+    precision uint
+    sigDigits int
+    negative, superscript bool
+    expected string
+}
+
+func TestUDec128LocaleFormatSci(t *testing.T) {
+    testCases := []UDec128LocFmtSciTC {
+        UDec128LocFmtSciTC{ "de", UDec128{ 15000, 0 }, 1, 0, false, false, "1,5E3" },
+        UDec128LocFmtSciTC{ "de", UDec128{ 15000, 0 }, 1, 0, false, true, "1,5×10³" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 15, 0 }, 4, 0, false, false, "1.5E-3" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 15, 0 }, 4, 0, false, true, "1.5×10⁻³" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 15, 0 }, 4, 3, false, false, "1.50E-3" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 1999, 0 }, 0, 2, true, false, "-2.0E3" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 7, 0 }, 0, 0, false, false, "7E0" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 0, 0 }, 2, 0, false, false, "0E0" },
+        UDec128LocFmtSciTC{ "en", UDec128{ 0, 1 }, 0, 3, false, true,
+                    "1.84×10¹⁹" },
+        UDec128LocFmtSciTC{ "sv", UDec128{ 12345, 0 }, 0, 0, true, false, "−1,2345×10^4" },
+        UDec128LocFmtSciTC{ "fi", UDec128{ 12, 0 }, 4, 0, true, false, "−1,2E−3" },
+        UDec128LocFmtSciTC{ "ar", UDec128{ 15, 0 }, 0, 0, false, false, "١٫٥أس١" },
+        UDec128LocFmtSciTC{ "ar", UDec128{ 15, 0 }, 0, 0, false, true, "١٫٥×١٠¹" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.LocaleFormatSci(tc.lang, tc.precision, tc.sigDigits,
+                                           tc.negative, tc.superscript)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v)->%q!=%q",
+                     i, tc.value, tc.expected, result)
+        }
+    }
+}
+
+type UDec128LocParseExpTC struct {
+    lang string
+    str string
+    precision uint
+    expected UDec128
+    negative bool
+    err error
+}
+
+func TestLocaleParseUDec128Exponent(t *testing.T) {
+    testCases := []UDec128LocParseExpTC {
+        UDec128LocParseExpTC{ "de", "1,5E3", 1, UDec128{ 15000, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5e3", 1, UDec128{ 15000, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5E+3", 1, UDec128{ 15000, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1.234,5E-2", 4, UDec128{ 123450, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5×10³", 1, UDec128{ 15000, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5×10⁻³", 4, UDec128{ 15, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5·10¹²", 0, UDec128{ 1500000000000, 0 },
+                    false, nil },
+        UDec128LocParseExpTC{ "de", "1,5×10^2", 0, UDec128{ 150, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "-1,5E3", 0, UDec128{ 1500, 0 }, true, nil },
+        UDec128LocParseExpTC{ "sv", "−1,5×10^−2", 3, UDec128{ 15, 0 }, true, nil },
+        UDec128LocParseExpTC{ "ar", "١٫٥أس٣", 0, UDec128{ 1500, 0 }, false, nil },
+        UDec128LocParseExpTC{ "ar", "١٫٥×١٠³", 0, UDec128{ 1500, 0 }, false, nil },
+        UDec128LocParseExpTC{ "de", "1,5E", 1, UDec128{}, false, strconv.ErrSyntax },
+        UDec128LocParseExpTC{ "de", "E3", 1, UDec128{}, false, strconv.ErrSyntax },
+        UDec128LocParseExpTC{ "de", "1,5E1234", 1, UDec128{}, false, strconv.ErrSyntax },
+        UDec128LocParseExpTC{ "de", "1,5×10", 1, UDec128{}, false, strconv.ErrSyntax },
+        UDec128LocParseExpTC{ "de", "1,5×2³", 1, UDec128{}, false, strconv.ErrSyntax },
+        UDec128LocParseExpTC{ "de", "1,5E3E2", 1, UDec128{}, false, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, negative, err := LocaleParseUDec128Style(tc.lang, tc.str,
+                                        tc.precision, false, StyleDecimal)
+        if tc.expected!=result || tc.negative!=negative || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v,%v!=%v,%v,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.err,
+                     result, negative, err)
+        }
+        result, negative, err = LocaleParseUDec128StyleBytes(tc.lang, []byte(tc.str),
+                                        tc.precision, false, StyleDecimal)
+        if tc.expected!=result || tc.negative!=negative || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parseBytes(%q)->%v,%v,%v!=%v,%v,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.err,
+                     result, negative, err)
+        }
+    }
+    // unsigned and strict parsing
+    if v, err := LocaleParseUDec128("en", "2.5E2", 0, false);
+            v!=(UDec128{ 250, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: unsigned: %v,%v", v, err)
+    }
+    if v, err := LocaleParseUDec128Strict("en", "1,234.5E1", 0, false);
+            v!=(UDec128{ 12345, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: strict: %v,%v", v, err)
+    }
+}
+
+func TestFormatterScientific(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Lang: "de", Precision: 2, Scientific: true,
+                SigDigits: 3 })
+    if s := f.Format(UDec128{ 123456, 0 }); s!="1,23E3" {
+        t.Errorf("Result mismatch: de: %v", s)
+    }
+    if s := f.FormatSigned(UDec128{ 123456, 0 }, true); s!="-1,23E3" {
+        t.Errorf("Result mismatch: de signed: %v", s)
+    }
+    f = NewFormatter(FormatterOptions{ Precision: 2, Scientific: true,
+                SuperscriptExponent: true })
+    if s := f.Format(UDec128{ 123456, 0 }); s!="1.23456×10³" {
+        t.Errorf("Result mismatch: plain: %v", s)
+    }
+    f = NewFormatter(FormatterOptions{ Lang: "en", Precision: 4, Scientific: true,
+                Style: StylePercent })
+    if s := f.Format(UDec128{ 1250, 0 }); s!="1.25E1%" {
+        t.Errorf("Result mismatch: percent: %v", s)
+    }
+}
+
+const cldrExponentTestData = `{
+  "main": {
+    "xx-EXP": {
+      "identity": { "language": "xx", "territory": "EXP" },
+      "numbers": {
+        "defaultNumberingSystem": "latn",
+        "symbols-numberSystem-latn": { "decimal": ",", "group": ".",
+            "exponential": "\u200e×10^", "superscriptingExponent": "·" },
+        "decimalFormats-numberSystem-latn": { "standard": "#,##0.###" }
+      }
+    }
+  }
+}`
+
+func TestLoadCLDRExponent(t *testing.T) {
+    names, err := LoadCLDR(strings.NewReader(cldrExponentTestData))
+    if err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer func() {
+        for _, lang := range names { UnregisterLocale(lang) }
+    }()
+    a := UDec128{ 25, 0 }
+    if s := a.LocaleFormatSci("xx-EXP", 0, 0, false, false); s!="2,5×10^1" {
+        t.Errorf("Result mismatch: exponential: %v", s)
+    }
+    if s := a.LocaleFormatSci("xx-EXP", 0, 0, false, true); s!="2,5·10¹" {
+        t.Errorf("Result mismatch: superscript: %v", s)
+    }
+    if v, err := LocaleParseUDec128("xx-EXP", "2,5·10¹", 0, false);
+            v!=a || err!=nil {
+        t.Errorf("Result mismatch: parse: %v,%v", v, err)
+    }
+}
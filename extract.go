@@ -0,0 +1,254 @@
+/*
+ * extract.go - extracting amounts from free text
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "io"
+    "io/ioutil"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// amount found in text
+type TextAmount struct {
+    // span of amount in bytes (including sign and currency)
+    Start, End int
+    Value UDec128
+    Negative bool
+    // currency token as found in text (like '€' or 'EUR') and ISO 4217 code
+    // of currency. Both are empty if no currency around amount. Code is
+    // empty if token is ambiguous (like 'kr' without Scandinavian locale).
+    Currency, CurrencyCode string
+    // language of locale used to parse amount
+    Lang string
+}
+
+// scanner of amounts in text for candidate locales
+type amountScanner struct {
+    langs []string
+    locs []*LocFmt
+    tokens [][]currencyToken
+}
+
+func newAmountScanner(langs []string) *amountScanner {
+    if len(langs)==0 { langs = []string{ "en" } }
+    sc := &amountScanner{ langs: langs }
+    for _, lang := range langs {
+        sc.locs = append(sc.locs, getLocFmt(lang))
+        sc.tokens = append(sc.tokens, currencyTokens(lang))
+    }
+    return sc
+}
+
+// return true if rune is digit of any candidate locale
+func (sc *amountScanner) isDigit(r rune) bool {
+    if r>='0' && r<='9' { return true }
+    for _, l := range sc.locs {
+        for _, d := range l.Digits {
+            if d==r { return true }
+        }
+    }
+    return false
+}
+
+// return true if rune is comma or separator of any candidate locale
+func (sc *amountScanner) isSep(r rune) bool {
+    for _, l := range sc.locs {
+        if r==l.Comma || r==l.Sep1000 || (r==l.Sep1000_2 && r!=0) { return true }
+    }
+    return false
+}
+
+// return end of number that starts at start: digits and separators
+// between digits
+func (sc *amountScanner) numberEnd(str string, start int) int {
+    end := start
+    for i := start; i < len(str); {
+        r, size := utf8.DecodeRuneInString(str[i:])
+        if sc.isDigit(r) {
+            i += size
+            end = i
+            continue
+        }
+        if i==end && sc.isSep(r) {
+            if next, _ := utf8.DecodeRuneInString(str[i+size:]); sc.isDigit(next) {
+                i += size
+                continue
+            }
+        }
+        break
+    }
+    return end
+}
+
+// return true if rune is letter or digit (amount can not be adjacent to it)
+func isWordRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// return start of minus sign before pos or -1 if no sign
+func minusBefore(l *LocFmt, str string, pos int) int {
+    r, size := utf8.DecodeLastRuneInString(str[:pos])
+    if r!='-' && r!='−' && r!=l.Symbols.minusSign() { return -1 }
+    if prev, _ := utf8.DecodeLastRuneInString(str[:pos-size]); isWordRune(prev) {
+        return -1
+    }
+    return pos-size
+}
+
+// find longest currency token after pos (after spaces).
+// Return index of token and end of token or -1 if not found.
+func findTokenAfter(tokens []currencyToken, str string, pos int) (int, int) {
+    p := pos+len(str[pos:])-len(strings.TrimLeftFunc(str[pos:], isSpaceRune))
+    best := -1
+    for i, t := range tokens {
+        if !strings.HasPrefix(str[p:], t.token) { continue }
+        if next, _ := utf8.DecodeRuneInString(str[p+len(t.token):]); isWordRune(next) {
+            continue
+        }
+        if best==-1 || len(t.token)>len(tokens[best].token) { best = i }
+    }
+    if best==-1 { return -1, 0 }
+    return best, p+len(tokens[best].token)
+}
+
+// find longest currency token before pos (before spaces).
+// Return index of token and start of token or -1 if not found.
+func findTokenBefore(tokens []currencyToken, str string, pos int) (int, int) {
+    p := len(strings.TrimRightFunc(str[:pos], isSpaceRune))
+    best := -1
+    for i, t := range tokens {
+        if !strings.HasSuffix(str[:p], t.token) { continue }
+        prev, _ := utf8.DecodeLastRuneInString(str[:p-len(t.token)])
+        if isWordRune(prev) { continue }
+        if best==-1 || len(t.token)>len(tokens[best].token) { best = i }
+    }
+    if best==-1 { return -1, 0 }
+    return best, p-len(tokens[best].token)
+}
+
+// parse number between start and end by first candidate locale that accepts
+// it (with checking of separators) and find sign and currency around it
+func (sc *amountScanner) amount(str string, start, end int, precision uint,
+                    rounding bool) (TextAmount, bool) {
+    for k, l := range sc.locs {
+        v, negative, err := localeParseUDec128Style(l, l.Grouping, l.FracGrouping,
+                    str[start:end], precision, rounding, StyleDecimal, true)
+        if err!=nil || negative { continue }
+        a := TextAmount{ Start: start, End: end, Value: v, Lang: sc.langs[k] }
+        tokens := sc.tokens[k]
+        // currency after number
+        if t, tend := findTokenAfter(tokens, str, end); t!=-1 {
+            a.Currency, a.CurrencyCode = tokens[t].token, tokens[t].code
+            a.End = tend
+        } else if next, _ := utf8.DecodeRuneInString(str[end:]); unicode.IsLetter(next) {
+            return TextAmount{}, false
+        }
+        // sign and currency before number
+        if s := minusBefore(l, str, a.Start); s!=-1 {
+            a.Negative = true
+            a.Start = s
+        }
+        if a.Currency=="" {
+            if t, tstart := findTokenBefore(tokens, str, a.Start); t!=-1 {
+                a.Currency, a.CurrencyCode = tokens[t].token, tokens[t].code
+                a.Start = tstart
+                if s := minusBefore(l, str, a.Start); s!=-1 && !a.Negative {
+                    a.Negative = true
+                    a.Start = s
+                }
+            }
+        }
+        if a.Currency=="" {
+            if prev, _ := utf8.DecodeLastRuneInString(str[:a.Start]); unicode.IsLetter(prev) {
+                return TextAmount{}, false
+            }
+        }
+        return a, true
+    }
+    return TextAmount{}, false
+}
+
+// append amounts found in number between start and end. If no locale
+// accepts number then it is split at spaces (like '3 4').
+func (sc *amountScanner) appendAmounts(out []TextAmount, str string, start, end int,
+                    precision uint, rounding bool) []TextAmount {
+    if a, ok := sc.amount(str, start, end, precision, rounding); ok {
+        return append(out, a)
+    }
+    if strings.IndexFunc(str[start:end], isSpaceRune)==-1 { return out }
+    for i := start; i < end; {
+        r, size := utf8.DecodeRuneInString(str[i:])
+        if isSpaceRune(r) {
+            i += size
+            continue
+        }
+        partEnd := end
+        if n := strings.IndexFunc(str[i:end], isSpaceRune); n!=-1 { partEnd = i+n }
+        if a, ok := sc.amount(str, i, partEnd, precision, rounding); ok {
+            out = append(out, a)
+        }
+        i = partEnd
+    }
+    return out
+}
+
+func (sc *amountScanner) find(str string, precision uint,
+                    rounding bool) []TextAmount {
+    var out []TextAmount
+    for i := 0; i < len(str); {
+        r, size := utf8.DecodeRuneInString(str[i:])
+        if !sc.isDigit(r) {
+            i += size
+            continue
+        }
+        end := sc.numberEnd(str, i)
+        out = sc.appendAmounts(out, str, i, end, precision, rounding)
+        i = end
+    }
+    return out
+}
+
+// find all amounts in text (like 'paid 1.234,50 EUR on ...') under candidate
+// locales. For every number first locale that accepts its digits and
+// separators is used. Sign and currency symbol or code around number are
+// recognized. If langs is empty then English locale is used.
+func FindAmounts(langs []string, str string, precision uint,
+                    rounding bool) []TextAmount {
+    return newAmountScanner(langs).find(str, precision, rounding)
+}
+
+// find all amounts in text given as bytes
+func FindAmountsBytes(langs []string, str []byte, precision uint,
+                    rounding bool) []TextAmount {
+    return FindAmounts(langs, string(str), precision, rounding)
+}
+
+// find all amounts in text read from reader. Spans are offsets in input.
+func ScanAmounts(langs []string, r io.Reader, precision uint,
+                    rounding bool) ([]TextAmount, error) {
+    data, err := ioutil.ReadAll(r)
+    if err!=nil { return nil, err }
+    return FindAmountsBytes(langs, data, precision, rounding), nil
+}
@@ -0,0 +1,82 @@
+/*
+ * extract_test.go - tests for extracting amounts from free text
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+type FindAmountsTC struct {
+    langs []string
+    str string
+    expected []TextAmount
+}
+
+func TestFindAmounts(t *testing.T) {
+    testCases := []FindAmountsTC {
+        FindAmountsTC{ []string{ "de" }, "paid 1.234,50 EUR on 2020-05-01",
+            []TextAmount{
+                TextAmount{ 5, 17, UDec128{ 123450, 0 }, false, "EUR", "EUR", "de" },
+                TextAmount{ 21, 25, UDec128{ 202000, 0 }, false, "", "", "de" },
+                TextAmount{ 26, 28, UDec128{ 500, 0 }, false, "", "", "de" },
+                TextAmount{ 29, 31, UDec128{ 100, 0 }, false, "", "", "de" } } },
+        FindAmountsTC{ []string{ "en", "de" }, "Refund of $1,234.56 and 1.234,50 €.",
+            []TextAmount{
+                TextAmount{ 10, 19, UDec128{ 123456, 0 }, false, "$", "USD", "en" },
+                TextAmount{ 24, 36, UDec128{ 123450, 0 }, false, "€", "EUR", "de" } } },
+        FindAmountsTC{ []string{ "en" }, "balance: -$12.50, fee -3.10 USD",
+            []TextAmount{
+                TextAmount{ 9, 16, UDec128{ 1250, 0 }, true, "$", "USD", "en" },
+                TextAmount{ 22, 31, UDec128{ 310, 0 }, true, "USD", "USD", "en" } } },
+        FindAmountsTC{ []string{ "fr" }, "total 1\u00a0234,5\u00a0€ ou 3 4",
+            []TextAmount{
+                TextAmount{ 6, 19, UDec128{ 123450, 0 }, false, "€", "EUR", "fr" },
+                TextAmount{ 23, 24, UDec128{ 300, 0 }, false, "", "", "fr" },
+                TextAmount{ 25, 26, UDec128{ 400, 0 }, false, "", "", "fr" } } },
+        FindAmountsTC{ []string{ "en" }, "order ID123 has 3rd item of 10kg for 7.5.",
+            []TextAmount{
+                TextAmount{ 37, 40, UDec128{ 750, 0 }, false, "", "", "en" } } },
+        FindAmountsTC{ []string{ "en" }, "EUR100 and 100EUR",
+            []TextAmount{
+                TextAmount{ 0, 6, UDec128{ 10000, 0 }, false, "EUR", "EUR", "en" },
+                TextAmount{ 11, 17, UDec128{ 10000, 0 }, false, "EUR", "EUR", "en" } } },
+        FindAmountsTC{ nil, "no amounts here", nil },
+    }
+    for i, tc := range testCases {
+        result := FindAmounts(tc.langs, tc.str, 2, false)
+        if !reflect.DeepEqual(tc.expected, result) {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+        resultBytes := FindAmountsBytes(tc.langs, []byte(tc.str), 2, false)
+        if !reflect.DeepEqual(tc.expected, resultBytes) {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, resultBytes)
+        }
+        resultScan, err := ScanAmounts(tc.langs, strings.NewReader(tc.str), 2, false)
+        if err!=nil || !reflect.DeepEqual(tc.expected, resultScan) {
+            t.Errorf("Result mismatch: %d: %v: %v->%v,%v", i, tc, tc.expected,
+                     resultScan, err)
+        }
+    }
+}
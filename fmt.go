@@ -0,0 +1,289 @@
+/*
+ * fmt.go - integration with fmt package
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "github.com/matszpk/goint128"
+)
+
+// 128-bit decimal fixed point with its precision. Implements fmt.Formatter,
+// fmt.Stringer and fmt.Scanner, therefore can be used directly with fmt package.
+// Before scanning Precision must be set.
+type UDec128Fmt struct {
+    Value UDec128
+    Precision uint
+}
+
+// return decimal fixed point with precision for fmt package
+func (a UDec128) Fmt(precision uint) UDec128Fmt {
+    return UDec128Fmt{ a, precision }
+}
+
+// return digits of 128-bit decimal fixed point as integer (without comma)
+func udec128Digits(a UDec128) []byte {
+    var buf [40]byte
+    return append([]byte(nil), uint128DigitsBuf(&buf, goint128.UInt128(a))...)
+}
+
+// round digits (half up) to keep first digits. return rounded digits and
+// true if carry goes beyond first digit (then digits are 1000...).
+func roundDigitsHalfUp(digits []byte, keep int) ([]byte, bool) {
+    if keep>=len(digits) { return digits, false }
+    if keep<0 { return digits[:0], false }
+    up := digits[keep]>='5'
+    digits = digits[:keep]
+    if !up { return digits, false }
+    i := keep-1
+    for ; i>=0 && digits[i]=='9'; i-- {
+        digits[i] = '0'
+    }
+    if i>=0 {
+        digits[i]++
+        return digits, false
+    }
+    // carry beyond first digit
+    return append([]byte{'1'}, digits...), true
+}
+
+// split to integer part and fraction part with displayPrecision digits
+// with rounding half up
+func splitFixedRounded(a UDec128, precision, displayPrecision uint) ([]byte, []byte) {
+    digits := udec128Digits(a)
+    if len(digits)<=int(precision) {
+        // add leading zeroes
+        d := make([]byte, int(precision)+1, int(precision)+1+int(displayPrecision))
+        l := len(d)-len(digits)
+        for i := 0; i < l; i++ { d[i] = '0' }
+        copy(d[l:], digits)
+        digits = d
+    }
+    intLen := len(digits)-int(precision)
+    if displayPrecision<precision {
+        var carry bool
+        digits, carry = roundDigitsHalfUp(digits, intLen+int(displayPrecision))
+        if carry { intLen++ }
+    } else {
+        for i := precision; i < displayPrecision; i++ {
+            digits = append(digits, '0')
+        }
+    }
+    return digits[:intLen], digits[intLen:]
+}
+
+// return significant digits and decimal exponent (of first digit)
+// if sigDigits<=0 then all significant digits without trailing zeroes are returned
+func sciDigitsRounded(a UDec128, precision uint, sigDigits int) ([]byte, int) {
+    if a.IsZero() {
+        d := []byte{'0'}
+        for i := 1; i < sigDigits; i++ { d = append(d, '0') }
+        return d, 0
+    }
+    digits := udec128Digits(a)
+    exp := len(digits)-1-int(precision)
+    if sigDigits<=0 {
+        i := len(digits)
+        for ; i>1 && digits[i-1]=='0'; i-- { }
+        return digits[:i], exp
+    }
+    if len(digits)>sigDigits {
+        var carry bool
+        digits, carry = roundDigitsHalfUp(digits, sigDigits)
+        if carry {
+            digits = digits[:sigDigits]
+            exp++
+        }
+    } else {
+        for len(digits)<sigDigits { digits = append(digits, '0') }
+    }
+    return digits, exp
+}
+
+func appendFmtExp(os []byte, exp int, expChar byte) []byte {
+    os = append(os, expChar)
+    if exp<0 {
+        os = append(os, '-')
+        exp = -exp
+    } else {
+        os = append(os, '+')
+    }
+    if exp<10 { os = append(os, '0') }
+    return strconv.AppendInt(os, int64(exp), 10)
+}
+
+func appendFmtSci(os []byte, digits []byte, exp int, expChar byte) []byte {
+    os = append(os, digits[0])
+    if len(digits)>1 {
+        os = append(os, '.')
+        os = append(os, digits[1:]...)
+    }
+    return appendFmtExp(os, exp, expChar)
+}
+
+func appendFmtFixed(os, intPart, frac []byte) []byte {
+    os = append(os, intPart...)
+    if len(frac)!=0 {
+        os = append(os, '.')
+        os = append(os, frac...)
+    }
+    return os
+}
+
+// format in %g style: sigDigits<=0 means shortest representation
+func appendFmtGeneral(os []byte, a UDec128Fmt, sigDigits int, expChar byte,
+                      keepZeroes bool) []byte {
+    shortest := sigDigits<=0
+    digits, exp := sciDigitsRounded(a.Value, a.Precision, sigDigits)
+    eprec := sigDigits
+    if shortest {
+        eprec = 6
+        if len(digits)>eprec { eprec = len(digits) }
+    }
+    if !keepZeroes {
+        i := len(digits)
+        for ; i>1 && digits[i-1]=='0'; i-- { }
+        digits = digits[:i]
+    }
+    if exp < -4 || exp >= eprec {
+        return appendFmtSci(os, digits, exp, expChar)
+    }
+    // fixed notation
+    if exp<0 {
+        os = append(os, '0', '.')
+        for i := exp+1; i < 0; i++ { os = append(os, '0') }
+        return append(os, digits...)
+    }
+    if len(digits)<=exp+1 {
+        os = append(os, digits...)
+        for i := len(digits); i < exp+1; i++ { os = append(os, '0') }
+        return os
+    }
+    return appendFmtFixed(os, digits[:exp+1], digits[exp+1:])
+}
+
+// format decimal fixed point in default form
+func (a UDec128Fmt) String() string {
+    return a.Value.Format(a.Precision, false)
+}
+
+// implementation of fmt.Formatter. Supported verbs: 'v', 's' (default form,
+// or fixed form if precision is given), 'd' (rounded integer part), 'f', 'F'
+// (fixed form, default precision is precision of value), 'e', 'E' (scientific form,
+// without precision all significant digits are printed), 'g', 'G' (general form).
+// Supported flags: '+', ' ', '-', '0' and width. Rounding is half up.
+func (a UDec128Fmt) Format(s fmt.State, verb rune) {
+    prec, hasPrec := s.Precision()
+    var body []byte
+    switch verb {
+    case 'v', 's':
+        if hasPrec {
+            intPart, frac := splitFixedRounded(a.Value, a.Precision, uint(prec))
+            body = appendFmtFixed(body, intPart, frac)
+        } else {
+            body = a.Value.FormatBytes(a.Precision, false)
+        }
+    case 'd':
+        intPart, _ := splitFixedRounded(a.Value, a.Precision, 0)
+        body = intPart
+    case 'f', 'F':
+        if !hasPrec { prec = int(a.Precision) }
+        intPart, frac := splitFixedRounded(a.Value, a.Precision, uint(prec))
+        body = appendFmtFixed(body, intPart, frac)
+    case 'e', 'E':
+        sigDigits := 0
+        if hasPrec { sigDigits = prec+1 }
+        digits, exp := sciDigitsRounded(a.Value, a.Precision, sigDigits)
+        body = appendFmtSci(body, digits, exp, byte(verb))
+    case 'g', 'G':
+        expChar := byte('e')
+        if verb=='G' { expChar = 'E' }
+        if !hasPrec {
+            prec = -1
+        } else if prec==0 {
+            prec = 1
+        }
+        body = appendFmtGeneral(body, a, prec, expChar, s.Flag('#'))
+    default:
+        fmt.Fprintf(s, "%%!%c(godec128.UDec128Fmt=%s)", verb, a.String())
+        return
+    }
+    var sign []byte
+    if s.Flag('+') {
+        sign = []byte{'+'}
+    } else if s.Flag(' ') {
+        sign = []byte{' '}
+    }
+    width, hasWidth := s.Width()
+    padLen := 0
+    if hasWidth { padLen = width-len(sign)-len(body) }
+    if padLen<=0 {
+        s.Write(sign)
+        s.Write(body)
+        return
+    }
+    pad := make([]byte, padLen)
+    fill := byte(' ')
+    if s.Flag('0') && !s.Flag('-') { fill = '0' }
+    for i := range pad { pad[i] = fill }
+    switch {
+    case s.Flag('-'):
+        s.Write(sign)
+        s.Write(body)
+        s.Write(pad)
+    case fill=='0':
+        s.Write(sign)
+        s.Write(pad)
+        s.Write(body)
+    default:
+        s.Write(pad)
+        s.Write(sign)
+        s.Write(body)
+    }
+}
+
+var errScanVerb = errors.New("godec128: invalid verb for scanning")
+
+func isScanDecChar(r rune) bool {
+    return (r>='0' && r<='9') || r=='.' || r=='e' || r=='E' || r=='+' || r=='-'
+}
+
+// implementation of fmt.Scanner. Value is parsed with precision given
+// in Precision field with rounding.
+func (a *UDec128Fmt) Scan(state fmt.ScanState, verb rune) error {
+    switch verb {
+    case 'v', 's', 'd', 'f', 'F', 'e', 'E', 'g', 'G':
+    default:
+        return errScanVerb
+    }
+    state.SkipSpace()
+    tok, err := state.Token(false, isScanDecChar)
+    if err!=nil { return err }
+    if len(tok)!=0 && tok[0]=='+' { tok = tok[1:] }
+    if len(tok)==0 { return strconv.ErrSyntax }
+    v, err := ParseUDec128Bytes(tok, a.Precision, true)
+    if err!=nil { return err }
+    a.Value = v
+    return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * fmt_test.go - tests for integration with fmt package
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "fmt"
+    "strconv"
+    "testing"
+)
+
+type UDec128FmtPkgTC struct {
+    format string
+    a UDec128
+    precision uint
+    expected string
+}
+
+func TestUDec128FmtFormat(t *testing.T) {
+    testCases := []UDec128FmtPkgTC {
+        UDec128FmtPkgTC{ "%v", UDec128{ 1234567, 0 }, 3, "1234.567" },
+        UDec128FmtPkgTC{ "%s", UDec128{ 1234567, 0 }, 3, "1234.567" },
+        UDec128FmtPkgTC{ "%v", UDec128{ 0, 0 }, 3, "0.0" },
+        UDec128FmtPkgTC{ "%.2v", UDec128{ 1234567, 0 }, 3, "1234.57" },
+        UDec128FmtPkgTC{ "%d", UDec128{ 1234567, 0 }, 3, "1235" },
+        UDec128FmtPkgTC{ "%d", UDec128{ 1234467, 0 }, 3, "1234" },
+        UDec128FmtPkgTC{ "%d", UDec128{ 499, 0 }, 3, "0" },
+        UDec128FmtPkgTC{ "%f", UDec128{ 1234567, 0 }, 3, "1234.567" },
+        UDec128FmtPkgTC{ "%f", UDec128{ 7, 0 }, 3, "0.007" },
+        UDec128FmtPkgTC{ "%.5f", UDec128{ 1234567, 0 }, 3, "1234.56700" },
+        UDec128FmtPkgTC{ "%.2f", UDec128{ 1999, 0 }, 3, "2.00" },
+        UDec128FmtPkgTC{ "%.2f", UDec128{ 9999, 0 }, 3, "10.00" },
+        UDec128FmtPkgTC{ "%.2f", UDec128{ 1994, 0 }, 3, "1.99" },
+        UDec128FmtPkgTC{ "%.0f", UDec128{ 1500, 0 }, 3, "2" },
+        UDec128FmtPkgTC{ "%.1f", UDec128{ 5, 0 }, 3, "0.0" },
+        UDec128FmtPkgTC{ "%.2f", UDec128{ 5, 0 }, 3, "0.01" },
+        UDec128FmtPkgTC{ "%.2f", UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15,
+            "217224419425.14" },
+        UDec128FmtPkgTC{ "%e", UDec128{ 1234567, 0 }, 3, "1.234567e+03" },
+        UDec128FmtPkgTC{ "%E", UDec128{ 1234567, 0 }, 3, "1.234567E+03" },
+        UDec128FmtPkgTC{ "%.2e", UDec128{ 1235567, 0 }, 3, "1.24e+03" },
+        UDec128FmtPkgTC{ "%.2e", UDec128{ 9996, 0 }, 3, "1.00e+01" },
+        UDec128FmtPkgTC{ "%e", UDec128{ 12, 0 }, 6, "1.2e-05" },
+        UDec128FmtPkgTC{ "%e", UDec128{ 0, 0 }, 6, "0e+00" },
+        UDec128FmtPkgTC{ "%g", UDec128{ 1234567, 0 }, 3, "1234.567" },
+        UDec128FmtPkgTC{ "%g", UDec128{ 10000000000, 0 }, 3, "1e+07" },
+        UDec128FmtPkgTC{ "%g", UDec128{ 12, 0 }, 6, "1.2e-05" },
+        UDec128FmtPkgTC{ "%g", UDec128{ 12, 0 }, 5, "0.00012" },
+        UDec128FmtPkgTC{ "%.3g", UDec128{ 1234567, 0 }, 3, "1.23e+03" },
+        UDec128FmtPkgTC{ "%.5g", UDec128{ 1234567, 0 }, 3, "1234.6" },
+        UDec128FmtPkgTC{ "%.5G", UDec128{ 1000000, 0 }, 3, "1000" },
+        UDec128FmtPkgTC{ "%10.2f", UDec128{ 1234567, 0 }, 3, "   1234.57" },
+        UDec128FmtPkgTC{ "%-10.2f|", UDec128{ 1234567, 0 }, 3, "1234.57   |" },
+        UDec128FmtPkgTC{ "%010.2f", UDec128{ 1234567, 0 }, 3, "0001234.57" },
+        UDec128FmtPkgTC{ "%+.2f", UDec128{ 1234567, 0 }, 3, "+1234.57" },
+        UDec128FmtPkgTC{ "% .2f", UDec128{ 1234567, 0 }, 3, " 1234.57" },
+        UDec128FmtPkgTC{ "%+010.2f", UDec128{ 1234567, 0 }, 3, "+001234.57" },
+        UDec128FmtPkgTC{ "%+10d", UDec128{ 1234567, 0 }, 3, "     +1235" },
+        UDec128FmtPkgTC{ "%x", UDec128{ 1234567, 0 }, 3,
+            "%!x(godec128.UDec128Fmt=1234.567)" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        result := fmt.Sprintf(tc.format, tc.a.Fmt(tc.precision))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: sprintf(%s,%v,%v)->%v!=%v",
+                     i, tc.format, tc.a, tc.precision, tc.expected, result)
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d: %v!=%v", i, a, tc.a)
+        }
+    }
+}
+
+type UDec128FmtScanTC struct {
+    format string
+    str string
+    precision uint
+    expected UDec128
+    expError error
+}
+
+func TestUDec128FmtScan(t *testing.T) {
+    testCases := []UDec128FmtScanTC {
+        UDec128FmtScanTC{ "%v", "1234.567", 3, UDec128{ 1234567, 0 }, nil },
+        UDec128FmtScanTC{ "%v", "  1234.567", 3, UDec128{ 1234567, 0 }, nil },
+        UDec128FmtScanTC{ "%v", "+1234.567", 3, UDec128{ 1234567, 0 }, nil },
+        UDec128FmtScanTC{ "%v", "1234.5675", 3, UDec128{ 1234568, 0 }, nil },
+        UDec128FmtScanTC{ "%f", "1.234567e3", 3, UDec128{ 1234567, 0 }, nil },
+        UDec128FmtScanTC{ "%v", "12", 3, UDec128{ 12000, 0 }, nil },
+        UDec128FmtScanTC{ "%v", "-12", 3, UDec128{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result := UDec128Fmt{ Precision: tc.precision }
+        _, err := fmt.Sscanf(tc.str, tc.format, &result)
+        if tc.expected!=result.Value || tc.expError!=err {
+            t.Errorf("Result mismatch: %d: sscanf(%s,%v,%v)->%v,%v!=%v,%v",
+                     i, tc.format, tc.str, tc.precision, tc.expected, tc.expError,
+                     result.Value, err)
+        }
+    }
+
+    var a, b UDec128Fmt
+    a.Precision, b.Precision = 2, 4
+    n, err := fmt.Sscan("11.5 0.0025", &a, &b)
+    if n!=2 || err!=nil || a.Value!=(UDec128{ 1150, 0 }) || b.Value!=(UDec128{ 25, 0 }) {
+        t.Errorf("Result mismatch: sscan->%v,%v,%v,%v", n, err, a, b)
+    }
+}
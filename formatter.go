@@ -0,0 +1,331 @@
+/*
+ * formatter.go - reusable formatter and parser objects
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strconv"
+)
+
+// options of formatter
+type FormatterOptions struct {
+    // language of locale. If empty then number is formatted without locale
+    Lang string
+    // number of digits in fraction of value
+    Precision uint
+    // number of digits in fraction to display
+    DisplayPrecision uint
+    // trim trailing zeroes in fraction
+    TrimZeroes bool
+    // do not put thousand separators
+    NoSep1000 bool
+    // grouping of digits, if nil then grouping of locale is used
+    Grouping *Grouping
+    // grouping of fractional digits, if nil then grouping of locale is used
+    FracGrouping *FracGrouping
+    // rounding mode used if DisplayPrecision is lesser than Precision
+    Rounding RoundingMode
+    // put plus sign before number
+    PlusSign bool
+    // style of negative numbers (sign, parentheses, trailing minus or CR/DR)
+    Negative NegativeStyle
+    // style of number (decimal, percent, permille or basis points)
+    Style NumberStyle
+    // put bidi marks of locale before signs
+    BidiMarks bool
+    // format number in scientific notation (like '1.5E3')
+    Scientific bool
+    // number of significant digits in scientific notation. If zero then
+    // all significant digits without trailing zeroes are put.
+    // In compact form: if zero then 2 significant digits are put
+    SigDigits int
+    // format exponent with superscript digits (like '1.5×10³')
+    SuperscriptExponent bool
+    // format number in compact form (like '1.2K')
+    Compact bool
+    // style of compact form (short or long names)
+    CompactStyle CompactStyle
+    // minimal width of formatted number in characters (runes)
+    Width int
+    // padding character, if zero then space is used
+    PadChar rune
+    // pad at right side (align to left)
+    PadRight bool
+}
+
+// formatter of decimal fixed points. It is created once and can be used
+// to format many values concurrently
+type Formatter struct {
+    opts FormatterOptions
+    loc *LocFmt
+    grouping Grouping
+    fracGrouping FracGrouping
+    padChar rune
+    zeroPad bool
+}
+
+// create new formatter with options
+func NewFormatter(opts FormatterOptions) *Formatter {
+    f := &Formatter{ opts: opts, padChar: opts.PadChar }
+    if opts.Lang!="" {
+        f.loc = getLocFmt(opts.Lang)
+        f.grouping = localeGrouping(f.loc, opts.NoSep1000)
+        if opts.Grouping!=nil && !opts.NoSep1000 { f.grouping = *opts.Grouping }
+        f.fracGrouping = f.loc.FracGrouping
+        if opts.FracGrouping!=nil { f.fracGrouping = *opts.FracGrouping }
+    }
+    if f.padChar==0 { f.padChar = ' ' }
+    // padding by zeroes is put after sign
+    f.zeroPad = !opts.PadRight && (f.padChar=='0' ||
+                    (f.loc!=nil && f.padChar==f.loc.Digits[0]))
+    return f
+}
+
+// return options of formatter
+func (f *Formatter) Options() FormatterOptions {
+    return f.opts
+}
+
+// return comma character used by formatter
+func (f *Formatter) commaRune() rune {
+    if f.loc==nil { return '.' }
+    return f.loc.Comma
+}
+
+// count runes in UTF-8 string (number of non-continuation bytes)
+func runeCount(s []byte) int {
+    n := 0
+    for _, b := range s {
+        if b&0xc0!=0x80 { n++ }
+    }
+    return n
+}
+
+func appendPadding(dst []byte, padChar rune, n int) []byte {
+    for ; n>0; n-- {
+        dst = appendRune(dst, padChar)
+    }
+    return dst
+}
+
+// append formatted number to dst and return extended buffer
+func (f *Formatter) AppendFormat(dst []byte, a UDec128) []byte {
+    return f.AppendFormatSigned(dst, a, false)
+}
+
+// append formatted number with sign to dst and return extended buffer.
+// If negative is set then minus sign is put.
+func (f *Formatter) AppendFormatSigned(dst []byte, a UDec128, negative bool) []byte {
+    var buf [128]byte
+    s := buf[:0]
+    var sign rune
+    symbols := &LocSymbols{}
+    if f.loc!=nil { symbols = &f.loc.Symbols }
+    if negative {
+        if f.opts.Negative.leadingSign() { sign = symbols.minusSign() }
+    } else if f.opts.PlusSign {
+        sign = symbols.plusSign()
+    }
+    s = f.opts.Negative.appendOpen(s, negative)
+    s = symbols.appendPrefix(s, sign, f.opts.Style, f.opts.BidiMarks)
+    prefixLen := len(s)
+    var nbuf [64]byte
+    if f.opts.Compact {
+        l := f.loc
+        if l==nil { l = plainLocFmt }
+        s = appendCompact(s, l, a, f.opts.Precision, f.opts.SigDigits,
+                f.opts.CompactStyle, f.opts.Rounding, getCompactPatterns(f.opts.Lang))
+    } else if f.opts.Scientific {
+        l := f.loc
+        if l==nil { l = plainLocFmt }
+        digits, exp := sciDigitsRounded(a, f.opts.Precision, f.opts.SigDigits)
+        s = appendLocaleSci(s, l, digits, exp+f.opts.Style.shift(),
+                            f.opts.SuperscriptExponent)
+    } else {
+        ns := appendStyleNumber(nbuf[:0], a, f.opts.Precision,
+                        f.opts.DisplayPrecision, f.opts.TrimZeroes, f.opts.Rounding,
+                        f.opts.Style)
+        if f.loc!=nil {
+            s = appendLocalized(s, f.loc, f.grouping, f.fracGrouping, ns)
+        } else {
+            s = append(s, ns...)
+        }
+    }
+    s = symbols.appendSuffix(s, sign, f.opts.Style, f.opts.BidiMarks)
+    s = f.opts.Negative.appendClose(s, symbols, negative)
+    padLen := 0
+    if f.opts.Width>0 { padLen = f.opts.Width - runeCount(s) }
+    if padLen<=0 { return append(dst, s...) }
+    switch {
+    case f.opts.PadRight:
+        dst = append(dst, s...)
+        dst = appendPadding(dst, f.padChar, padLen)
+    case f.zeroPad:
+        dst = append(dst, s[:prefixLen]...)
+        dst = appendPadding(dst, f.padChar, padLen)
+        dst = append(dst, s[prefixLen:]...)
+    default:
+        dst = appendPadding(dst, f.padChar, padLen)
+        dst = append(dst, s...)
+    }
+    return dst
+}
+
+// format number
+func (f *Formatter) Format(a UDec128) string {
+    var buf [128]byte
+    return string(f.AppendFormat(buf[:0], a))
+}
+
+// format number to bytes
+func (f *Formatter) FormatBytes(a UDec128) []byte {
+    var buf [128]byte
+    s := f.AppendFormat(buf[:0], a)
+    return append([]byte(nil), s...)
+}
+
+// format number with sign
+func (f *Formatter) FormatSigned(a UDec128, negative bool) string {
+    var buf [128]byte
+    return string(f.AppendFormatSigned(buf[:0], a, negative))
+}
+
+// options of parser
+type ParserOptions struct {
+    // language of locale. If empty then number is parsed without locale
+    Lang string
+    // number of digits in fraction of value
+    Precision uint
+    // round value if string has more digits in fraction than Precision
+    Rounding bool
+    // grouping of digits, if nil then grouping of locale is used
+    Grouping *Grouping
+    // grouping of fractional digits, if nil then grouping of locale is used
+    FracGrouping *FracGrouping
+    // style of number (decimal, percent, permille or basis points)
+    Style NumberStyle
+    // check positions of separators and return ParseError if string is malformed
+    Strict bool
+    // substitutions done by normalization before parsing
+    Normalize NormalizeOptions
+    // recognize accounting markers of negative numbers: parentheses,
+    // CR (negative) and DR (positive)
+    Accounting bool
+}
+
+// parser of decimal fixed points. It is created once and can be used
+// to parse many values concurrently
+type Parser struct {
+    opts ParserOptions
+    loc *LocFmt
+    grouping Grouping
+    fracGrouping FracGrouping
+}
+
+// create new parser with options
+func NewParser(opts ParserOptions) *Parser {
+    p := &Parser{ opts: opts }
+    if opts.Lang!="" {
+        p.loc = getLocFmt(opts.Lang)
+        p.grouping = p.loc.Grouping
+        if opts.Grouping!=nil { p.grouping = *opts.Grouping }
+        p.fracGrouping = p.loc.FracGrouping
+        if opts.FracGrouping!=nil { p.fracGrouping = *opts.FracGrouping }
+    }
+    return p
+}
+
+// return options of parser
+func (p *Parser) Options() ParserOptions {
+    return p.opts
+}
+
+// number format used by parser without locale
+var plainLocFmt *LocFmt = &LocFmt{ Comma: '.', Digits: []rune("0123456789") }
+
+// return number format and groupings used by parser
+func (p *Parser) locale() (*LocFmt, Grouping, FracGrouping) {
+    if p.loc==nil { return plainLocFmt, GroupingNone, FracGrouping{} }
+    return p.loc, p.grouping, p.fracGrouping
+}
+
+// parse number from string
+func (p *Parser) Parse(str string) (UDec128, error) {
+    if p.loc==nil && p.opts.Style==StyleDecimal && !p.opts.Strict &&
+            p.opts.Normalize==0 && !p.opts.Accounting {
+        return ParseUDec128(str, p.opts.Precision, p.opts.Rounding)
+    }
+    v, negative, err := p.ParseSigned(str)
+    if err==nil && negative { return UDec128{}, p.negativeError() }
+    return v, err
+}
+
+// parse number from bytes
+func (p *Parser) ParseBytes(str []byte) (UDec128, error) {
+    if p.loc==nil && p.opts.Style==StyleDecimal && !p.opts.Strict &&
+            p.opts.Normalize==0 && !p.opts.Accounting {
+        return ParseUDec128Bytes(str, p.opts.Precision, p.opts.Rounding)
+    }
+    v, negative, err := p.ParseSignedBytes(str)
+    if err==nil && negative { return UDec128{}, p.negativeError() }
+    return v, err
+}
+
+// return error for negative number parsed as unsigned number
+func (p *Parser) negativeError() error {
+    if p.opts.Strict { return &ParseError{ 0, "negative number" } }
+    return strconv.ErrSyntax
+}
+
+// parse number with sign from string. Return value, true if number
+// is negative and error (nil if no error)
+func (p *Parser) ParseSigned(str string) (UDec128, bool, error) {
+    l, g, fg := p.locale()
+    if p.opts.Accounting {
+        if p.opts.Normalize!=0 {
+            str = string(appendNormalized(make([]byte, 0, len(str)), l, []byte(str),
+                                          p.opts.Normalize))
+        }
+        return localeParseUDec128Accounting(l, g, fg, str, p.opts.Precision,
+                                    p.opts.Rounding, p.opts.Style, p.opts.Strict)
+    }
+    if p.opts.Normalize!=0 {
+        ns := appendNormalized(make([]byte, 0, len(str)), l, []byte(str),
+                               p.opts.Normalize)
+        return localeParseUDec128StyleBytes(l, g, fg, ns, p.opts.Precision,
+                                    p.opts.Rounding, p.opts.Style, p.opts.Strict)
+    }
+    return localeParseUDec128Style(l, g, fg, str, p.opts.Precision,
+                                   p.opts.Rounding, p.opts.Style, p.opts.Strict)
+}
+
+// parse number with sign from bytes. Return value, true if number
+// is negative and error (nil if no error)
+func (p *Parser) ParseSignedBytes(str []byte) (UDec128, bool, error) {
+    if p.opts.Accounting { return p.ParseSigned(string(str)) }
+    l, g, fg := p.locale()
+    if p.opts.Normalize!=0 {
+        str = appendNormalized(make([]byte, 0, len(str)), l, str, p.opts.Normalize)
+    }
+    return localeParseUDec128StyleBytes(l, g, fg, str, p.opts.Precision,
+                                        p.opts.Rounding, p.opts.Style, p.opts.Strict)
+}
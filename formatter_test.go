@@ -0,0 +1,167 @@
+/*
+ * formatter_test.go - tests for formatter and parser objects
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "sync"
+    "testing"
+)
+
+type FormatterTC struct {
+    opts FormatterOptions
+    a UDec128
+    expected string
+}
+
+func TestFormatterFormat(t *testing.T) {
+    testCases := []FormatterTC {
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 3 },
+            UDec128{ 1234567, 0 }, "1234.567" },
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 2,
+            Rounding: RoundHalfUp }, UDec128{ 1234567, 0 }, "1234.57" },
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 2 },
+            UDec128{ 1234567, 0 }, "1234.56" },
+        FormatterTC{ FormatterOptions{ Lang: "en", Precision: 3, DisplayPrecision: 2,
+            Rounding: RoundHalfUp }, UDec128{ 1234567, 0 }, "1,234.57" },
+        FormatterTC{ FormatterOptions{ Lang: "en", Precision: 3, DisplayPrecision: 2,
+            NoSep1000: true }, UDec128{ 1234567, 0 }, "1234.56" },
+        FormatterTC{ FormatterOptions{ Lang: "de", Precision: 3, DisplayPrecision: 4,
+            TrimZeroes: true }, UDec128{ 1234500, 0 }, "1.234,5" },
+        FormatterTC{ FormatterOptions{ Lang: "de", Precision: 3, DisplayPrecision: 3,
+            PlusSign: true }, UDec128{ 1234500, 0 }, "+1.234,500" },
+        FormatterTC{ FormatterOptions{ Lang: "ar", Precision: 3, DisplayPrecision: 3,
+            Width: 11 }, UDec128{ 1234500, 0 }, "  ١٬٢٣٤٫٥٠٠" },
+        FormatterTC{ FormatterOptions{ Lang: "ar", Precision: 3, DisplayPrecision: 3,
+            Width: 11, PadChar: '٠', PlusSign: true }, UDec128{ 1234500, 0 },
+            "+٠١٬٢٣٤٫٥٠٠" },
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 3,
+            Width: 10, PadChar: '*', PadRight: true }, UDec128{ 1234500, 0 },
+            "1234.500**" },
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 3,
+            Width: 10, PadChar: '0', PlusSign: true }, UDec128{ 1234500, 0 },
+            "+01234.500" },
+        FormatterTC{ FormatterOptions{ Precision: 3, DisplayPrecision: 3,
+            Width: 4 }, UDec128{ 1234500, 0 }, "1234.500" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        f := NewFormatter(tc.opts)
+        result := f.Format(tc.a)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%v!=%v",
+                     i, tc.opts, tc.a, tc.expected, result)
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d: %v!=%v", i, a, tc.a)
+        }
+        resultBytes := f.FormatBytes(tc.a)
+        if tc.expected!=string(resultBytes) {
+            t.Errorf("Result mismatch: %d: fmtBytes(%v,%v)->%v!=%v",
+                     i, tc.opts, tc.a, tc.expected, string(resultBytes))
+        }
+        if f.Options()!=tc.opts {
+            t.Errorf("Options mismatch: %d: %v!=%v", i, tc.opts, f.Options())
+        }
+    }
+}
+
+func TestFormatterConcurrent(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Lang: "pl", Precision: 2, DisplayPrecision: 2 })
+    var wg sync.WaitGroup
+    results := make([]string, 8)
+    for i := range results {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            for k := 0; k < 100; k++ {
+                results[i] = f.Format(UDec128{ uint64(123456+i), 0 })
+            }
+        }(i)
+    }
+    wg.Wait()
+    for i, r := range results {
+        expected := UDec128{ uint64(123456+i), 0 }.LocaleFormat("pl", 2, false, false)
+        if r!=expected {
+            t.Errorf("Result mismatch: %d: %v!=%v", i, expected, r)
+        }
+    }
+}
+
+type ParserTC struct {
+    opts ParserOptions
+    str string
+    expected UDec128
+    expError error
+}
+
+func TestParserParse(t *testing.T) {
+    testCases := []ParserTC {
+        ParserTC{ ParserOptions{ Precision: 3 }, "1234.567", UDec128{ 1234567, 0 }, nil },
+        ParserTC{ ParserOptions{ Precision: 2 }, "1234.567", UDec128{ 123456, 0 }, nil },
+        ParserTC{ ParserOptions{ Precision: 2, Rounding: true }, "1234.567",
+            UDec128{ 123457, 0 }, nil },
+        ParserTC{ ParserOptions{ Lang: "de", Precision: 3 }, "1.234,567",
+            UDec128{ 1234567, 0 }, nil },
+        ParserTC{ ParserOptions{ Lang: "ar", Precision: 3 }, "١٬٢٣٤٫٥٦٧",
+            UDec128{ 1234567, 0 }, nil },
+        ParserTC{ ParserOptions{ Precision: 3 }, "1,234.567", UDec128{},
+            strconv.ErrSyntax },
+        ParserTC{ ParserOptions{ Lang: "en", Precision: 3 }, "", UDec128{},
+            strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        p := NewParser(tc.opts)
+        result, err := p.Parse(tc.str)
+        if tc.expected!=result || tc.expError!=err {
+            t.Errorf("Result mismatch: %d: parse(%v,%v)->%v,%v!=%v,%v",
+                     i, tc.opts, tc.str, tc.expected, tc.expError, result, err)
+        }
+        result, err = p.ParseBytes([]byte(tc.str))
+        if tc.expected!=result || tc.expError!=err {
+            t.Errorf("Result mismatch: %d: parseBytes(%v,%v)->%v,%v!=%v,%v",
+                     i, tc.opts, tc.str, tc.expected, tc.expError, result, err)
+        }
+    }
+}
+
+func TestFormatterAppendFormatNoAlloc(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Precision: 8, DisplayPrecision: 6,
+                    Rounding: RoundHalfEven, Width: 30, PadChar: '0' })
+    a := UDec128{ 7341542494928938945, 938491 }
+    buf := make([]byte, 0, 128)
+    allocs := testing.AllocsPerRun(100, func() {
+        buf = f.AppendFormat(buf[:0], a)
+    })
+    if allocs!=0 {
+        t.Errorf("AppendFormat allocates: %v", allocs)
+    }
+}
+
+func BenchmarkFormatterAppendFormat(b *testing.B) {
+    f := NewFormatter(FormatterOptions{ Lang: "pl", Precision: 8, DisplayPrecision: 8 })
+    a := UDec128{ 7341542494928938945, 938491 }
+    buf := make([]byte, 0, 128)
+    for i := 0; i < b.N; i++ {
+        buf = f.AppendFormat(buf[:0], a)
+    }
+}
@@ -0,0 +1,223 @@
+/*
+ * fraction.go - fractional prices (treasury 32nds and common fractions)
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strconv"
+    "strings"
+    "github.com/matszpk/goint128"
+)
+
+// ticks of treasury price
+type PriceTicks uint8
+
+const (
+    // 32nds ('99-16')
+    Ticks32 PriceTicks = iota
+    // 32nds with '+' for half of 32nd ('99-16+')
+    Ticks64
+    // 32nds with digit of eighths of 32nd and '+' for half ('99-162', '99-16+')
+    Ticks256
+)
+
+// return number of ticks in one
+func (t PriceTicks) denominator() uint64 {
+    switch t {
+    case Ticks64:
+        return 64
+    case Ticks256:
+        return 256
+    }
+    return 32
+}
+
+// split number into integer part and numerator of fraction with denominator.
+// Fraction is rounded by rounding mode and it can carry into integer part.
+func splitFraction(a UDec128, precision uint, den uint64,
+                    mode RoundingMode) (UDec128, uint64) {
+    pow := uint64_powers[precision]
+    whole, fr := goint128.UInt128(a).Div64(pow)
+    q, r := goint128.UInt128{ fr, 0 }.Mul64(den).Div64(pow)
+    // parity of whole number of fractions (whole*den+q) for half even rounding
+    parity := goint128.UInt128{ (whole[0]&1)*den + q[0], 0 }
+    if roundQuotient(parity, r, pow, false, mode) { q = q.Add64(1) }
+    if q[0]==den { return UDec128(whole.Add64(1)), 0 }
+    return UDec128(whole), q[0]
+}
+
+// return integer part plus fraction as number with precision.
+// If rounding is not set then fraction is truncated.
+func fractionValue(whole UDec128, num, den uint64, precision uint,
+                    rounding bool) (UDec128, error) {
+    pow := uint64_powers[precision]
+    chi, clo := goint128.UInt128(whole).MulFull(goint128.UInt128{ pow, 0 })
+    if chi[0]!=0 || chi[1]!=0 { return UDec128{}, strconv.ErrRange }
+    q, r := goint128.UInt128{ num, 0 }.Mul64(pow).Div64(den)
+    if rounding && r!=0 && r>=den-r { q = q.Add64(1) }
+    v, carry := UDec128(clo).AddC(UDec128(q), 0)
+    if carry!=0 { return UDec128{}, strconv.ErrRange }
+    return v, nil
+}
+
+// parse integer part of fractional price
+func parseWhole(str string) (UDec128, error) {
+    v, err := goint128.ParseUInt128(str)
+    if err!=nil { return UDec128{}, err }
+    return UDec128(v), nil
+}
+
+// append integer part of fractional price
+func appendWhole(dst []byte, whole UDec128) []byte {
+    var buf [40]byte
+    return append(dst, uint128DigitsBuf(&buf, goint128.UInt128(whole))...)
+}
+
+// append treasury price (like '99-16+' or '99-162') to dst and return
+// extended buffer. Price is rounded to nearest tick by rounding mode.
+func (a UDec128) AppendFormatTreasury(dst []byte, precision uint, ticks PriceTicks,
+                    mode RoundingMode) []byte {
+    whole, n := splitFraction(a, precision, ticks.denominator(), mode)
+    dst = appendWhole(dst, whole)
+    // number of 32nds and remaining part of 32nd
+    sub := uint64(0)
+    switch ticks {
+    case Ticks64:
+        n, sub = n>>1, (n&1)<<2
+    case Ticks256:
+        n, sub = n>>3, n&7
+    }
+    dst = append(dst, '-', byte('0'+n/10), byte('0'+n%10))
+    switch {
+    case sub==4:
+        dst = append(dst, '+')
+    case sub!=0:
+        dst = append(dst, byte('0'+sub))
+    }
+    return dst
+}
+
+// format treasury price
+func (a UDec128) FormatTreasury(precision uint, ticks PriceTicks,
+                    mode RoundingMode) string {
+    var buf [64]byte
+    return string(a.AppendFormatTreasury(buf[:0], precision, ticks, mode))
+}
+
+// parse treasury price (like '99-16', '99-16+' or '99-162'). Third digit
+// is number of eighths of 32nd ('+' is half of 32nd). Separator can be
+// also apostrophe ('99'16'). If rounding is not set then digits beyond
+// precision are truncated.
+func ParseUDec128Treasury(str string, precision uint,
+                    rounding bool) (UDec128, error) {
+    sep := strings.IndexAny(str, "-'")
+    if sep==-1 { return UDec128{}, strconv.ErrSyntax }
+    whole, err := parseWhole(str[:sep])
+    if err!=nil { return UDec128{}, err }
+    ticks := str[sep+1:]
+    if len(ticks)<2 || len(ticks)>3 { return UDec128{}, strconv.ErrSyntax }
+    for i := 0; i < 2; i++ {
+        if ticks[i]<'0' || ticks[i]>'9' { return UDec128{}, strconv.ErrSyntax }
+    }
+    n := uint64(ticks[0]-'0')*10 + uint64(ticks[1]-'0')
+    if n>=32 { return UDec128{}, strconv.ErrSyntax }
+    n <<= 3
+    if len(ticks)==3 {
+        switch c := ticks[2]; {
+        case c=='+':
+            n += 4
+        case c>='0' && c<='7':
+            n += uint64(c-'0')
+        default:
+            return UDec128{}, strconv.ErrSyntax
+        }
+    }
+    return fractionValue(whole, n, 256, precision, rounding)
+}
+
+// parse treasury price from bytes
+func ParseUDec128TreasuryBytes(str []byte, precision uint,
+                    rounding bool) (UDec128, error) {
+    return ParseUDec128Treasury(string(str), precision, rounding)
+}
+
+// return greatest common divisor
+func gcd64(a, b uint64) uint64 {
+    for b!=0 { a, b = b, a%b }
+    return a
+}
+
+// append number as mixed fraction with denominator (like '3 1/8') to dst
+// and return extended buffer. Fraction is reduced to lowest terms and
+// rounded to nearest multiple of 1/den by rounding mode.
+func (a UDec128) AppendFormatFraction(dst []byte, precision uint, den uint64,
+                    mode RoundingMode) []byte {
+    if den==0 { den = 1 }
+    whole, n := splitFraction(a, precision, den, mode)
+    if n==0 || !whole.IsZero() {
+        dst = appendWhole(dst, whole)
+        if n==0 { return dst }
+        dst = append(dst, ' ')
+    }
+    g := gcd64(n, den)
+    dst = strconv.AppendUint(dst, n/g, 10)
+    dst = append(dst, '/')
+    return strconv.AppendUint(dst, den/g, 10)
+}
+
+// format number as mixed fraction
+func (a UDec128) FormatFraction(precision uint, den uint64, mode RoundingMode) string {
+    var buf [64]byte
+    return string(a.AppendFormatFraction(buf[:0], precision, den, mode))
+}
+
+// parse fraction (like '3 1/8', '1/8', '9/8' or '3'). Denominator can be any.
+// If rounding is not set then digits beyond precision are truncated.
+func ParseUDec128Fraction(str string, precision uint,
+                    rounding bool) (UDec128, error) {
+    str = strings.TrimFunc(str, isSpaceRune)
+    slash := strings.IndexByte(str, '/')
+    if slash==-1 { return ParseUDec128(str, precision, rounding) }
+    whole := UDec128{}
+    numStr := str[:slash]
+    mixed := false
+    if i := strings.LastIndexFunc(numStr, isSpaceRune); i!=-1 {
+        var err error
+        whole, err = parseWhole(strings.TrimRightFunc(numStr[:i], isSpaceRune))
+        if err!=nil { return UDec128{}, err }
+        numStr = strings.TrimLeftFunc(numStr[i:], isSpaceRune)
+        mixed = true
+    }
+    num, err := strconv.ParseUint(numStr, 10, 64)
+    if err!=nil { return UDec128{}, strconv.ErrSyntax }
+    den, err := strconv.ParseUint(str[slash+1:], 10, 64)
+    if err!=nil || den==0 { return UDec128{}, strconv.ErrSyntax }
+    // proper fraction is required after integer part
+    if mixed && num>=den { return UDec128{}, strconv.ErrSyntax }
+    return fractionValue(whole.Add64(num/den), num%den, den, precision, rounding)
+}
+
+// parse fraction from bytes
+func ParseUDec128FractionBytes(str []byte, precision uint,
+                    rounding bool) (UDec128, error) {
+    return ParseUDec128Fraction(string(str), precision, rounding)
+}
@@ -0,0 +1,173 @@
+/*
+ * fraction_test.go - tests for fractional prices
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type UDec128FmtTreasuryTC struct {
+    value UDec128
+    precision uint
+    ticks PriceTicks
+    mode RoundingMode
+    expected string
+}
+
+func TestUDec128FormatTreasury(t *testing.T) {
+    testCases := []UDec128FmtTreasuryTC {
+        UDec128FmtTreasuryTC{ UDec128{ 99500000, 0 }, 6, Ticks32, RoundHalfEven,
+                    "99-16" },
+        UDec128FmtTreasuryTC{ UDec128{ 99515625, 0 }, 6, Ticks64, RoundHalfEven,
+                    "99-16+" },
+        UDec128FmtTreasuryTC{ UDec128{ 99515625, 0 }, 6, Ticks256, RoundHalfEven,
+                    "99-16+" },
+        UDec128FmtTreasuryTC{ UDec128{ 995078125, 0 }, 7, Ticks256, RoundHalfEven,
+                    "99-162" },
+        UDec128FmtTreasuryTC{ UDec128{ 995234375, 0 }, 7, Ticks256, RoundHalfEven,
+                    "99-166" },
+        UDec128FmtTreasuryTC{ UDec128{ 10003125, 0 }, 5, Ticks32, RoundHalfEven,
+                    "100-01" },
+        UDec128FmtTreasuryTC{ UDec128{ 9951, 0 }, 2, Ticks32, RoundHalfEven,
+                    "99-16" },
+        UDec128FmtTreasuryTC{ UDec128{ 9951, 0 }, 2, Ticks64, RoundHalfEven,
+                    "99-16+" },
+        UDec128FmtTreasuryTC{ UDec128{ 9951, 0 }, 2, Ticks32, RoundUp, "99-17" },
+        UDec128FmtTreasuryTC{ UDec128{ 9999, 0 }, 2, Ticks32, RoundHalfEven,
+                    "100-00" },
+        UDec128FmtTreasuryTC{ UDec128{ 99, 0 }, 0, Ticks64, RoundHalfEven, "99-00" },
+        UDec128FmtTreasuryTC{ UDec128{ 5, 0 }, 1, Ticks32, RoundHalfEven, "0-16" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.FormatTreasury(tc.precision, tc.ticks, tc.mode)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+    }
+}
+
+type UDec128ParseFractionTC struct {
+    str string
+    precision uint
+    rounding bool
+    expected UDec128
+    expectedError error
+}
+
+func TestParseUDec128Treasury(t *testing.T) {
+    testCases := []UDec128ParseFractionTC {
+        UDec128ParseFractionTC{ "99-16", 6, false, UDec128{ 99500000, 0 }, nil },
+        UDec128ParseFractionTC{ "99-16+", 6, false, UDec128{ 99515625, 0 }, nil },
+        UDec128ParseFractionTC{ "99-162", 7, false, UDec128{ 995078125, 0 }, nil },
+        UDec128ParseFractionTC{ "99'162", 7, false, UDec128{ 995078125, 0 }, nil },
+        UDec128ParseFractionTC{ "99-162", 4, false, UDec128{ 995078, 0 }, nil },
+        UDec128ParseFractionTC{ "99-16+", 4, true, UDec128{ 995156, 0 }, nil },
+        UDec128ParseFractionTC{ "99-16+", 3, true, UDec128{ 99516, 0 }, nil },
+        UDec128ParseFractionTC{ "99-16+", 3, false, UDec128{ 99515, 0 }, nil },
+        UDec128ParseFractionTC{ "100-00", 2, false, UDec128{ 10000, 0 }, nil },
+        UDec128ParseFractionTC{ "99-32", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "99-168", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "99-1", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "99-16++", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "99.5", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "-16", 2, false, UDec128{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, err := ParseUDec128Treasury(tc.str, tc.precision, tc.rounding)
+        if tc.expectedError!=err || tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v,%v->%v,%v", i, tc, tc.expected,
+                     tc.expectedError, result, err)
+        }
+        resultBytes, err := ParseUDec128TreasuryBytes([]byte(tc.str), tc.precision,
+                                                      tc.rounding)
+        if tc.expectedError!=err || tc.expected!=resultBytes {
+            t.Errorf("Result mismatch: %d: %v: %v,%v->%v,%v", i, tc, tc.expected,
+                     tc.expectedError, resultBytes, err)
+        }
+    }
+}
+
+type UDec128FmtFractionTC struct {
+    value UDec128
+    precision uint
+    den uint64
+    mode RoundingMode
+    expected string
+}
+
+func TestUDec128FormatFraction(t *testing.T) {
+    testCases := []UDec128FmtFractionTC {
+        UDec128FmtFractionTC{ UDec128{ 3125, 0 }, 3, 8, RoundHalfEven, "3 1/8" },
+        UDec128FmtFractionTC{ UDec128{ 35, 0 }, 1, 8, RoundHalfEven, "3 1/2" },
+        UDec128FmtFractionTC{ UDec128{ 125, 0 }, 3, 8, RoundHalfEven, "1/8" },
+        UDec128FmtFractionTC{ UDec128{ 3, 0 }, 0, 8, RoundHalfEven, "3" },
+        UDec128FmtFractionTC{ UDec128{ 0, 0 }, 2, 8, RoundHalfEven, "0" },
+        UDec128FmtFractionTC{ UDec128{ 333, 0 }, 2, 3, RoundHalfEven, "3 1/3" },
+        UDec128FmtFractionTC{ UDec128{ 399, 0 }, 2, 8, RoundHalfEven, "4" },
+        UDec128FmtFractionTC{ UDec128{ 310, 0 }, 2, 8, RoundHalfEven, "3 1/8" },
+        UDec128FmtFractionTC{ UDec128{ 310, 0 }, 2, 8, RoundDown, "3" },
+        UDec128FmtFractionTC{ UDec128{ 3375, 0 }, 3, 16, RoundHalfEven, "3 3/8" },
+        // ties with odd denominators
+        UDec128FmtFractionTC{ UDec128{ 35, 0 }, 1, 1, RoundHalfEven, "4" },
+        UDec128FmtFractionTC{ UDec128{ 25, 0 }, 1, 1, RoundHalfEven, "2" },
+        UDec128FmtFractionTC{ UDec128{ 15, 0 }, 1, 3, RoundHalfEven, "1 1/3" },
+        UDec128FmtFractionTC{ UDec128{ 25, 0 }, 1, 3, RoundHalfEven, "2 2/3" },
+        UDec128FmtFractionTC{ UDec128{ 13, 0 }, 1, 5, RoundHalfEven, "1 1/5" },
+        UDec128FmtFractionTC{ UDec128{ 15, 0 }, 1, 3, RoundHalfDown, "1 1/3" },
+        UDec128FmtFractionTC{ UDec128{ 11, 0 }, 1, 5, RoundHalfEven, "1 1/5" },
+    }
+    for i, tc := range testCases {
+        result := tc.value.FormatFraction(tc.precision, tc.den, tc.mode)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+    }
+}
+
+func TestParseUDec128Fraction(t *testing.T) {
+    testCases := []UDec128ParseFractionTC {
+        UDec128ParseFractionTC{ "3 1/8", 3, false, UDec128{ 3125, 0 }, nil },
+        UDec128ParseFractionTC{ "3 1/8", 2, false, UDec128{ 312, 0 }, nil },
+        UDec128ParseFractionTC{ "3 1/8", 2, true, UDec128{ 313, 0 }, nil },
+        UDec128ParseFractionTC{ " 3  1/8 ", 3, false, UDec128{ 3125, 0 }, nil },
+        UDec128ParseFractionTC{ "3\u00a01/8", 3, false, UDec128{ 3125, 0 }, nil },
+        UDec128ParseFractionTC{ "1/8", 3, false, UDec128{ 125, 0 }, nil },
+        UDec128ParseFractionTC{ "9/8", 3, false, UDec128{ 1125, 0 }, nil },
+        UDec128ParseFractionTC{ "2/3", 4, true, UDec128{ 6667, 0 }, nil },
+        UDec128ParseFractionTC{ "3", 2, false, UDec128{ 300, 0 }, nil },
+        UDec128ParseFractionTC{ "3.25", 2, false, UDec128{ 325, 0 }, nil },
+        UDec128ParseFractionTC{ "3 9/8", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "1/0", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "a 1/8", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "1/8x", 2, false, UDec128{}, strconv.ErrSyntax },
+        UDec128ParseFractionTC{ "1 2 1/8", 2, false, UDec128{}, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, err := ParseUDec128Fraction(tc.str, tc.precision, tc.rounding)
+        if tc.expectedError!=err || tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v,%v->%v,%v", i, tc, tc.expected,
+                     tc.expectedError, result, err)
+        }
+    }
+}
@@ -0,0 +1,129 @@
+/*
+ * grouping.go - grouping of digits
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strconv"
+    "strings"
+)
+
+// grouping of digits of integer part (as in CLDR patterns)
+type Grouping struct {
+    // size of first group (from comma), zero - no grouping
+    Primary uint8
+    // size of next groups, zero - same as Primary
+    Secondary uint8
+    // minimal number of digits in first group (at left side) required to
+    // put separators. zero is treated as 1.
+    MinGrouping uint8
+}
+
+var (
+    // no grouping
+    GroupingNone Grouping = Grouping{}
+    // groups of three digits (1,234,567)
+    GroupingThousands Grouping = Grouping{ 3, 3, 1 }
+    // Indian grouping (12,34,567)
+    GroupingIndian Grouping = Grouping{ 3, 2, 1 }
+    // myriad grouping, groups of four digits (123,4567)
+    GroupingMyriad Grouping = Grouping{ 4, 4, 1 }
+)
+
+// return true if grouping is enabled
+func (g Grouping) Enabled() bool {
+    return g.Primary!=0
+}
+
+func (g Grouping) secondary() int {
+    if g.Secondary==0 { return int(g.Primary) }
+    return int(g.Secondary)
+}
+
+// return true if integer part with intLen digits should be grouped
+func (g Grouping) applies(intLen int) bool {
+    if g.Primary==0 { return false }
+    minGrouping := int(g.MinGrouping)
+    if minGrouping==0 { minGrouping = 1 }
+    return intLen >= int(g.Primary)+minGrouping
+}
+
+// return true if separator is put before digit that have rem digits
+// at right side in integer part
+func (g Grouping) isBoundary(rem int) bool {
+    p := int(g.Primary)
+    if rem<p { return false }
+    return rem==p || (rem-p)%g.secondary()==0
+}
+
+// parse grouping from integer part of CLDR or ICU pattern (like '#,##,##0')
+func parseGroupingPattern(pattern string) Grouping {
+    if i := strings.IndexByte(pattern, ';'); i!=-1 { pattern = pattern[:i] }
+    if i := strings.IndexAny(pattern, ".E"); i!=-1 { pattern = pattern[:i] }
+    last := strings.LastIndexByte(pattern, ',')
+    if last==-1 { return GroupingNone }
+    // count digit placeholders after separators
+    countDigits := func(s string) int {
+        n := 0
+        for i := 0; i < len(s); i++ {
+            if s[i]=='#' || s[i]=='@' || (s[i]>='0' && s[i]<='9') { n++ }
+        }
+        return n
+    }
+    g := Grouping{ Primary: uint8(countDigits(pattern[last+1:])), MinGrouping: 1 }
+    if prev := strings.LastIndexByte(pattern[:last], ','); prev!=-1 {
+        g.Secondary = uint8(countDigits(pattern[prev+1:last]))
+    } else {
+        g.Secondary = g.Primary
+    }
+    return g
+}
+
+// parse minimum grouping digits from CLDR (can be number or empty)
+func parseMinGrouping(s string) uint8 {
+    v, err := strconv.ParseUint(s, 10, 8)
+    if err!=nil || v==0 { return 1 }
+    return uint8(v)
+}
+
+// default separator of groups of fractional digits (thin space)
+const DefaultFracSep rune = 0x2009
+
+// grouping of digits of fractional part (like 0.123 456 78)
+type FracGrouping struct {
+    // size of groups (from comma), zero - no grouping
+    Size uint8
+    // separator of groups, if zero then DefaultFracSep is used
+    Sep rune
+}
+
+// return separator of fractional groups
+func (fg FracGrouping) sep() rune {
+    if fg.Sep==0 { return DefaultFracSep }
+    return fg.Sep
+}
+
+// return true if rune is separator of fractional groups while parsing.
+// Without grouping of fraction no separator is accepted.
+func (fg FracGrouping) isSep(r rune) bool {
+    return fg.Size!=0 && r==fg.sep()
+}
@@ -0,0 +1,209 @@
+/*
+ * grouping_test.go - tests for grouping of digits
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type GroupingPatternTC struct {
+    pattern string
+    expected Grouping
+}
+
+func TestParseGroupingPattern(t *testing.T) {
+    testCases := []GroupingPatternTC {
+        GroupingPatternTC{ "#,##0.###", Grouping{ 3, 3, 1 } },
+        GroupingPatternTC{ "#,##,##0.###", Grouping{ 3, 2, 1 } },
+        GroupingPatternTC{ "#,###0.###", Grouping{ 4, 4, 1 } },
+        GroupingPatternTC{ "#0.###", GroupingNone },
+        GroupingPatternTC{ "#,##0.###;(#,##0.###)", Grouping{ 3, 3, 1 } },
+        GroupingPatternTC{ "#,##0E0", Grouping{ 3, 3, 1 } },
+    }
+    for i, tc := range testCases {
+        result := parseGroupingPattern(tc.pattern)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v!=%v",
+                     i, tc.pattern, tc.expected, result)
+        }
+    }
+}
+
+type GroupingFmtTC struct {
+    grouping Grouping
+    value UDec128
+    expected string
+}
+
+func TestGroupingFormat(t *testing.T) {
+    testCases := []GroupingFmtTC {
+        GroupingFmtTC{ GroupingThousands, UDec128{ 123456789, 0 }, "12,345,678.9" },
+        GroupingFmtTC{ GroupingThousands, UDec128{ 123456, 0 }, "12,345.6" },
+        GroupingFmtTC{ GroupingThousands, UDec128{ 12345, 0 }, "1,234.5" },
+        GroupingFmtTC{ GroupingIndian, UDec128{ 1234567890, 0 }, "12,34,56,789.0" },
+        GroupingFmtTC{ GroupingIndian, UDec128{ 12345, 0 }, "1,234.5" },
+        GroupingFmtTC{ GroupingMyriad, UDec128{ 1234567890, 0 }, "1,2345,6789.0" },
+        GroupingFmtTC{ GroupingMyriad, UDec128{ 12345, 0 }, "1234.5" },
+        GroupingFmtTC{ GroupingMyriad, UDec128{ 123450, 0 }, "1,2345.0" },
+        GroupingFmtTC{ Grouping{ 3, 3, 2 }, UDec128{ 12345, 0 }, "1234.5" },
+        GroupingFmtTC{ Grouping{ 3, 3, 2 }, UDec128{ 123456, 0 }, "12,345.6" },
+        GroupingFmtTC{ Grouping{ 3, 3, 2 }, UDec128{ 12345678, 0 }, "1,234,567.8" },
+        GroupingFmtTC{ Grouping{ 3, 0, 1 }, UDec128{ 12345678, 0 }, "1,234,567.8" },
+        GroupingFmtTC{ GroupingNone, UDec128{ 12345678, 0 }, "1234567.8" },
+    }
+    for i, tc := range testCases {
+        f := NewFormatter(FormatterOptions{ Lang: "en", Precision: 1,
+                    DisplayPrecision: 1, Grouping: &tc.grouping })
+        result := f.Format(tc.value)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%v!=%v",
+                     i, tc.grouping, tc.value, tc.expected, result)
+        }
+        p := NewParser(ParserOptions{ Lang: "en", Precision: 1,
+                    Grouping: &tc.grouping })
+        v, err := p.Parse(result)
+        if v!=tc.value || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v", i, result, v, err)
+        }
+    }
+}
+
+func TestGroupingLocale(t *testing.T) {
+    a := UDec128{ 123456, 0 }
+    if s := a.LocaleFormat("pl", 2, false, false); s!="1234,56" {
+        t.Errorf("Result mismatch: pl: %v", s)
+    }
+    if s := a.LocaleFormat("de", 2, false, false); s!="1.234,56" {
+        t.Errorf("Result mismatch: de: %v", s)
+    }
+    if s := a.LocaleFormat("en-IN", 0, false, false); s!="1,23,456" {
+        t.Errorf("Result mismatch: en-IN: %v", s)
+    }
+    // locale without grouping
+    if err := RegisterLocale("xx", &LocFmt{ Comma: ',',
+                    Digits: []rune("0123456789") }); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterLocale("xx")
+    if s := a.LocaleFormat("xx", 2, false, false); s!="1234,56" {
+        t.Errorf("Result mismatch: xx: %v", s)
+    }
+    if _, err := LocaleParseUDec128("xx", "1 234,56", 2, false);
+            err!=strconv.ErrSyntax {
+        t.Errorf("Separator accepted without grouping: %v", err)
+    }
+    p := NewParser(ParserOptions{ Lang: "en", Precision: 1, Grouping: &GroupingNone })
+    if _, err := p.Parse("1,234.5"); err!=strconv.ErrSyntax {
+        t.Errorf("Separator accepted without grouping: %v", err)
+    }
+    // myriad grouping in custom locale
+    if err := RegisterLocale("zz", &LocFmt{ Comma: '.', Sep1000: ',',
+                Grouping: GroupingMyriad, Digits: []rune("0123456789") }); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterLocale("zz")
+    b := UDec128{ 123456789012, 0 }
+    if s := b.LocaleFormat("zz", 2, false, false); s!="12,3456,7890.12" {
+        t.Errorf("Result mismatch: zz: %v", s)
+    }
+    if v, err := LocaleParseUDec128("zz", "12,3456,7890.12", 2, false); v!=b || err!=nil {
+        t.Errorf("Result mismatch: parse zz: %v,%v", v, err)
+    }
+}
+
+type FracGroupingTC struct {
+    lang string
+    fracGrouping FracGrouping
+    value UDec128
+    precision uint
+    expected string
+}
+
+func TestFracGrouping(t *testing.T) {
+    testCases := []FracGroupingTC {
+        FracGroupingTC{ "en", FracGrouping{ 3, 0 }, UDec128{ 12345678, 0 }, 8,
+                    "0.123\u2009456\u200978" },
+        FracGroupingTC{ "en", FracGrouping{ 3, 0 }, UDec128{ 123456, 0 }, 6,
+                    "0.123\u2009456" },
+        FracGroupingTC{ "en", FracGrouping{ 3, 0 }, UDec128{ 1234567891, 0 }, 5,
+                    "12,345.678\u200991" },
+        FracGroupingTC{ "en", FracGrouping{ 5, '_' }, UDec128{ 1234567891, 0 }, 8,
+                    "12.34567_891" },
+        FracGroupingTC{ "fr", FracGrouping{ 3, 0x202f }, UDec128{ 1234567891, 0 }, 5,
+                    "12\u00a0345,678\u202f91" },
+        FracGroupingTC{ "ar", FracGrouping{ 2, 0 }, UDec128{ 12345, 0 }, 4,
+                    "١٫٢٣\u2009٤٥" },
+        FracGroupingTC{ "en", FracGrouping{ 3, 0 }, UDec128{ 1234567891, 0 }, 0,
+                    "1,234,567,891" },
+    }
+    for i, tc := range testCases {
+        f := NewFormatter(FormatterOptions{ Lang: tc.lang, Precision: tc.precision,
+                    DisplayPrecision: tc.precision, FracGrouping: &tc.fracGrouping })
+        result := f.Format(tc.value)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%v!=%v",
+                     i, tc.fracGrouping, tc.value, tc.expected, result)
+        }
+        p := NewParser(ParserOptions{ Lang: tc.lang, Precision: tc.precision,
+                    FracGrouping: &tc.fracGrouping })
+        v, err := p.ParseBytes([]byte(result))
+        if v!=tc.value || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v", i, result, v, err)
+        }
+    }
+    // without fractional grouping spaces in fraction are not accepted
+    for _, str := range []string{ "1.5 2", "1.5\u00a02", "0.123\u2009456" } {
+        if _, err := LocaleParseUDec128("en", str, 3, false); err!=strconv.ErrSyntax {
+            t.Errorf("Space accepted in fraction: en: %v: %v", str, err)
+        }
+    }
+    for _, str := range []string{ "1,5 2", "1,5\u00a02" } {
+        if _, err := LocaleParseUDec128Bytes("de", []byte(str), 3,
+                    false); err!=strconv.ErrSyntax {
+            t.Errorf("Space accepted in fraction: de: %v: %v", str, err)
+        }
+    }
+    // only separator of fractional grouping is accepted
+    p := NewParser(ParserOptions{ Lang: "en", Precision: 6,
+                FracGrouping: &FracGrouping{ 3, 0 } })
+    if v, err := p.Parse("0.123\u2009456"); v!=(UDec128{ 123456, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: parse: %v,%v", v, err)
+    }
+    if _, err := p.Parse("0.123 456"); err!=strconv.ErrSyntax {
+        t.Errorf("Space accepted in fraction: %v", err)
+    }
+    if _, err := LocaleParseUDec128("en", "1 234.5", 1, false); err!=strconv.ErrSyntax {
+        t.Errorf("Space accepted in integer part: %v", err)
+    }
+    // locale with fractional grouping
+    if err := RegisterLocale("en-x-si", &LocFmt{ Comma: '.', Sep1000: 0x2009,
+                FracGrouping: FracGrouping{ 3, 0x2009 },
+                Digits: []rune("0123456789") }); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterLocale("en-x-si")
+    a := UDec128{ 123456789, 0 }
+    if s := a.LocaleFormat("en-x-si", 5, false, false); s!="1\u2009234.567\u200989" {
+        t.Errorf("Result mismatch: en-x-si: %v", s)
+    }
+}
@@ -0,0 +1,440 @@
+/*
+ * icu.go - ICU (CLDR) decimal format patterns
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "errors"
+    "math/bits"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+    "github.com/matszpk/goint128"
+)
+
+var ErrInvalidPattern = errors.New("godec128: invalid decimal pattern")
+
+// configuration of formatter given by ICU decimal format pattern
+// (like '#,##0.00 ¤' or '#,##,##0.###')
+type DecimalPattern struct {
+    // minimal and maximal number of digits of integer part
+    // (zero maximal number - no limit)
+    MinIntDigits, MaxIntDigits int
+    // minimal and maximal number of digits of fraction
+    MinFracDigits, MaxFracDigits int
+    // grouping of digits of integer part
+    Grouping Grouping
+    // prefixes and suffixes in pattern syntax (with quotes and special
+    // characters: '%', '‰', '¤', '-' and '+')
+    PositivePrefix, PositiveSuffix string
+    NegativePrefix, NegativeSuffix string
+    // rounding increment in units of last digit of fraction
+    // (like 5 for '#,##0.05'), zero - no increment
+    Increment uint64
+    // style of number (percent or permille if pattern has '%' or '‰')
+    Style NumberStyle
+    // rounding mode (half to even by default)
+    Rounding RoundingMode
+}
+
+// return true if character belongs to number part of pattern
+func isPatternNumberChar(c byte) bool {
+    return c=='#' || c==',' || c=='.' || (c>='0' && c<='9')
+}
+
+// split subpattern into prefix, number part and suffix
+func splitSubpattern(pattern string) (string, string, string, error) {
+    start, end := -1, -1
+    quoted := false
+    for i := 0; i < len(pattern); i++ {
+        c := pattern[i]
+        switch {
+        case c=='\'':
+            quoted = !quoted
+        case quoted:
+        case c=='@' || c=='*' || (c=='E' && end==i):
+            // significant digits, padding and exponent are not supported
+            return "", "", "", ErrInvalidPattern
+        case isPatternNumberChar(c):
+            if end!=-1 && end!=i { return "", "", "", ErrInvalidPattern }
+            if start==-1 { start = i }
+            end = i+1
+        }
+    }
+    if quoted || start==-1 { return "", "", "", ErrInvalidPattern }
+    return pattern[:start], pattern[start:end], pattern[end:], nil
+}
+
+// parse number part of pattern (like '#,##0.00')
+func (p *DecimalPattern) parseNumber(num string) error {
+    intPart, frac := num, ""
+    if i := strings.IndexByte(num, '.'); i!=-1 {
+        intPart, frac = num[:i], num[i+1:]
+        if strings.ContainsAny(frac, ".,") { return ErrInvalidPattern }
+    }
+    if strings.HasSuffix(intPart, ",") || strings.HasPrefix(intPart, ",") ||
+            strings.Contains(intPart, ",,") {
+        return ErrInvalidPattern
+    }
+    var incDigits []byte
+    for i := 0; i < len(intPart); i++ {
+        switch c := intPart[i]; {
+        case c=='#':
+            // '#' can not be after '0'
+            if p.MinIntDigits!=0 { return ErrInvalidPattern }
+            incDigits = append(incDigits, '0')
+        case c!=',':
+            p.MinIntDigits++
+            incDigits = append(incDigits, c)
+        }
+    }
+    for i := 0; i < len(frac); i++ {
+        if frac[i]!='#' {
+            // '0' can not be after '#'
+            if p.MaxFracDigits!=p.MinFracDigits { return ErrInvalidPattern }
+            p.MinFracDigits++
+            incDigits = append(incDigits, frac[i])
+        } else {
+            incDigits = append(incDigits, '0')
+        }
+        p.MaxFracDigits++
+    }
+    p.Grouping = parseGroupingPattern(intPart)
+    if strings.Trim(string(incDigits), "0")!="" {
+        inc, err := strconv.ParseUint(string(incDigits), 10, 64)
+        if err!=nil { return ErrInvalidPattern }
+        p.Increment = inc
+    }
+    return nil
+}
+
+// return style of number given by special characters of affix
+func affixStyle(affix string) NumberStyle {
+    quoted := false
+    for _, r := range affix {
+        switch {
+        case r=='\'':
+            quoted = !quoted
+        case quoted:
+        case r=='%':
+            return StylePercent
+        case r=='‰':
+            return StylePermille
+        }
+    }
+    return StyleDecimal
+}
+
+// parse ICU decimal format pattern (like '#,##0.00;(#,##0.00)'). Patterns
+// with significant digits ('@'), exponent and padding are not supported.
+// If pattern is malformed then ErrInvalidPattern is returned.
+func ParseDecimalPattern(pattern string) (*DecimalPattern, error) {
+    p := &DecimalPattern{ Rounding: RoundHalfEven }
+    pos, neg := pattern, ""
+    hasNeg := false
+    quoted := false
+    for i := 0; i < len(pattern); i++ {
+        if pattern[i]=='\'' { quoted = !quoted }
+        if !quoted && pattern[i]==';' {
+            pos, neg = pattern[:i], pattern[i+1:]
+            hasNeg = true
+            break
+        }
+    }
+    prefix, num, suffix, err := splitSubpattern(pos)
+    if err!=nil { return nil, err }
+    if err := p.parseNumber(num); err!=nil { return nil, err }
+    p.PositivePrefix, p.PositiveSuffix = prefix, suffix
+    // negative subpattern gives only prefix and suffix
+    p.NegativePrefix, p.NegativeSuffix = "-"+prefix, suffix
+    if hasNeg {
+        p.NegativePrefix, _, p.NegativeSuffix, err = splitSubpattern(neg)
+        if err!=nil { return nil, err }
+    }
+    p.Style = affixStyle(prefix+suffix)
+    return p, nil
+}
+
+// formatter and parser of decimal fixed points using decimal pattern
+type DecimalFormat struct {
+    pattern DecimalPattern
+    loc *LocFmt
+    posPrefix, posSuffix, negPrefix, negSuffix string
+}
+
+// expand prefix or suffix in pattern syntax with symbols of locale
+func expandAffix(affix string, ls *LocSymbols, c *Currency, lang string) string {
+    var dst []byte
+    quoted := false
+    for i := 0; i < len(affix); {
+        r, size := utf8.DecodeRuneInString(affix[i:])
+        i += size
+        switch {
+        case r=='\'':
+            // two apostrophes give apostrophe
+            if i<len(affix) && affix[i]=='\'' {
+                dst = append(dst, '\'')
+                i++
+            } else {
+                quoted = !quoted
+            }
+        case quoted:
+            dst = appendRune(dst, r)
+        case r=='%':
+            dst = appendRune(dst, ls.styleSign(StylePercent))
+        case r=='‰':
+            dst = appendRune(dst, ls.styleSign(StylePermille))
+        case r=='-':
+            dst = appendRune(dst, ls.minusSign())
+        case r=='+':
+            dst = appendRune(dst, ls.plusSign())
+        case r=='¤':
+            // '¤¤' gives ISO code of currency
+            n := 1
+            for strings.HasPrefix(affix[i:], "¤") {
+                i += len("¤")
+                n++
+            }
+            switch {
+            case c==nil:
+                dst = append(dst, strings.Repeat("¤", n)...)
+            case n>1:
+                dst = append(dst, c.Code...)
+            default:
+                dst = append(dst, currencySymbol(c, lang, CurrencyDisplaySymbol)...)
+            }
+        default:
+            dst = appendRune(dst, r)
+        }
+    }
+    return string(dst)
+}
+
+// create formatter that uses decimal pattern with symbols of locale (English
+// if lang is empty). Currency sign in pattern is replaced by symbol of
+// currency (if currency is empty then currency sign is kept).
+func NewDecimalFormat(p *DecimalPattern, lang, currency string) (*DecimalFormat,
+                    error) {
+    f := &DecimalFormat{ pattern: *p, loc: englishLocFmt }
+    if lang!="" { f.loc = getLocFmt(lang) }
+    var c *Currency
+    if currency!="" {
+        var err error
+        if c, err = LookupCurrency(currency); err!=nil { return nil, err }
+    }
+    ls := &f.loc.Symbols
+    f.posPrefix = expandAffix(p.PositivePrefix, ls, c, lang)
+    f.posSuffix = expandAffix(p.PositiveSuffix, ls, c, lang)
+    f.negPrefix = expandAffix(p.NegativePrefix, ls, c, lang)
+    f.negSuffix = expandAffix(p.NegativeSuffix, ls, c, lang)
+    return f, nil
+}
+
+// compile ICU decimal format pattern and create formatter
+func CompileDecimalPattern(pattern, lang, currency string) (*DecimalFormat, error) {
+    p, err := ParseDecimalPattern(pattern)
+    if err!=nil { return nil, err }
+    return NewDecimalFormat(p, lang, currency)
+}
+
+// return configuration of formatter
+func (f *DecimalFormat) Pattern() DecimalPattern {
+    return f.pattern
+}
+
+// return true if quotient should be incremented while rounding.
+// r is remainder of division by d, sticky is true if lower cut off
+// digits are not zero. d can be any (not only power of 10).
+func roundQuotient(q goint128.UInt128, r, d uint64, sticky bool,
+                    mode RoundingMode) bool {
+    switch mode {
+    case RoundUp:
+        return r!=0 || sticky
+    case RoundHalfUp:
+        return r>=d-r && r!=0
+    case RoundHalfDown:
+        return r>d-r || (r==d-r && sticky)
+    case RoundHalfEven:
+        return r>d-r || (r==d-r && (sticky || q[0]&1!=0))
+    }
+    return false
+}
+
+// divide integer given as ASCII digits by d. Return quotient
+// (without leading zeroes, empty if zero) and remainder
+func divDigits64(digits []byte, d uint64) ([]byte, uint64) {
+    q := make([]byte, 0, len(digits))
+    var r uint64
+    for _, c := range digits {
+        hi, lo := bits.Mul64(r, 10)
+        lo, carry := bits.Add64(lo, uint64(c-'0'), 0)
+        var qd uint64
+        qd, r = bits.Div64(hi+carry, lo, d)
+        if len(q)!=0 || qd!=0 { q = append(q, byte('0'+qd)) }
+    }
+    return q, r
+}
+
+// multiply integer given as ASCII digits by m and add c
+func mulAddDigits64(digits []byte, m, c uint64) []byte {
+    out := make([]byte, len(digits)+20)
+    i := len(out)
+    carry := c
+    for k := len(digits)-1; k>=0; k-- {
+        hi, lo := bits.Mul64(uint64(digits[k]-'0'), m)
+        lo, cc := bits.Add64(lo, carry, 0)
+        var d uint64
+        carry, d = bits.Div64(hi+cc, lo, 10)
+        i--
+        out[i] = byte('0'+d)
+    }
+    for ; carry!=0; carry /= 10 {
+        i--
+        out[i] = byte('0'+carry%10)
+    }
+    for i<len(out)-1 && out[i]=='0' { i++ }
+    return out[i:]
+}
+
+// return displayed number (as ASCII digits) in units of last digit
+// of fraction rounded to multiple of increment
+func (f *DecimalFormat) scale(a UDec128, precision uint) []byte {
+    p := &f.pattern
+    inc := p.Increment
+    if inc==0 { inc = 1 }
+    e := p.Style.shift()-int(precision)+p.MaxFracDigits
+    if e>=0 {
+        // number is multiplied, so work on digits to avoid overflow
+        digits := append(udec128Digits(a), strings.Repeat("0", e)...)
+        if inc==1 { return digits }
+        q, r := divDigits64(digits, inc)
+        var parity uint64
+        if len(q)!=0 { parity = uint64(q[len(q)-1]-'0')&1 }
+        var add uint64
+        if roundQuotient(goint128.UInt128{ parity, 0 }, r, inc, false, p.Rounding) {
+            add = inc
+        }
+        return mulAddDigits64(q, inc, add)
+    }
+    v := goint128.UInt128(a)
+    sticky := false
+    d := inc
+    for ; e<0; e++ {
+        if d>(^uint64(0))/10 {
+            // divisor too big, reduce number
+            var r uint64
+            v, r = v.Div64(10)
+            if r!=0 { sticky = true }
+            continue
+        }
+        d *= 10
+    }
+    q, r := v.Div64(d)
+    if roundQuotient(q, r, d, sticky, p.Rounding) { q = q.Add64(1) }
+    hi, lo := q.MulFull(goint128.UInt128{ inc, 0 })
+    if hi.IsZero() { return udec128Digits(UDec128(lo)) }
+    return mulAddDigits64(udec128Digits(UDec128(q)), inc, 0)
+}
+
+// append number in ASCII formatted by pattern (without affixes)
+func (f *DecimalFormat) appendNumber(dst []byte, a UDec128, precision uint) []byte {
+    p := &f.pattern
+    digits := f.scale(a, precision)
+    if len(digits)<=p.MaxFracDigits {
+        digits = append([]byte(strings.Repeat("0", p.MaxFracDigits+1-len(digits))),
+                         digits...)
+    }
+    intPart := digits[:len(digits)-p.MaxFracDigits]
+    frac := digits[len(intPart):]
+    if p.MaxIntDigits>0 && len(intPart)>p.MaxIntDigits {
+        intPart = intPart[len(intPart)-p.MaxIntDigits:]
+    }
+    for len(intPart)>0 && intPart[0]=='0' { intPart = intPart[1:] }
+    for len(frac)>p.MinFracDigits && frac[len(frac)-1]=='0' { frac = frac[:len(frac)-1] }
+    for i := len(intPart); i < p.MinIntDigits; i++ { dst = append(dst, '0') }
+    dst = append(dst, intPart...)
+    if len(frac)!=0 {
+        dst = append(dst, '.')
+        dst = append(dst, frac...)
+    } else if len(intPart)==0 && p.MinIntDigits==0 {
+        // at least one digit
+        dst = append(dst, '0')
+    }
+    return dst
+}
+
+// append number formatted by pattern to dst and return extended buffer.
+// a is magnitude of number and negative is set if number is negative.
+func (f *DecimalFormat) AppendFormat(dst []byte, a UDec128, precision uint,
+                    negative bool) []byte {
+    var buf [64]byte
+    s := f.appendNumber(buf[:0], a, precision)
+    prefix, suffix := f.posPrefix, f.posSuffix
+    if negative && !a.IsZero() { prefix, suffix = f.negPrefix, f.negSuffix }
+    dst = append(dst, prefix...)
+    dst = appendLocalized(dst, f.loc, f.pattern.Grouping, FracGrouping{}, s)
+    return append(dst, suffix...)
+}
+
+// format number by pattern
+func (f *DecimalFormat) Format(a UDec128, precision uint, negative bool) string {
+    var buf [128]byte
+    return string(f.AppendFormat(buf[:0], a, precision, negative))
+}
+
+// strip prefix and suffix (spaces around them are ignored)
+func stripAffixes(str, prefix, suffix string) (string, bool) {
+    prefix = strings.TrimFunc(prefix, isSpaceRune)
+    suffix = strings.TrimFunc(suffix, isSpaceRune)
+    if !strings.HasPrefix(str, prefix) || !strings.HasSuffix(str[len(prefix):], suffix) {
+        return str, false
+    }
+    return strings.TrimFunc(str[len(prefix):len(str)-len(suffix)], isSpaceRune), true
+}
+
+// parse number formatted by pattern. Return value, true if number
+// is negative and error (nil if no error)
+func (f *DecimalFormat) Parse(str string, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    str = strings.TrimFunc(str, isSpaceRune)
+    negAffixes := f.negPrefix!=f.posPrefix || f.negSuffix!=f.posSuffix
+    var rangeErr error
+    for _, negative := range []bool{ true, false } {
+        if negative && !negAffixes { continue }
+        prefix, suffix := f.posPrefix, f.posSuffix
+        if negative { prefix, suffix = f.negPrefix, f.negSuffix }
+        s, ok := stripAffixes(str, prefix, suffix)
+        if !ok || s=="" { continue }
+        v, neg, err := localeParseUDec128Style(f.loc, f.pattern.Grouping,
+                    FracGrouping{}, s, precision, rounding, f.pattern.Style, false)
+        if err==nil && !neg { return v, negative, nil }
+        if err==strconv.ErrRange { rangeErr = err }
+    }
+    if rangeErr!=nil { return UDec128{}, false, rangeErr }
+    return UDec128{}, false, strconv.ErrSyntax
+}
+
+// parse number formatted by pattern from bytes
+func (f *DecimalFormat) ParseBytes(str []byte, precision uint,
+                    rounding bool) (UDec128, bool, error) {
+    return f.Parse(string(str), precision, rounding)
+}
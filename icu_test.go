@@ -0,0 +1,229 @@
+/*
+ * icu_test.go - tests for ICU decimal format patterns
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type DecimalPatternTC struct {
+    pattern string
+    expected DecimalPattern
+}
+
+func TestParseDecimalPattern(t *testing.T) {
+    testCases := []DecimalPatternTC {
+        DecimalPatternTC{ "#,##0.00 ¤", DecimalPattern{ MinIntDigits: 1,
+                MinFracDigits: 2, MaxFracDigits: 2, Grouping: GroupingThousands,
+                PositiveSuffix: " ¤", NegativePrefix: "-", NegativeSuffix: " ¤",
+                Rounding: RoundHalfEven } },
+        DecimalPatternTC{ "#,##,##0.###", DecimalPattern{ MinIntDigits: 1,
+                MaxFracDigits: 3, Grouping: GroupingIndian,
+                NegativePrefix: "-", Rounding: RoundHalfEven } },
+        DecimalPatternTC{ "0.00%", DecimalPattern{ MinIntDigits: 1,
+                MinFracDigits: 2, MaxFracDigits: 2, PositiveSuffix: "%",
+                NegativePrefix: "-", NegativeSuffix: "%", Style: StylePercent,
+                Rounding: RoundHalfEven } },
+        DecimalPatternTC{ "#,##0.05;(#)", DecimalPattern{ MinIntDigits: 1,
+                MinFracDigits: 2, MaxFracDigits: 2, Grouping: GroupingThousands,
+                NegativePrefix: "(", NegativeSuffix: ")", Increment: 5,
+                Rounding: RoundHalfEven } },
+        DecimalPatternTC{ "'#'000", DecimalPattern{ MinIntDigits: 3,
+                PositivePrefix: "'#'", NegativePrefix: "-'#'", Rounding: RoundHalfEven } },
+    }
+    for i, tc := range testCases {
+        p, err := ParseDecimalPattern(tc.pattern)
+        if err!=nil {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+            continue
+        }
+        if tc.expected!=*p {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, *p)
+        }
+    }
+}
+
+func TestParseDecimalPatternInvalid(t *testing.T) {
+    testCases := []string{ "", "abc", "0.0.0", "0#", "#.#0", "#,##0,", ",##0",
+            "#,,##0", "0 a 0", "'abc0", "0.00E0", "@@", "*x#0", "0.0,0" }
+    for i, tc := range testCases {
+        if _, err := ParseDecimalPattern(tc); err!=ErrInvalidPattern {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+        }
+    }
+}
+
+type UDec128DecimalFormatTC struct {
+    pattern string
+    lang string
+    currency string
+    value UDec128
+    precision uint
+    negative bool
+    expected string
+}
+
+func TestDecimalFormat(t *testing.T) {
+    testCases := []UDec128DecimalFormatTC {
+        UDec128DecimalFormatTC{ "#,##0.00 ¤", "de", "EUR", UDec128{ 123450, 0 }, 2,
+                    false, "1.234,50 €" },
+        UDec128DecimalFormatTC{ "#,##0.00 ¤", "de", "EUR", UDec128{ 123450, 0 }, 2,
+                    true, "-1.234,50 €" },
+        UDec128DecimalFormatTC{ "#,##0 ¤¤", "", "USD", UDec128{ 12345, 0 }, 1,
+                    false, "1,234 USD" },
+        UDec128DecimalFormatTC{ "¤#,##0.00", "", "", UDec128{ 5, 0 }, 0,
+                    false, "¤5.00" },
+        UDec128DecimalFormatTC{ "#,##,##0.###", "en", "", UDec128{ 12345678915, 0 }, 4,
+                    false, "12,34,567.892" },
+        UDec128DecimalFormatTC{ "#,##,##0.###", "en", "", UDec128{ 12345678000, 0 }, 4,
+                    false, "12,34,567.8" },
+        UDec128DecimalFormatTC{ "0.00%", "", "", UDec128{ 12345, 0 }, 5,
+                    false, "12.34%" },
+        UDec128DecimalFormatTC{ "0.00%", "", "", UDec128{ 12355, 0 }, 5,
+                    false, "12.36%" },
+        UDec128DecimalFormatTC{ "#,##0%", "", "", UDec128{ 125, 0 }, 1,
+                    false, "1,250%" },
+        UDec128DecimalFormatTC{ "#,##0.##‰", "", "", UDec128{ 125, 0 }, 4,
+                    false, "12.5‰" },
+        UDec128DecimalFormatTC{ "#,##0.05", "", "", UDec128{ 123, 0 }, 2,
+                    false, "1.25" },
+        UDec128DecimalFormatTC{ "#,##0.05", "", "", UDec128{ 1212, 0 }, 3,
+                    false, "1.20" },
+        UDec128DecimalFormatTC{ "#,##0.25", "", "", UDec128{ 1375, 0 }, 3,
+                    false, "1.50" },
+        UDec128DecimalFormatTC{ "#,##0.00;(#,##0.00)", "", "", UDec128{ 123450, 0 }, 2,
+                    true, "(1,234.50)" },
+        UDec128DecimalFormatTC{ "#,##0.00;(#,##0.00)", "", "", UDec128{ 0, 0 }, 2,
+                    true, "0.00" },
+        UDec128DecimalFormatTC{ "00.###", "", "", UDec128{ 5, 0 }, 0, false, "05" },
+        UDec128DecimalFormatTC{ "#.##", "", "", UDec128{ 5, 0 }, 1, false, ".5" },
+        UDec128DecimalFormatTC{ "#.##", "", "", UDec128{ 0, 0 }, 1, false, "0" },
+        UDec128DecimalFormatTC{ "#", "", "", UDec128{ 125, 0 }, 1, false, "12" },
+        UDec128DecimalFormatTC{ "#", "", "", UDec128{ 135, 0 }, 1, false, "14" },
+        UDec128DecimalFormatTC{ "'#'0", "", "", UDec128{ 7, 0 }, 0, false, "#7" },
+        UDec128DecimalFormatTC{ "0 'o''clock'", "", "", UDec128{ 7, 0 }, 0, false,
+                    "7 o'clock" },
+        UDec128DecimalFormatTC{ "#,##0.0", "fi", "", UDec128{ 12345, 0 }, 1, true,
+                    "−1\u00a0234,5" },
+        UDec128DecimalFormatTC{ "+#,##0.0;-#,##0.0", "", "", UDec128{ 12345, 0 }, 1,
+                    false, "+1,234.5" },
+        // large numbers multiplied while formatting
+        UDec128DecimalFormatTC{ "0.00%", "", "",
+                    UDec128{ 2062198654202020340, 16263032587282566510 }, 3, false,
+                    "30000000000000000000000000000000000050.00%" },
+        UDec128DecimalFormatTC{ "0.00000", "", "",
+                    UDec128{ 18446744073709551615, 18446744073709551615 }, 2, false,
+                    "3402823669209384634633746074317682114.55000" },
+        UDec128DecimalFormatTC{ "#,##0.25", "", "", UDec128{ 13, 0 }, 1, false, "1.25" },
+        UDec128DecimalFormatTC{ "#,##0.25", "", "",
+                    UDec128{ 18446744073709551614, 18446744073709551615 }, 1, false,
+                    "34,028,236,692,093,846,346,337,460,743,176,821,145.50" },
+        UDec128DecimalFormatTC{ "#,##0.25", "", "",
+                    UDec128{ 18446744073709551613, 18446744073709551615 }, 1, false,
+                    "34,028,236,692,093,846,346,337,460,743,176,821,145.25" },
+        UDec128DecimalFormatTC{ "#,##0.25", "", "",
+                    UDec128{ 18446744073709551612, 18446744073709551615 }, 1, false,
+                    "34,028,236,692,093,846,346,337,460,743,176,821,145.25" },
+    }
+    for i, tc := range testCases {
+        f, err := CompileDecimalPattern(tc.pattern, tc.lang, tc.currency)
+        if err!=nil {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+            continue
+        }
+        result := f.Format(tc.value, tc.precision, tc.negative)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+    }
+}
+
+func TestDecimalFormatMaxIntDigits(t *testing.T) {
+    p, err := ParseDecimalPattern("00")
+    if err!=nil {
+        t.Fatalf("Error mismatch: %v", err)
+    }
+    p.MaxIntDigits = 2
+    f, _ := NewDecimalFormat(p, "", "")
+    if result := f.Format(UDec128{ 1997, 0 }, 0, false); result!="97" {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    if _, err := NewDecimalFormat(p, "", "XYZ"); err!=ErrUnknownCurrency {
+        t.Errorf("Error mismatch: %v", err)
+    }
+}
+
+type UDec128DecimalParseTC struct {
+    pattern string
+    lang string
+    currency string
+    str string
+    precision uint
+    expected UDec128
+    expectedNegative bool
+    expectedError error
+}
+
+func TestDecimalFormatParse(t *testing.T) {
+    testCases := []UDec128DecimalParseTC {
+        UDec128DecimalParseTC{ "#,##0.00 ¤", "de", "EUR", "1.234,50 €", 2,
+                    UDec128{ 123450, 0 }, false, nil },
+        UDec128DecimalParseTC{ "#,##0.00 ¤", "de", "EUR", "-1.234,50 €", 2,
+                    UDec128{ 123450, 0 }, true, nil },
+        UDec128DecimalParseTC{ "#,##0.00 ¤", "de", "EUR", "1.234,50", 2,
+                    UDec128{}, false, strconv.ErrSyntax },
+        UDec128DecimalParseTC{ "#,##0.00;(#,##0.00)", "", "", "(1,234.50)", 2,
+                    UDec128{ 123450, 0 }, true, nil },
+        UDec128DecimalParseTC{ "#,##0.00;(#,##0.00)", "", "", " 1,234.5 ", 2,
+                    UDec128{ 123450, 0 }, false, nil },
+        UDec128DecimalParseTC{ "#,##0.00;(#,##0.00)", "", "", "(-1,234.50)", 2,
+                    UDec128{}, false, strconv.ErrSyntax },
+        UDec128DecimalParseTC{ "0.00%", "", "", "12.34%", 4,
+                    UDec128{ 1234, 0 }, false, nil },
+        UDec128DecimalParseTC{ "#,##0.##‰", "", "", "12.5‰", 4,
+                    UDec128{ 125, 0 }, false, nil },
+        UDec128DecimalParseTC{ "0", "", "", "1,234", 0, UDec128{}, false, strconv.ErrSyntax },
+        UDec128DecimalParseTC{ "#,##0", "", "", "1,234", 0, UDec128{ 1234, 0 }, false, nil },
+        UDec128DecimalParseTC{ "0", "", "", "999999999999999999999999999999999999999", 0,
+                    UDec128{}, false, strconv.ErrRange },
+        UDec128DecimalParseTC{ "0.00", "", "", "34028236692093846346337460743176821146", 2,
+                    UDec128{}, false, strconv.ErrRange },
+        UDec128DecimalParseTC{ "#,##0.0", "fi", "", "−1\u00a0234,5", 1,
+                    UDec128{ 12345, 0 }, true, nil },
+    }
+    for i, tc := range testCases {
+        f, err := CompileDecimalPattern(tc.pattern, tc.lang, tc.currency)
+        if err!=nil {
+            t.Errorf("Error mismatch: %d: %v: %v", i, tc, err)
+            continue
+        }
+        result, negative, err := f.Parse(tc.str, tc.precision, false)
+        if tc.expectedError!=err || tc.expected!=result ||
+                tc.expectedNegative!=negative {
+            t.Errorf("Result mismatch: %d: %v: %v,%v,%v->%v,%v,%v", i, tc,
+                     tc.expected, tc.expectedNegative, tc.expectedError,
+                     result, negative, err)
+        }
+    }
+}
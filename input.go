@@ -0,0 +1,221 @@
+/*
+ * input.go - incremental validation of amounts typed in form fields
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strings"
+    "unicode/utf8"
+)
+
+// state of partial input
+type InputState uint8
+
+const (
+    // input can not become valid amount by typing more characters
+    InputInvalid InputState = iota
+    // input is incomplete (like '12,' or '1 23')
+    InputIntermediate
+    // input is valid amount
+    InputValid
+)
+
+// options of input validator
+type ValidatorOptions struct {
+    // language of locale. If empty then amount is typed without locale
+    Lang string
+    // maximal number of digits in fraction
+    Precision uint
+    // maximal magnitude of amount, if nil then no limit
+    Max *UDec128
+    // allow negative amounts
+    Negative bool
+}
+
+// validator of amounts typed in form fields. It is created once and
+// can be used to validate many inputs concurrently
+type InputValidator struct {
+    opts ValidatorOptions
+    loc *LocFmt
+    grouping Grouping
+}
+
+// create new input validator with options
+func NewInputValidator(opts ValidatorOptions) *InputValidator {
+    v := &InputValidator{ opts: opts, loc: plainLocFmt }
+    if opts.Lang!="" {
+        v.loc = getLocFmt(opts.Lang)
+        v.grouping = v.loc.Grouping
+    }
+    return v
+}
+
+// return options of validator
+func (v *InputValidator) Options() ValidatorOptions {
+    return v.opts
+}
+
+// scanned partial input
+type inputScan struct {
+    negative bool
+    // digits in ASCII with '.' for comma
+    digits []byte
+    // number of digits of integer part before every separator
+    seps []int
+    intDigits, fracDigits int
+    hasComma bool
+    // number of significant runes (sign, digits, comma) before cursor
+    before int
+}
+
+// return digit for rune or -1 if rune is not digit
+func (v *InputValidator) digit(r rune) int {
+    if r>='0' && r<='9' { return int(r-'0') }
+    for d, dr := range v.loc.Digits {
+        if dr==r { return d }
+    }
+    return -1
+}
+
+// return true if rune is separator of groups
+func (v *InputValidator) isSep(r rune) bool {
+    return v.grouping.Enabled() && v.loc.Sep1000!=0 &&
+            (r==v.loc.Sep1000 || r==v.loc.Sep1000_2)
+}
+
+// scan input. cursor is position in runes. Return false if input has
+// characters not allowed in amount or characters in wrong order.
+func (v *InputValidator) scan(str string, cursor int) (inputScan, bool) {
+    var sc inputScan
+    ls := &v.loc.Symbols
+    str = strings.TrimLeftFunc(str, func(r rune) bool {
+        if isSpaceRune(r) && cursor>0 { cursor-- }
+        return isSpaceRune(r)
+    })
+    str = strings.TrimRightFunc(str, func(r rune) bool {
+        return isSpaceRune(r) && !v.isSep(r)
+    })
+    pos := 0
+    lastSep := false
+    for i, r := range str {
+        significant := true
+        switch {
+        case v.digit(r)!=-1:
+            sc.digits = append(sc.digits, byte('0'+v.digit(r)))
+            if sc.hasComma {
+                sc.fracDigits++
+            } else {
+                sc.intDigits++
+            }
+            lastSep = false
+        case i==0 && (r==ls.minusSign() || r=='-' || r=='−'):
+            if !v.opts.Negative { return sc, false }
+            sc.negative = true
+        case i==0 && (r==ls.plusSign() || r=='+'):
+            // plus sign is dropped while reformatting
+            significant = false
+        case r==v.loc.Comma:
+            if sc.hasComma || lastSep || v.opts.Precision==0 { return sc, false }
+            sc.digits = append(sc.digits, '.')
+            sc.hasComma = true
+        case v.isSep(r) && !sc.hasComma:
+            // separator must be after digit
+            if lastSep || sc.intDigits==0 { return sc, false }
+            sc.seps = append(sc.seps, sc.intDigits)
+            lastSep = true
+            significant = false
+        default:
+            return sc, false
+        }
+        if significant && pos<cursor { sc.before++ }
+        pos++
+    }
+    return sc, true
+}
+
+// check groups of integer part. Return InputIntermediate if last group
+// is incomplete
+func (v *InputValidator) checkGroups(sc *inputScan) InputState {
+    if len(sc.seps)==0 { return InputValid }
+    g := v.grouping
+    primary, secondary := int(g.Primary), g.secondary()
+    if sc.seps[0]>secondary { return InputInvalid }
+    for i := 1; i < len(sc.seps); i++ {
+        if sc.seps[i]-sc.seps[i-1]!=secondary { return InputInvalid }
+    }
+    last := sc.intDigits-sc.seps[len(sc.seps)-1]
+    switch {
+    case last>primary:
+        return InputInvalid
+    case last<primary:
+        // digits of last group are being typed
+        if sc.hasComma { return InputInvalid }
+        return InputIntermediate
+    }
+    return InputValid
+}
+
+// classify partial input as valid, intermediate or invalid amount.
+// Input is intermediate if it can become valid by typing more characters
+// (like '12,', '1 23' or '-'). Input is invalid if it has more digits
+// in fraction than precision or its magnitude (or magnitude of smallest
+// completion of last group) exceeds maximal value.
+func (v *InputValidator) Validate(str string) InputState {
+    sc, ok := v.scan(str, 0)
+    if !ok || sc.fracDigits>int(v.opts.Precision) { return InputInvalid }
+    state := v.checkGroups(&sc)
+    if state==InputInvalid { return InputInvalid }
+    if sc.intDigits+sc.fracDigits==0 { return InputIntermediate }
+    digits := sc.digits
+    if state==InputIntermediate {
+        // compare smallest completion (last group padded with zeroes)
+        last := sc.intDigits-sc.seps[len(sc.seps)-1]
+        for ; last<int(v.grouping.Primary); last++ { digits = append(digits, '0') }
+    }
+    value, err := ParseUDec128Bytes(digits, v.opts.Precision, false)
+    if err!=nil || (v.opts.Max!=nil && value.Cmp(*v.opts.Max)>0) {
+        return InputInvalid
+    }
+    if sc.hasComma && sc.fracDigits==0 { return InputIntermediate }
+    return state
+}
+
+// reformat input with separators of groups placed by grouping of locale.
+// cursor is position in runes, returned cursor is at same position
+// between digits. Invalid input is returned unchanged.
+func (v *InputValidator) Reformat(str string, cursor int) (string, int) {
+    sc, ok := v.scan(str, cursor)
+    if !ok { return str, cursor }
+    var buf [128]byte
+    out := buf[:0]
+    if sc.negative { out = appendRune(out, v.loc.Symbols.minusSign()) }
+    out = appendLocalized(out, v.loc, v.grouping, FracGrouping{}, sc.digits)
+    // put cursor after same number of significant runes
+    newCursor, count := 0, 0
+    for i := 0; i < len(out) && count<sc.before; {
+        r, size := utf8.DecodeRune(out[i:])
+        if !v.isSep(r) { count++ }
+        i += size
+        newCursor++
+    }
+    return string(out), newCursor
+}
@@ -0,0 +1,154 @@
+/*
+ * input_test.go - tests for incremental validation of amounts
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "testing"
+)
+
+type InputValidateTC struct {
+    lang string
+    str string
+    expected InputState
+}
+
+func TestInputValidatorValidate(t *testing.T) {
+    max := UDec128{ 100000000, 0 }
+    testCases := []InputValidateTC {
+        InputValidateTC{ "en", "", InputIntermediate },
+        InputValidateTC{ "en", "-", InputIntermediate },
+        InputValidateTC{ "en", "+", InputIntermediate },
+        InputValidateTC{ "en", "1", InputValid },
+        InputValidateTC{ "en", " 12 ", InputValid },
+        InputValidateTC{ "en", "12.", InputIntermediate },
+        InputValidateTC{ "en", ".", InputIntermediate },
+        InputValidateTC{ "en", ".5", InputValid },
+        InputValidateTC{ "en", "0.0", InputValid },
+        InputValidateTC{ "en", "12.34", InputValid },
+        InputValidateTC{ "en", "12.345", InputInvalid },
+        InputValidateTC{ "en", "12.3.", InputInvalid },
+        InputValidateTC{ "en", "1,", InputIntermediate },
+        InputValidateTC{ "en", "1,23", InputIntermediate },
+        InputValidateTC{ "en", "1,234", InputValid },
+        InputValidateTC{ "en", "1,2345", InputInvalid },
+        InputValidateTC{ "en", "1,23.5", InputInvalid },
+        InputValidateTC{ "en", "1234,567", InputInvalid },
+        InputValidateTC{ "en", "1,,234", InputInvalid },
+        InputValidateTC{ "en", ",234", InputInvalid },
+        InputValidateTC{ "en", "1,000,000", InputValid },
+        InputValidateTC{ "en", "1,000,001", InputInvalid },
+        InputValidateTC{ "en", "1,000,0", InputIntermediate },
+        InputValidateTC{ "en", "-12.5", InputValid },
+        InputValidateTC{ "en", "12-", InputInvalid },
+        InputValidateTC{ "en", "12a", InputInvalid },
+        InputValidateTC{ "de", "12,", InputIntermediate },
+        InputValidateTC{ "de", "0.0", InputIntermediate },
+        InputValidateTC{ "de", "1.234,56", InputValid },
+        InputValidateTC{ "fr", "1 23", InputIntermediate },
+        InputValidateTC{ "fr", "1\u00a0234,5", InputValid },
+        InputValidateTC{ "fr", "1 ", InputIntermediate },
+        InputValidateTC{ "hi", "1,23,4", InputIntermediate },
+        InputValidateTC{ "hi", "12,34,5", InputInvalid },
+        InputValidateTC{ "hi", "1,23,456", InputValid },
+        InputValidateTC{ "hi", "1,234,567", InputInvalid },
+        InputValidateTC{ "", "1234.5", InputValid },
+        InputValidateTC{ "", "1,234", InputInvalid },
+    }
+    for i, tc := range testCases {
+        v := NewInputValidator(ValidatorOptions{ Lang: tc.lang, Precision: 2,
+                        Max: &max, Negative: true })
+        result := v.Validate(tc.str)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: %v: %v->%v", i, tc, tc.expected, result)
+        }
+    }
+}
+
+func TestInputValidatorOptions(t *testing.T) {
+    v := NewInputValidator(ValidatorOptions{ Lang: "en", Precision: 0 })
+    if result := v.Validate("12.5"); result!=InputInvalid {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    if result := v.Validate("-12"); result!=InputInvalid {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    if result := v.Validate("123456789012345678901234567890"); result!=InputValid {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    max := UDec128{ 1000, 0 }
+    v = NewInputValidator(ValidatorOptions{ Lang: "en", Precision: 2, Max: &max })
+    if result := v.Validate("10."); result!=InputIntermediate {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    if result := v.Validate("10.01"); result!=InputInvalid {
+        t.Errorf("Result mismatch: %v", result)
+    }
+    // incomplete group can not be completed below maximal value
+    max = UDec128{ 10000, 0 }
+    v = NewInputValidator(ValidatorOptions{ Lang: "en", Precision: 2, Max: &max })
+    for _, str := range []string{ "1,0", "1," } {
+        if result := v.Validate(str); result!=InputInvalid {
+            t.Errorf("Result mismatch: %v: %v", str, result)
+        }
+    }
+    if result := v.Validate("10"); result!=InputValid {
+        t.Errorf("Result mismatch: %v", result)
+    }
+}
+
+type InputReformatTC struct {
+    lang string
+    str string
+    cursor int
+    expected string
+    expectedCursor int
+}
+
+func TestInputValidatorReformat(t *testing.T) {
+    testCases := []InputReformatTC {
+        InputReformatTC{ "en", "1234", 4, "1,234", 5 },
+        InputReformatTC{ "en", "1234", 1, "1,234", 1 },
+        InputReformatTC{ "en", "1234", 2, "1,234", 3 },
+        InputReformatTC{ "en", "1,2345", 6, "12,345", 6 },
+        InputReformatTC{ "en", "1,2345", 2, "12,345", 1 },
+        InputReformatTC{ "en", "-1234567.5", 8, "-1,234,567.5", 10 },
+        InputReformatTC{ "en", "+1234", 5, "1,234", 5 },
+        InputReformatTC{ "en", "  1234", 6, "1,234", 5 },
+        InputReformatTC{ "en", "12.", 3, "12.", 3 },
+        InputReformatTC{ "en", "1,234", 0, "1,234", 0 },
+        InputReformatTC{ "en", "12a", 3, "12a", 3 },
+        InputReformatTC{ "de", "1234,5", 6, "1.234,5", 7 },
+        InputReformatTC{ "fr", "1 23", 4, "123", 3 },
+        InputReformatTC{ "fr", "12345", 5, "12\u00a0345", 6 },
+        InputReformatTC{ "hi", "1234567", 7, "12,34,567", 9 },
+    }
+    for i, tc := range testCases {
+        v := NewInputValidator(ValidatorOptions{ Lang: tc.lang, Precision: 2,
+                        Negative: true })
+        result, cursor := v.Reformat(tc.str, tc.cursor)
+        if tc.expected!=result || tc.expectedCursor!=cursor {
+            t.Errorf("Result mismatch: %d: %v: %v,%v->%v,%v", i, tc, tc.expected,
+                     tc.expectedCursor, result, cursor)
+        }
+    }
+}
@@ -0,0 +1,131 @@
+/*
+ * langtag.go - BCP 47 language tags
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strings"
+)
+
+// parsed language tag (only parts used to choose number format)
+type langTag struct {
+    lang, script, region string
+    // numbering system from -u-nu- extension
+    numbering string
+}
+
+func isAlphaStr(s string) bool {
+    for i := 0; i < len(s); i++ {
+        c := s[i]|0x20
+        if c<'a' || c>'z' { return false }
+    }
+    return true
+}
+
+func isDigitStr(s string) bool {
+    for i := 0; i < len(s); i++ {
+        if s[i]<'0' || s[i]>'9' { return false }
+    }
+    return true
+}
+
+func isAlnumStr(s string) bool {
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        if !((c>='0' && c<='9') || (c|0x20>='a' && c|0x20<='z')) { return false }
+    }
+    return true
+}
+
+// parse BCP 47 language tag or POSIX locale name (lang_REGION.encoding@modifier).
+// return false if tag is malformed.
+func parseLangTag(tag string) (langTag, bool) {
+    var t langTag
+    if i := strings.IndexAny(tag, ".@"); i!=-1 {
+        tag = tag[:i]
+    }
+    if tag=="" || tag=="C" || tag=="POSIX" {
+        t.lang = "C"
+        return t, true
+    }
+    parts := strings.FieldsFunc(tag, func(r rune) bool { return r=='-' || r=='_' })
+    if len(parts)==0 { return t, false }
+    if len(parts[0])<2 || len(parts[0])>8 || !isAlphaStr(parts[0]) {
+        return t, false
+    }
+    t.lang = strings.ToLower(parts[0])
+    i := 1
+    // extended language subtags are skipped
+    for ; i<len(parts) && i<4 && len(parts[i])==3 && isAlphaStr(parts[i]); i++ { }
+    if i<len(parts) && len(parts[i])==4 && isAlphaStr(parts[i]) {
+        t.script = strings.ToUpper(parts[i][:1])+strings.ToLower(parts[i][1:])
+        i++
+    }
+    if i<len(parts) && ((len(parts[i])==2 && isAlphaStr(parts[i])) ||
+                        (len(parts[i])==3 && isDigitStr(parts[i]))) {
+        t.region = strings.ToUpper(parts[i])
+        i++
+    }
+    // variants
+    for ; i<len(parts) && len(parts[i])>=4 && len(parts[i])<=8 &&
+                isAlnumStr(parts[i]); i++ { }
+    // extensions
+    for i<len(parts) {
+        if len(parts[i])!=1 || !isAlnumStr(parts[i]) { return t, false }
+        singleton := strings.ToLower(parts[i])
+        i++
+        if singleton=="x" { break } // private use to end
+        start := i
+        for ; i<len(parts) && len(parts[i])>=2 && len(parts[i])<=8 &&
+                isAlnumStr(parts[i]); i++ {
+            if singleton=="u" && strings.ToLower(parts[i])=="nu" && i+1<len(parts) &&
+                    len(parts[i+1])>=3 {
+                t.numbering = strings.ToLower(parts[i+1])
+            }
+        }
+        if start==i { return t, false } // empty extension
+    }
+    return t, true
+}
+
+// return canonical name of tag without extensions
+func (t langTag) String() string {
+    s := t.lang
+    if t.script!="" { s += "-"+t.script }
+    if t.region!="" { s += "-"+t.region }
+    return s
+}
+
+// return names of locales to try, from most specific to most general
+func (t langTag) fallbacks() []string {
+    names := make([]string, 0, 4)
+    if t.script!="" && t.region!="" {
+        names = append(names, t.lang+"-"+t.script+"-"+t.region)
+    }
+    if t.region!="" {
+        names = append(names, t.lang+"-"+t.region)
+    }
+    if t.script!="" {
+        names = append(names, t.lang+"-"+t.script)
+    }
+    return append(names, t.lang)
+}
@@ -0,0 +1,72 @@
+/*
+ * langtag_test.go - tests for BCP 47 language tags
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "reflect"
+    "testing"
+)
+
+type LangTagTC struct {
+    tag string
+    expected langTag
+    expOk bool
+    expFallbacks []string
+}
+
+func TestParseLangTag(t *testing.T) {
+    testCases := []LangTagTC {
+        LangTagTC{ "", langTag{ lang: "C" }, true, []string{ "C" } },
+        LangTagTC{ "POSIX", langTag{ lang: "C" }, true, []string{ "C" } },
+        LangTagTC{ "pl", langTag{ lang: "pl" }, true, []string{ "pl" } },
+        LangTagTC{ "pl_PL.UTF-8", langTag{ lang: "pl", region: "PL" }, true,
+            []string{ "pl-PL", "pl" } },
+        LangTagTC{ "de-ch", langTag{ lang: "de", region: "CH" }, true,
+            []string{ "de-CH", "de" } },
+        LangTagTC{ "zh-hant-tw", langTag{ lang: "zh", script: "Hant", region: "TW" },
+            true, []string{ "zh-Hant-TW", "zh-TW", "zh-Hant", "zh" } },
+        LangTagTC{ "sr-Latn", langTag{ lang: "sr", script: "Latn" }, true,
+            []string{ "sr-Latn", "sr" } },
+        LangTagTC{ "es-419", langTag{ lang: "es", region: "419" }, true,
+            []string{ "es-419", "es" } },
+        LangTagTC{ "ar-EG-u-nu-latn", langTag{ lang: "ar", region: "EG",
+            numbering: "latn" }, true, []string{ "ar-EG", "ar" } },
+        LangTagTC{ "hi-IN-u-ca-gregory-nu-deva-x-priv", langTag{ lang: "hi",
+            region: "IN", numbering: "deva" }, true, []string{ "hi-IN", "hi" } },
+        LangTagTC{ "de-CH-1996", langTag{ lang: "de", region: "CH" }, true,
+            []string{ "de-CH", "de" } },
+        LangTagTC{ "1x", langTag{}, false, nil },
+        LangTagTC{ "en-u", langTag{ lang: "en" }, false, nil },
+        LangTagTC{ "en-US-$", langTag{ lang: "en", region: "US" }, false, nil },
+    }
+    for i, tc := range testCases {
+        result, ok := parseLangTag(tc.tag)
+        if tc.expOk!=ok || (ok && tc.expected!=result) {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v!=%v,%v",
+                     i, tc.tag, tc.expected, tc.expOk, result, ok)
+        }
+        if ok && !reflect.DeepEqual(tc.expFallbacks, result.fallbacks()) {
+            t.Errorf("Fallbacks mismatch: %d: %v: %v!=%v",
+                     i, tc.tag, tc.expFallbacks, result.fallbacks())
+        }
+    }
+}
@@ -24,74 +24,71 @@ package godec128
 
 import (
     "bytes"
-    "strings"
     "strconv"
     "unicode/utf8"
-    "github.com/matszpk/goint128"
 )
 
-// format 128-bit decimal fixed point including locale
-func (a UDec128) LocaleFormatNewBytes(lang string, precision, displayPrecision uint,
-                                trimZeroes, noSep1000 bool) []byte {
-    l := goint128.GetLocFmt(lang)
-    s := a.FormatNewBytes(precision, displayPrecision, trimZeroes)
+// append rune in UTF-8 encoding to buffer
+func appendRune(dst []byte, r rune) []byte {
+    if r < utf8.RuneSelf { return append(dst, byte(r)) }
+    var rbuf [utf8.UTFMax]byte
+    n := utf8.EncodeRune(rbuf[:], r)
+    return append(dst, rbuf[:n]...)
+}
+
+// append formatted 128-bit decimal fixed point including locale to dst
+// and return extended buffer. Does not allocate if dst has enough capacity.
+func (a UDec128) AppendLocaleFormat(dst []byte, lang string,
+                    precision, displayPrecision uint,
+                    trimZeroes, noSep1000 bool) []byte {
+    var buf [64]byte
+    s := a.AppendFormat(buf[:0], precision, displayPrecision, trimZeroes)
+    l := getLocFmt(lang)
+    return appendLocalized(dst, l, localeGrouping(l, noSep1000), l.FracGrouping, s)
+}
+
+// return grouping of locale or no grouping if noSep1000 is set
+func localeGrouping(l *LocFmt, noSep1000 bool) Grouping {
+    if noSep1000 { return GroupingNone }
+    return l.Grouping
+}
+
+// append number formatted by AppendFormat to dst with replaced digits, comma
+// and with separators placed by grouping of integer part and fractional part
+func appendLocalized(dst []byte, l *LocFmt, g Grouping, fg FracGrouping,
+                     s []byte) []byte {
     slen := len(s)
-    os := make([]byte, slen<<1) // optimization
-    oslen := 0
     commaIdx := bytes.LastIndexByte(s, '.')
     if commaIdx==-1 {
         commaIdx = slen
     }
-    ti := commaIdx
-    i := commaIdx
-    if !l.Sep100and1000 {
-        ti = (commaIdx)%3
-        if ti==0 { ti=3 }
-    }
+    grouped := l.Sep1000!=0 && g.applies(commaIdx)
     for k:=0; k < commaIdx; k++ {
-        r := s[k]
-        if r>='0' && r<='9' {
-            if oslen+4 >= len(os) {
-                os = append(os, 0,0,0,0)
-            }
-            oslen += utf8.EncodeRune(os[oslen:], l.Digits[r-'0'])
+        dst = appendRune(dst, l.Digits[s[k]-'0'])
+        if grouped && k+1<commaIdx && g.isBoundary(commaIdx-k-1) {
+            dst = appendRune(dst, l.Sep1000)
         }
-        if !noSep1000 && i!=1 {
-            if !l.Sep100and1000 || ti<=3 {
-                ti--
-                if ti==0 {
-                    if oslen+4 >= len(os) {
-                        os = append(os, 0,0,0,0)
-                    }
-                    oslen += utf8.EncodeRune(os[oslen:], l.Sep1000)
-                    ti = 3
-                }
-            } else {
-                ti--
-                if (ti-3)&1==0 {
-                    if oslen+4 >= len(os) {
-                        os = append(os, 0,0,0,0)
-                    }
-                    oslen += utf8.EncodeRune(os[oslen:], l.Sep1000)
-                }
-            }
-        }
-        i--
     }
     // comma
     if commaIdx!=slen {
-        if oslen+4 >= len(os) {
-            os = append(os, 0,0,0,0)
-        }
-        oslen += utf8.EncodeRune(os[oslen:], l.Comma)
-        for i = commaIdx+1; i < slen; i++ {
-            if oslen+4 >= len(os) {
-                os = append(os, 0,0,0,0)
+        dst = appendRune(dst, l.Comma)
+        for i := commaIdx+1; i < slen; i++ {
+            if fg.Size!=0 && i!=commaIdx+1 && (i-commaIdx-1)%int(fg.Size)==0 {
+                dst = appendRune(dst, fg.sep())
             }
-            oslen += utf8.EncodeRune(os[oslen:], l.Digits[s[i]-'0'])
+            dst = appendRune(dst, l.Digits[s[i]-'0'])
         }
     }
-    return os[:oslen]
+    return dst
+}
+
+// format 128-bit decimal fixed point including locale
+func (a UDec128) LocaleFormatNewBytes(lang string, precision, displayPrecision uint,
+                                trimZeroes, noSep1000 bool) []byte {
+    var buf [128]byte
+    s := a.AppendLocaleFormat(buf[:0], lang, precision, displayPrecision,
+                              trimZeroes, noSep1000)
+    return append([]byte(nil), s...)
 }
 
 func (a UDec128) LocaleFormatBytes(lang string, precision uint,
@@ -102,50 +99,9 @@ func (a UDec128) LocaleFormatBytes(lang string, precision uint,
 // format 128-bit decimal fixed point including locale
 func (a UDec128) LocaleFormatNew(lang string, precision, displayPrecision uint,
                             trimZeroes, noSep1000 bool) string {
-    l := goint128.GetLocFmt(lang)
-    s := a.FormatNewBytes(precision, displayPrecision, trimZeroes)
-    var os strings.Builder
-    slen := len(s)
-    os.Grow(slen*3)
-    commaIdx := bytes.LastIndexByte(s, '.')
-    if commaIdx==-1 {
-        commaIdx = slen
-    }
-    ti := commaIdx
-    i := commaIdx
-    if !l.Sep100and1000 {
-        ti = (commaIdx)%3
-        if ti==0 { ti=3 }
-    }
-    for k:=0; k < commaIdx; k++ {
-        r := s[k]
-        if r>='0' && r<='9' {
-            os.WriteRune(l.Digits[r-'0'])
-        }
-        if !noSep1000 && i!=1 {
-            if !l.Sep100and1000 || ti<=3 {
-                ti--
-                if ti==0 {
-                    os.WriteRune(l.Sep1000)
-                    ti = 3
-                }
-            } else {
-                ti--
-                if (ti-3)&1==0 {
-                    os.WriteRune(l.Sep1000)
-                }
-            }
-        }
-        i--
-    }
-    // comma
-    if commaIdx!=slen {
-        os.WriteRune(l.Comma)
-        for i = commaIdx+1; i < slen; i++ {
-            os.WriteRune(l.Digits[s[i]-'0'])
-        }
-    }
-    return os.String()
+    var buf [128]byte
+    return string(a.AppendLocaleFormat(buf[:0], lang, precision, displayPrecision,
+                              trimZeroes, noSep1000))
 }
 
 func (a UDec128) LocaleFormat(lang string, precision uint,
@@ -155,64 +111,30 @@ func (a UDec128) LocaleFormat(lang string, precision uint,
 
 // parse decimal fixed point from string and return value and error (nil if no error)
 func LocaleParseUDec128(lang, str string, precision uint, rounding bool) (UDec128, error) {
-    l := goint128.GetLocFmt(lang)
-    if len(str)==0 { return UDec128{}, strconv.ErrSyntax }
-    
-    os := make([]byte, 0, len(str))
-    for _, r := range str {
-        if r>='0' && r<='9' {
-            // if standard digits
-            os = append(os, byte(r))
-        } else if r!=l.Sep1000 && r!=l.Sep1000_2 && r!=l.Comma {
-            // if non-standard digit
-            dig:=0
-            found := false
-            for ; dig<=9; dig++ {
-                if l.Digits[dig]==r {
-                    found = true
-                    break
-                }
-            }
-            if !found { return UDec128{}, strconv.ErrSyntax }
-            os = append(os, '0'+byte(dig))
-        } else if r==l.Comma {
-            os = append(os, '.')
-        }
-        // otherwise skip sep1000
-    }
-    return ParseUDec128Bytes(os, precision, rounding)
+    l := getLocFmt(lang)
+    return localeParseUDec128(l, l.Grouping, l.FracGrouping, str, precision, rounding)
+}
+
+func localeParseUDec128(l *LocFmt, g Grouping, fg FracGrouping, str string,
+                        precision uint, rounding bool) (UDec128, error) {
+    v, negative, err := localeParseUDec128Style(l, g, fg, str, precision, rounding,
+                                                StyleDecimal, false)
+    if err==nil && negative { return UDec128{}, strconv.ErrSyntax }
+    return v, err
 }
 
 // parse decimal fixed point from string and return value and error (nil if no error)
 func LocaleParseUDec128Bytes(lang string, strInput []byte,
                              precision uint, rounding bool) (UDec128, error) {
-    l := goint128.GetLocFmt(lang)
-    if len(strInput)==0 { return UDec128{}, strconv.ErrSyntax }
-    
-    os := make([]byte, 0, len(strInput))
-    str := strInput
-    for len(str)>0 {
-        r, size := utf8.DecodeRune(str)
-        if r>='0' && r<='9' {
-            // if standard digits
-            os = append(os, byte(r))
-        } else if r!=l.Sep1000 && r!=l.Sep1000_2 && r!=l.Comma {
-            // if non-standard digit
-            dig:=0
-            found := false
-            for ; dig<=9; dig++ {
-                if l.Digits[dig]==r {
-                    found = true
-                    break
-                }
-            }
-            if !found { return UDec128{}, strconv.ErrSyntax }
-            os = append(os, '0'+byte(dig))
-        } else if r==l.Comma {
-            os = append(os, '.')
-        }
-        // otherwise skip sep1000
-        str = str[size:]
-    }
-    return ParseUDec128Bytes(os, precision, rounding)
+    l := getLocFmt(lang)
+    return localeParseUDec128Bytes(l, l.Grouping, l.FracGrouping, strInput,
+                                   precision, rounding)
+}
+
+func localeParseUDec128Bytes(l *LocFmt, g Grouping, fg FracGrouping,
+                    strInput []byte, precision uint, rounding bool) (UDec128, error) {
+    v, negative, err := localeParseUDec128StyleBytes(l, g, fg, strInput,
+                                precision, rounding, StyleDecimal, false)
+    if err==nil && negative { return UDec128{}, strconv.ErrSyntax }
+    return v, err
 }
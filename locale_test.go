@@ -237,6 +237,33 @@ func TestUDec128LocaleFormat(t *testing.T) {
     }
 }
 
+func TestUDec128AppendLocaleFormat(t *testing.T) {
+    testCases := []UDec128LocTC {
+        UDec128LocTC{ "pl", false, UDec128{0xab54a98ceb1f0ad3, 0},
+                10, false, "1\u00a0234\u00a0567\u00a0890,1234567891" },
+        UDec128LocTC{ "ar", false, UDec128{0xab54a98ceb1f0ad3,0},
+                10, false, "١٬٢٣٤٬٥٦٧٬٨٩٠٫١٢٣٤٥٦٧٨٩١" },
+        UDec128LocTC{ "hi", false, UDec128{0xab54a98ceb1f0ad3,0},
+                10, false, "1,23,45,67,890.1234567891" },
+        UDec128LocTC{ "en", true, UDec128{0xab54a98ceb1f0ad3,0},
+                10, false, "1234567890.1234567891" },
+        UDec128LocTC{ "de", false, UDec128{0, 0}, 10, false, "0,0" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        result := tc.a.AppendLocaleFormat([]byte("xx"), tc.lang, tc.precision,
+                        tc.precision, tc.trimZeroes, tc.noSep1000)
+        if "xx"+tc.expected!=string(result) {
+            t.Errorf("Result mismatch: %d: appendFmt(%v,%s,%v,%v)->%v!=%v",
+                     i, tc.a, tc.lang, tc.precision, tc.trimZeroes, tc.expected,
+                     string(result))
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d %s: %v!=%v", i, tc.lang, a, tc.a)
+        }
+    }
+}
+
 type UDec128LocParseTC struct {
     lang string
     str string
@@ -296,3 +323,11 @@ func BenchmarkUDec128LocaleFormat(b *testing.B) {
         a.LocaleFormat("pl", 8, false, false)
     }
 }
+
+func BenchmarkUDec128AppendLocaleFormat(b *testing.B) {
+    a := UDec128{ 7341542494928938945, 938491 }
+    buf := make([]byte, 0, 128)
+    for i := 0; i < b.N; i++ {
+        buf = a.AppendLocaleFormat(buf[:0], "pl", 8, 8, false, false)
+    }
+}
@@ -0,0 +1,507 @@
+/*
+ * locreg.go - registry of locales
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "sync"
+    "github.com/matszpk/goint128"
+)
+
+// number format of locale
+type LocFmt struct {
+    // decimal separator
+    Comma rune
+    // thousand separator and alternative thousand separator accepted while parsing
+    Sep1000, Sep1000_2 rune
+    // grouping of digits of integer part
+    Grouping Grouping
+    // grouping of digits of fractional part
+    FracGrouping FracGrouping
+    // signs, percent and permille
+    Symbols LocSymbols
+    // digits from zero to nine
+    Digits []rune
+}
+
+var (
+    ErrUnknownLocale = errors.New("godec128: unknown locale")
+    ErrInvalidLocale = errors.New("godec128: invalid locale format")
+)
+
+// languages of locales built into goint128 (sorted)
+var builtinLocales []string = []string{
+    "C", "af", "am", "ar", "az", "bg", "bn", "ca", "cs", "da", "de", "el", "en",
+    "es", "et", "fa", "fi", "fil", "fr", "gu", "he", "hi", "hr", "hu", "hy", "id",
+    "is", "it", "ja", "ka", "kk", "km", "kn", "ko", "ky", "lo", "lt", "lv", "mk",
+    "ml", "mn", "mo", "mr", "ms", "mul", "my", "nb", "ne", "nl", "no", "pa", "pl",
+    "pt", "ro", "ru", "sh", "si", "sk", "sl", "sq", "sr", "sv", "sw", "ta", "te",
+    "th", "tl", "tn", "tr", "uk", "ur", "uz", "vi", "zh", "zu",
+}
+
+// create locale format with latin digits
+func latnLocFmt(comma, sep1000, sep1000_2 rune) *LocFmt {
+    return &LocFmt{ Comma: comma, Sep1000: sep1000, Sep1000_2: sep1000_2,
+            Grouping: GroupingThousands, Digits: []rune("0123456789") }
+}
+
+// built-in regional variants of locales
+var builtinRegionalLocales map[string]*LocFmt = map[string]*LocFmt{
+    "ar-DZ": latnLocFmt(',', '.', '.'),
+    "ar-MA": latnLocFmt(',', '.', '.'),
+    "ar-TN": latnLocFmt(',', '.', '.'),
+    "de-AT": latnLocFmt(',', 0xa0, ' '),
+    "de-CH": latnLocFmt('.', '’', '\''),
+    "de-LI": latnLocFmt('.', '’', '\''),
+    "en-IN": &LocFmt{ Comma: '.', Sep1000: ',', Sep1000_2: ',',
+                Grouping: GroupingIndian, Digits: []rune("0123456789") },
+    "en-ZA": latnLocFmt(',', 0xa0, ' '),
+    "es-419": latnLocFmt('.', ',', ','),
+    "es-MX": latnLocFmt('.', ',', ','),
+    "es-US": latnLocFmt('.', ',', ','),
+    "fr-CA": latnLocFmt(',', 0xa0, ' '),
+    "fr-CH": latnLocFmt(',', 0x202f, ' '),
+    "it-CH": latnLocFmt('.', '’', '\''),
+    "pt-PT": &LocFmt{ Comma: ',', Sep1000: 0xa0, Sep1000_2: ' ',
+                Grouping: Grouping{ 3, 3, 2 }, Digits: []rune("0123456789") },
+}
+
+func init() {
+    // regional variants use symbols of language
+    for lang, l := range builtinRegionalLocales {
+        l = copyLocFmt(l)
+        l.Symbols = builtinLocaleSymbols[lang[:strings.IndexByte(lang, '-')]]
+        if p, ok := builtinRegionalCurrencyPatterns[lang]; ok {
+            l.Symbols.CurrencyPattern = p
+        }
+        builtinRegionalLocales[lang] = l
+    }
+}
+
+// return copy of locale format with own digits
+func copyLocFmt(l *LocFmt) *LocFmt {
+    c := *l
+    c.Digits = append([]rune(nil), l.Digits...)
+    return &c
+}
+
+// built-in locales that require two digits in first group (CLDR minimumGroupingDigits)
+var builtinMinGrouping2 map[string]bool = map[string]bool{
+    "es": true, "pl": true, "lv": true,
+}
+
+// result of matching locale to language tag
+type LocaleMatch struct {
+    // canonical form of requested language tag
+    Requested string
+    // name of chosen locale
+    Locale string
+    // numbering system given by -u-nu- extension (empty if not applied)
+    NumberingSystem string
+    // number format of chosen locale with digits of numbering system
+    Format *LocFmt
+}
+
+// maximal number of cached results of matching
+const maxResolvedLocales = 1024
+
+type locRegistry struct {
+    sync.RWMutex
+    // registered locales
+    custom map[string]*LocFmt
+    // converted built-in locales
+    builtin map[string]*LocFmt
+    // cached results of matching of language tags
+    resolved map[string]LocaleMatch
+    // generation of registered locales, changed by registration
+    generation uint64
+}
+
+var localeRegistry = locRegistry{
+    custom: make(map[string]*LocFmt),
+    builtin: make(map[string]*LocFmt),
+    resolved: make(map[string]LocaleMatch),
+}
+
+func isBuiltinLocale(lang string) bool {
+    if _, ok := builtinRegionalLocales[lang]; ok { return true }
+    i := sort.SearchStrings(builtinLocales, lang)
+    return i<len(builtinLocales) && builtinLocales[i]==lang
+}
+
+// get built-in locale converted from goint128
+func (r *locRegistry) getBuiltin(lang string) *LocFmt {
+    if l, ok := builtinRegionalLocales[lang]; ok { return l }
+    r.RLock()
+    l, ok := r.builtin[lang]
+    r.RUnlock()
+    if ok { return l }
+    gl := goint128.GetLocFmt(lang)
+    l = &LocFmt{ Comma: gl.Comma, Sep1000: gl.Sep1000, Sep1000_2: gl.Sep1000_2,
+            Grouping: GroupingThousands, Symbols: builtinLocaleSymbols[lang],
+            Digits: gl.Digits }
+    if gl.Sep100and1000 { l.Grouping = GroupingIndian }
+    if builtinMinGrouping2[lang] { l.Grouping.MinGrouping = 2 }
+    r.Lock()
+    if l2, ok := r.builtin[lang]; ok {
+        l = l2
+    } else {
+        r.builtin[lang] = l
+    }
+    r.Unlock()
+    return l
+}
+
+// find locale by exact canonical name
+func (r *locRegistry) find(lang string) *LocFmt {
+    r.RLock()
+    l, ok := r.custom[lang]
+    r.RUnlock()
+    if ok { return l }
+    if isBuiltinLocale(lang) { return r.getBuiltin(lang) }
+    return nil
+}
+
+// match language tag: try locales from most specific (lang-Script-REGION)
+// to most general (lang) and apply numbering system
+func (r *locRegistry) match(tag string) (LocaleMatch, error) {
+    t, ok := parseLangTag(tag)
+    if !ok { return LocaleMatch{}, ErrUnknownLocale }
+    m := LocaleMatch{ Requested: t.String() }
+    if t.numbering!="" { m.Requested += "-u-nu-"+t.numbering }
+    for _, name := range t.fallbacks() {
+        if l := r.find(name); l!=nil {
+            m.Locale = name
+            m.Format = l
+            break
+        }
+    }
+    if m.Format==nil { return m, ErrUnknownLocale }
+    if t.numbering!="" {
+        if digits := cldrDigits(t.numbering); digits!=nil {
+            l := *m.Format
+            l.Digits = digits
+            m.Format = &l
+            m.NumberingSystem = t.numbering
+        }
+    }
+    return m, nil
+}
+
+// match language tag with caching of results
+func (r *locRegistry) resolve(tag string) (LocaleMatch, error) {
+    r.RLock()
+    m, ok := r.resolved[tag]
+    gen := r.generation
+    r.RUnlock()
+    if !ok {
+        m, _ = r.match(tag)
+        r.Lock()
+        // do not cache match if locales have been changed while matching
+        if r.generation==gen {
+            if len(r.resolved)>=maxResolvedLocales {
+                r.resolved = make(map[string]LocaleMatch)
+            }
+            r.resolved[tag] = m
+        }
+        r.Unlock()
+    }
+    if m.Format==nil { return m, ErrUnknownLocale }
+    return m, nil
+}
+
+// get locale, if not found then default locale is returned
+func getLocFmt(lang string) *LocFmt {
+    if m, err := localeRegistry.resolve(lang); err==nil { return m.Format }
+    return localeRegistry.getBuiltin("C")
+}
+
+// match BCP 47 language tag (or POSIX locale name like 'pl_PL.UTF-8') to
+// the best available locale. Locales are tried in order: lang-Script-REGION,
+// lang-REGION, lang-Script, lang. Numbering system given by -u-nu- extension
+// replaces digits of locale. If no locale matches then ErrUnknownLocale
+// is returned. Returned format is copy and can be modified.
+func MatchLocale(tag string) (LocaleMatch, error) {
+    m, err := localeRegistry.resolve(tag)
+    if m.Format!=nil { m.Format = copyLocFmt(m.Format) }
+    return m, err
+}
+
+// return locale by name or language tag. If locale is not available
+// then ErrUnknownLocale is returned. Returned locale is copy and can be
+// modified.
+func LookupLocale(lang string) (*LocFmt, error) {
+    m, err := MatchLocale(lang)
+    return m.Format, err
+}
+
+// register locale format. Registered locale overrides built-in locale
+// with same name. Locale is copied, so it can be modified after registration.
+// If grouping is not set and thousand separator is set, then groups of
+// three digits are used.
+func RegisterLocale(lang string, l *LocFmt) error {
+    t, ok := parseLangTag(lang)
+    if !ok || len(l.Digits)!=10 || l.Comma==0 || l.Comma==l.Sep1000 ||
+        l.Comma==l.Sep1000_2 || l.Comma==l.FracGrouping.Sep {
+        return ErrInvalidLocale
+    }
+    l = copyLocFmt(l)
+    if l.Sep1000==0 {
+        l.Grouping = GroupingNone
+    } else if l.Grouping==GroupingNone {
+        l.Grouping = GroupingThousands
+    }
+    if l.Sep1000_2==0 { l.Sep1000_2 = l.Sep1000 }
+    localeRegistry.Lock()
+    localeRegistry.custom[t.String()] = l
+    localeRegistry.resolved = make(map[string]LocaleMatch)
+    localeRegistry.generation++
+    localeRegistry.Unlock()
+    return nil
+}
+
+// unregister locale registered by RegisterLocale
+func UnregisterLocale(lang string) {
+    t, ok := parseLangTag(lang)
+    if !ok { return }
+    localeRegistry.Lock()
+    delete(localeRegistry.custom, t.String())
+    localeRegistry.resolved = make(map[string]LocaleMatch)
+    localeRegistry.generation++
+    localeRegistry.Unlock()
+}
+
+// return sorted names of all available locales (built-in and registered)
+func AvailableLocales() []string {
+    names := append([]string(nil), builtinLocales...)
+    for lang := range builtinRegionalLocales {
+        names = append(names, lang)
+    }
+    localeRegistry.RLock()
+    for lang := range localeRegistry.custom {
+        if !isBuiltinLocale(lang) {
+            names = append(names, lang)
+        }
+    }
+    localeRegistry.RUnlock()
+    sort.Strings(names)
+    return names
+}
+
+// zero digits of numbering systems used by CLDR
+var cldrNumberingSystems map[string]rune = map[string]rune{
+    "adlm": 0x1e950, "ahom": 0x11730, "arab": 0x660, "arabext": 0x6f0,
+    "bali": 0x1b50, "beng": 0x9e6, "bhks": 0x11c50, "brah": 0x11066,
+    "cakm": 0x11136, "cham": 0xaa50, "deva": 0x966, "fullwide": 0xff10,
+    "gong": 0x11da0, "gonm": 0x11d50, "gujr": 0xae6, "guru": 0xa66,
+    "hanidec": -1, "hmng": 0x16b50, "java": 0xa9d0, "kali": 0xa900,
+    "khmr": 0x17e0, "knda": 0xce6, "lana": 0x1a80, "lanatham": 0x1a90,
+    "laoo": 0xed0, "latn": '0', "lepc": 0x1c40, "limb": 0x1946,
+    "mlym": 0xd66, "modi": 0x11650, "mong": 0x1810, "mroo": 0x16a60,
+    "mtei": 0xabf0, "mymr": 0x1040, "mymrshan": 0x1090, "nkoo": 0x7c0,
+    "olck": 0x1c50, "orya": 0xb66, "osma": 0x104a0, "saur": 0xa8d0,
+    "shrd": 0x111d0, "sind": 0x112f0, "sinh": 0xde6, "sora": 0x110f0,
+    "sund": 0x1bb0, "takr": 0x116c0, "talu": 0x19d0, "tamldec": 0xbe6,
+    "telu": 0xc66, "thai": 0xe50, "tibt": 0xf20, "tirh": 0x114d0,
+    "vaii": 0xa620, "wara": 0x118e0,
+}
+
+// digits of hanidec numbering system (not contiguous in Unicode)
+var hanidecDigits []rune = []rune("〇一二三四五六七八九")
+
+// return digits of CLDR numbering system
+func cldrDigits(numberingSystem string) []rune {
+    zero, ok := cldrNumberingSystems[numberingSystem]
+    if !ok { return nil }
+    if zero==-1 { return hanidecDigits }
+    digits := make([]rune, 10)
+    for i := range digits { digits[i] = zero+rune(i) }
+    return digits
+}
+
+type cldrSymbols struct {
+    Decimal string `json:"decimal"`
+    Group string `json:"group"`
+    PlusSign string `json:"plusSign"`
+    MinusSign string `json:"minusSign"`
+    PercentSign string `json:"percentSign"`
+    PerMille string `json:"perMille"`
+    Exponential string `json:"exponential"`
+    SuperscriptingExponent string `json:"superscriptingExponent"`
+}
+
+type cldrPercentFormats struct {
+    Standard string `json:"standard"`
+}
+
+type cldrCurrencyFormats struct {
+    Standard string `json:"standard"`
+}
+
+type cldrDecimalFormats struct {
+    Standard string `json:"standard"`
+}
+
+// get first rune of CLDR symbol (CLDR symbols can contain bidi marks)
+func cldrSymbolRune(s string) rune {
+    for _, r := range s {
+        if r!=0x200e && r!=0x200f && r!=0x061c { return r }
+    }
+    return 0
+}
+
+// get bidi mark from CLDR symbol
+func cldrBidiMark(s string) rune {
+    for _, r := range s {
+        if r==LRM || r==RLM || r==ALM { return r }
+    }
+    return 0
+}
+
+// remove bidi marks from CLDR symbol
+func cldrStripBidi(s string) string {
+    var sb strings.Builder
+    for _, r := range s {
+        if r!=LRM && r!=RLM && r!=ALM { sb.WriteRune(r) }
+    }
+    return sb.String()
+}
+
+// convert CLDR percent pattern (like '#,##0 %') to pattern of LocSymbols
+func cldrPercentPattern(pattern string) string {
+    if i := strings.IndexByte(pattern, ';'); i!=-1 { pattern = pattern[:i] }
+    start := strings.IndexAny(pattern, "#0")
+    end := strings.LastIndexAny(pattern, "#0")
+    if start==-1 { return "" }
+    var sb strings.Builder
+    for _, r := range pattern[:start] {
+        if r!=LRM && r!=RLM && r!=ALM { sb.WriteRune(r) }
+    }
+    sb.WriteByte('#')
+    for _, r := range pattern[end+1:] {
+        if r!=LRM && r!=RLM && r!=ALM { sb.WriteRune(r) }
+    }
+    return sb.String()
+}
+
+// convert CLDR number data of locale to locale format
+func cldrToLocFmt(raw map[string]json.RawMessage) (*LocFmt, error) {
+    ns := "latn"
+    if v, ok := raw["defaultNumberingSystem"]; ok {
+        if err := json.Unmarshal(v, &ns); err!=nil { return nil, err }
+    }
+    digits := cldrDigits(ns)
+    if digits==nil { return nil, ErrInvalidLocale }
+    var symbols cldrSymbols
+    v, ok := raw["symbols-numberSystem-"+ns]
+    if !ok { return nil, ErrInvalidLocale }
+    if err := json.Unmarshal(v, &symbols); err!=nil { return nil, err }
+    l := &LocFmt{ Comma: cldrSymbolRune(symbols.Decimal),
+            Sep1000: cldrSymbolRune(symbols.Group), Digits: digits }
+    l.Sep1000_2 = l.Sep1000
+    if l.Sep1000==0xa0 || l.Sep1000==0x202f {
+        // accept also ordinary space
+        l.Sep1000_2 = ' '
+    }
+    var formats cldrDecimalFormats
+    if v, ok := raw["decimalFormats-numberSystem-"+ns]; ok {
+        if err := json.Unmarshal(v, &formats); err!=nil { return nil, err }
+    }
+    if formats.Standard!="" {
+        l.Grouping = parseGroupingPattern(formats.Standard)
+    } else {
+        l.Grouping = GroupingThousands
+    }
+    if l.Grouping.Enabled() {
+        if v, ok := raw["minimumGroupingDigits"]; ok {
+            var mg string
+            if err := json.Unmarshal(v, &mg); err!=nil { return nil, err }
+            l.Grouping.MinGrouping = parseMinGrouping(mg)
+        }
+        if l.Sep1000==0 { return nil, ErrInvalidLocale }
+    } else {
+        // locale without grouping
+        l.Sep1000, l.Sep1000_2 = 0, 0
+    }
+    // signs and percent
+    l.Symbols.MinusSign = cldrSymbolRune(symbols.MinusSign)
+    l.Symbols.PlusSign = cldrSymbolRune(symbols.PlusSign)
+    l.Symbols.PercentSign = cldrSymbolRune(symbols.PercentSign)
+    l.Symbols.PermilleSign = cldrSymbolRune(symbols.PerMille)
+    l.Symbols.BidiMark = cldrBidiMark(symbols.MinusSign)
+    l.Symbols.Exponential = cldrStripBidi(symbols.Exponential)
+    l.Symbols.SuperscriptingExponent = cldrSymbolRune(symbols.SuperscriptingExponent)
+    var percentFormats cldrPercentFormats
+    if v, ok := raw["percentFormats-numberSystem-"+ns]; ok {
+        if err := json.Unmarshal(v, &percentFormats); err!=nil { return nil, err }
+        l.Symbols.PercentPattern = cldrPercentPattern(percentFormats.Standard)
+    }
+    var currencyFormats cldrCurrencyFormats
+    if v, ok := raw["currencyFormats-numberSystem-"+ns]; ok {
+        if err := json.Unmarshal(v, &currencyFormats); err!=nil { return nil, err }
+        // currency pattern is converted like percent pattern
+        l.Symbols.CurrencyPattern = cldrPercentPattern(currencyFormats.Standard)
+    }
+    if l.Comma==0 { return nil, ErrInvalidLocale }
+    return l, nil
+}
+
+// load number format data from CLDR JSON (numbers.json from cldr-numbers
+// package) and register locales. Return names of registered locales.
+func LoadCLDR(r io.Reader) ([]string, error) {
+    var data struct {
+        Main map[string]struct {
+            Numbers map[string]json.RawMessage `json:"numbers"`
+        } `json:"main"`
+    }
+    if err := json.NewDecoder(r).Decode(&data); err!=nil { return nil, err }
+    // convert all before registering
+    locs := make(map[string]*LocFmt)
+    names := make([]string, 0, len(data.Main))
+    for lang, v := range data.Main {
+        if v.Numbers==nil { return nil, ErrInvalidLocale }
+        l, err := cldrToLocFmt(v.Numbers)
+        if err!=nil { return nil, err }
+        locs[lang] = l
+        names = append(names, lang)
+    }
+    sort.Strings(names)
+    for _, lang := range names {
+        if err := RegisterLocale(lang, locs[lang]); err!=nil { return nil, err }
+    }
+    return names, nil
+}
+
+// load number format data from CLDR JSON file and register locales.
+// Return names of registered locales.
+func LoadCLDRFile(filename string) ([]string, error) {
+    f, err := os.Open(filename)
+    if err!=nil { return nil, err }
+    defer f.Close()
+    return LoadCLDR(f)
+}
@@ -0,0 +1,270 @@
+/*
+ * locreg_test.go - tests for registry of locales
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+func TestLookupLocale(t *testing.T) {
+    for _, lang := range []string{ "", "C", "en", "pl", "pl-PL", "pl_PL.UTF-8", "fil" } {
+        l, err := LookupLocale(lang)
+        if l==nil || err!=nil {
+            t.Errorf("Lookup failed: %v: %v,%v", lang, l, err)
+        }
+    }
+    for _, lang := range []string{ "xx", "xx-PL", "english" } {
+        l, err := LookupLocale(lang)
+        if l!=nil || err!=ErrUnknownLocale {
+            t.Errorf("Lookup unexpectedly succeeded: %v: %v,%v", lang, l, err)
+        }
+    }
+    if !sort.StringsAreSorted(builtinLocales) {
+        t.Errorf("Built-in locales are not sorted")
+    }
+}
+
+func TestRegisterLocale(t *testing.T) {
+    swiss := &LocFmt{ Comma: '.', Sep1000: '\'', Digits: []rune("0123456789") }
+    if err := RegisterLocale("de_CH", swiss); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterLocale("de-CH")
+    a := UDec128{ 123456789, 0 }
+    if s := a.LocaleFormat("de-CH", 2, false, false); s!="1'234'567.89" {
+        t.Errorf("Result mismatch: de-CH: %v", s)
+    }
+    if s := a.LocaleFormat("de_CH.UTF-8", 2, false, false); s!="1'234'567.89" {
+        t.Errorf("Result mismatch: de_CH.UTF-8: %v", s)
+    }
+    if s := a.LocaleFormat("de", 2, false, false); s!="1.234.567,89" {
+        t.Errorf("Result mismatch: de: %v", s)
+    }
+    v, err := LocaleParseUDec128("de-CH", "1'234'567.89", 2, false)
+    if v!=a || err!=nil {
+        t.Errorf("Result mismatch: parse de-CH: %v,%v", v, err)
+    }
+    // looked up locale is copy
+    l, err := LookupLocale("de-CH")
+    if err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    l.Comma = ';'
+    l.Digits[1] = 'I'
+    if m, _ := MatchLocale("de-CH"); m.Format.Comma!='.' {
+        t.Errorf("Result mismatch: match after change: %v", m.Format)
+    }
+    // registered locale is copy
+    if swiss.Grouping!=GroupingNone || swiss.Sep1000_2!=0 {
+        t.Errorf("Registered locale is modified: %v", swiss)
+    }
+    swiss.Comma = ','
+    swiss.Digits[0] = 'o'
+    if s := a.LocaleFormat("de-CH", 2, false, false); s!="1'234'567.89" {
+        t.Errorf("Result mismatch: de-CH after change: %v", s)
+    }
+    // custom language
+    if err := RegisterLocale("xx", &LocFmt{ Comma: ',', Sep1000: '_',
+                    Digits: []rune("0123456789") }); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    if s := a.LocaleFormat("xx-YY", 2, false, false); s!="1_234_567,89" {
+        t.Errorf("Result mismatch: xx-YY: %v", s)
+    }
+    locs := AvailableLocales()
+    i := sort.SearchStrings(locs, "xx")
+    if i==len(locs) || locs[i]!="xx" {
+        t.Errorf("Registered locale is not available: %v", locs)
+    }
+    UnregisterLocale("xx")
+    if _, err := LookupLocale("xx"); err!=ErrUnknownLocale {
+        t.Errorf("Unregistered locale is available")
+    }
+    // invalid locales
+    invalid := []*LocFmt{
+        &LocFmt{ Comma: ',', Sep1000: ',', Digits: []rune("0123456789") },
+        &LocFmt{ Comma: ',', Sep1000: '.', Digits: []rune("012345678") },
+        &LocFmt{ Sep1000: '.', Digits: []rune("0123456789") },
+    }
+    for i, l := range invalid {
+        if err := RegisterLocale("yy", l); err!=ErrInvalidLocale {
+            t.Errorf("Invalid locale registered: %d: %v", i, err)
+        }
+    }
+}
+
+const cldrTestData = `{
+  "main": {
+    "de-CH": {
+      "identity": { "language": "de", "territory": "CH" },
+      "numbers": {
+        "defaultNumberingSystem": "latn",
+        "symbols-numberSystem-latn": { "decimal": ".", "group": "’" },
+        "decimalFormats-numberSystem-latn": { "standard": "#,##0.###" }
+      }
+    },
+    "bn-IN": {
+      "identity": { "language": "bn", "territory": "IN" },
+      "numbers": {
+        "defaultNumberingSystem": "beng",
+        "symbols-numberSystem-beng": { "decimal": ".", "group": "," },
+        "decimalFormats-numberSystem-beng": { "standard": "#,##,##0.###" }
+      }
+    },
+    "fr-CA": {
+      "identity": { "language": "fr", "territory": "CA" },
+      "numbers": {
+        "defaultNumberingSystem": "latn",
+        "symbols-numberSystem-latn": { "decimal": ",", "group": " " },
+        "decimalFormats-numberSystem-latn": { "standard": "#,##0.###" }
+      }
+    }
+  }
+}`
+
+func TestLoadCLDR(t *testing.T) {
+    names, err := LoadCLDR(strings.NewReader(cldrTestData))
+    if err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer func() {
+        for _, lang := range names { UnregisterLocale(lang) }
+    }()
+    if !reflect.DeepEqual(names, []string{ "bn-IN", "de-CH", "fr-CA" }) {
+        t.Errorf("Names mismatch: %v", names)
+    }
+    l, err := LookupLocale("bn-IN")
+    if err!=nil || l.Grouping!=GroupingIndian || l.Digits[1]!='১' {
+        t.Errorf("Locale mismatch: bn-IN: %v,%v", l, err)
+    }
+    a := UDec128{ 0xab54a98ceb1f0ad3, 0 }
+    if s := a.LocaleFormat("de-CH", 10, false, false);
+            s!="1’234’567’890.1234567891" {
+        t.Errorf("Result mismatch: de-CH: %v", s)
+    }
+    if s := a.LocaleFormat("bn-IN", 10, false, false);
+            s!="১,২৩,৪৫,৬৭,৮৯০.১২৩৪৫৬৭৮৯১" {
+        t.Errorf("Result mismatch: bn-IN: %v", s)
+    }
+    v, err := LocaleParseUDec128("fr-CA", "1 234 567 890,1234567891", 10, false)
+    if v!=a || err!=nil {
+        t.Errorf("Result mismatch: parse fr-CA: %v,%v", v, err)
+    }
+    
+    _, err = LoadCLDR(strings.NewReader(`{ "main": { "xx": { "numbers": {
+            "defaultNumberingSystem": "unknown" } } } }`))
+    if err!=ErrInvalidLocale {
+        t.Errorf("Unexpected error: %v", err)
+    }
+    if _, err := LookupLocale("xx"); err!=ErrUnknownLocale {
+        t.Errorf("Invalid locale is registered")
+    }
+}
+
+func TestLoadCLDRFile(t *testing.T) {
+    dir, err := ioutil.TempDir("", "godec128")
+    if err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer os.RemoveAll(dir)
+    filename := filepath.Join(dir, "numbers.json")
+    if err := ioutil.WriteFile(filename, []byte(cldrTestData), 0644); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    names, err := LoadCLDRFile(filename)
+    if err!=nil || len(names)!=3 {
+        t.Errorf("Result mismatch: %v,%v", names, err)
+    }
+    for _, lang := range names { UnregisterLocale(lang) }
+    if _, err := LoadCLDRFile(filepath.Join(dir, "notexist.json")); err==nil {
+        t.Errorf("Loading not existing file succeeded")
+    }
+}
+
+type MatchLocaleTC struct {
+    tag string
+    expRequested string
+    expLocale string
+    expNumbering string
+    expError error
+}
+
+func TestMatchLocale(t *testing.T) {
+    testCases := []MatchLocaleTC {
+        MatchLocaleTC{ "de-CH", "de-CH", "de-CH", "", nil },
+        MatchLocaleTC{ "de-DE", "de-DE", "de", "", nil },
+        MatchLocaleTC{ "fr_CA.UTF-8", "fr-CA", "fr-CA", "", nil },
+        MatchLocaleTC{ "en-IN", "en-IN", "en-IN", "", nil },
+        MatchLocaleTC{ "en-Latn-IN", "en-Latn-IN", "en-IN", "", nil },
+        MatchLocaleTC{ "zh-Hant-TW", "zh-Hant-TW", "zh", "", nil },
+        MatchLocaleTC{ "ar-EG-u-nu-latn", "ar-EG-u-nu-latn", "ar", "latn", nil },
+        MatchLocaleTC{ "en-u-nu-xxxx", "en-u-nu-xxxx", "en", "", nil },
+        MatchLocaleTC{ "xx-PL", "xx-PL", "", "", ErrUnknownLocale },
+        MatchLocaleTC{ "1x", "", "", "", ErrUnknownLocale },
+    }
+    for i, tc := range testCases {
+        m, err := MatchLocale(tc.tag)
+        if tc.expRequested!=m.Requested || tc.expLocale!=m.Locale ||
+            tc.expNumbering!=m.NumberingSystem || tc.expError!=err {
+            t.Errorf("Result mismatch: %d: match(%v)->%v,%v,%v,%v!=%v,%v,%v,%v",
+                     i, tc.tag, tc.expRequested, tc.expLocale, tc.expNumbering,
+                     tc.expError, m.Requested, m.Locale, m.NumberingSystem, err)
+        }
+        if err==nil && m.Format==nil {
+            t.Errorf("No format: %d: %v", i, tc.tag)
+        }
+    }
+
+    a := UDec128{ 0xab54a98ceb1f0ad3, 0 }
+    localeTestCases := []UDec128LocTC {
+        UDec128LocTC{ "de-CH", false, a, 10, false, "1’234’567’890.1234567891" },
+        UDec128LocTC{ "fr-CA", false, a, 10, false,
+                "1\u00a0234\u00a0567\u00a0890,1234567891" },
+        UDec128LocTC{ "en-IN", false, a, 10, false, "1,23,45,67,890.1234567891" },
+        UDec128LocTC{ "ar-EG-u-nu-latn", false, a, 10, false,
+                "1٬234٬567٬890٫1234567891" },
+        UDec128LocTC{ "en-u-nu-deva", false, a, 10, false,
+                "१,२३४,५६७,८९०.१२३४५६७८९१" },
+    }
+    for i, tc := range localeTestCases {
+        result := tc.a.LocaleFormat(tc.lang, tc.precision, tc.trimZeroes, tc.noSep1000)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%s)->%v!=%v",
+                     i, tc.a, tc.lang, tc.expected, result)
+        }
+        v, err := LocaleParseUDec128(tc.lang, result, tc.precision, false)
+        if v!=tc.a || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%v,%s)->%v,%v",
+                     i, result, tc.lang, v, err)
+        }
+    }
+    v, err := LocaleParseUDec128("de-CH", "1'234'567'890.1234567891", 10, false)
+    if v!=a || err!=nil {
+        t.Errorf("Result mismatch: parse de-CH with apostrophe: %v,%v", v, err)
+    }
+}
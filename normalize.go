@@ -0,0 +1,154 @@
+/*
+ * normalize.go - normalization of Unicode input of numbers
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "unicode"
+    "unicode/utf8"
+)
+
+// substitutions done by normalization
+type NormalizeOptions uint32
+
+const (
+    // map all Unicode decimal digits (Nd category) to ASCII digits
+    NormalizeDigits NormalizeOptions = 1<<iota
+    // map no-break spaces, thin spaces and other spaces to space separator
+    // of locale or ordinary space
+    NormalizeSpaces
+    // map minus and plus look-alikes (U+2212, dashes, full-width signs)
+    // to ASCII signs
+    NormalizeSigns
+    // map Arabic decimal and thousand separators and full-width comma and
+    // full stop to separators of locale
+    NormalizeSeparators
+    // map apostrophe look-alikes to ASCII apostrophe
+    NormalizeApostrophes
+    // remove zero-width characters and bidi control characters
+    NormalizeZeroWidth
+    // all substitutions
+    NormalizeAll NormalizeOptions = NormalizeDigits | NormalizeSpaces |
+            NormalizeSigns | NormalizeSeparators | NormalizeApostrophes |
+            NormalizeZeroWidth
+)
+
+// return value of Unicode decimal digit or -1 if rune is not decimal digit
+func unicodeDigitValue(r rune) int {
+    if r>='0' && r<='9' { return int(r-'0') }
+    if r<utf8.RuneSelf || !unicode.Is(unicode.Nd, r) { return -1 }
+    // digits of Nd category are in blocks of ten from zero
+    start := r
+    for unicode.Is(unicode.Nd, start-1) { start-- }
+    return int(r-start)%10
+}
+
+// return true if rune is zero-width or bidi control character
+func isZeroWidthRune(r rune) bool {
+    switch r {
+    case 0x200b, 0x200c, 0x200d, 0x2060, 0xfeff, LRM, RLM, ALM:
+        return true
+    }
+    return (r>=0x202a && r<=0x202e) || (r>=0x2066 && r<=0x2069)
+}
+
+// normalize rune. Return normalized rune and false if rune should be removed
+func normalizeRune(l *LocFmt, r rune, opts NormalizeOptions) (rune, bool) {
+    if r<utf8.RuneSelf { return r, true }
+    if opts&NormalizeZeroWidth!=0 && isZeroWidthRune(r) { return 0, false }
+    // do not change characters used by locale
+    if r==l.Comma || r==l.Sep1000 || r==l.Sep1000_2 { return r, true }
+    for _, d := range l.Digits {
+        if r==d { return r, true }
+    }
+    if opts&NormalizeDigits!=0 {
+        if d := unicodeDigitValue(r); d>=0 { return '0'+rune(d), true }
+    }
+    if opts&NormalizeSpaces!=0 && unicode.Is(unicode.Zs, r) {
+        // use space separator of locale
+        if isSpaceRune(l.Sep1000) { return l.Sep1000, true }
+        return ' ', true
+    }
+    if opts&NormalizeSigns!=0 {
+        switch r {
+        case 0x2212, 0x2012, 0x2013, 0x2014, 0x2010, 0x2011, 0xfe63, 0xff0d:
+            return '-', true
+        case 0xfe62, 0xff0b:
+            return '+', true
+        }
+    }
+    if opts&NormalizeSeparators!=0 {
+        switch r {
+        case 0x066b:
+            return l.Comma, true
+        case 0x066c:
+            if l.Sep1000!=0 { return l.Sep1000, true }
+        case 0xff0c:
+            return ',', true
+        case 0xff0e:
+            return '.', true
+        }
+    }
+    if opts&NormalizeApostrophes!=0 {
+        switch r {
+        case 0x2019, 0x2018, 0x02bc, 0x2032, 0xff07:
+            return '\'', true
+        }
+    }
+    return r, true
+}
+
+// append normalized number to dst
+func appendNormalized(dst []byte, l *LocFmt, str []byte, opts NormalizeOptions) []byte {
+    for len(str)>0 {
+        r, size := utf8.DecodeRune(str)
+        if nr, ok := normalizeRune(l, r, opts); ok {
+            dst = appendRune(dst, nr)
+        }
+        str = str[size:]
+    }
+    return dst
+}
+
+// normalize number for parsing with locale: map Unicode digits, separators
+// and signs to canonical form (depends on options)
+func NormalizeNumber(lang, str string, opts NormalizeOptions) string {
+    return string(appendNormalized(nil, getLocFmt(lang), []byte(str), opts))
+}
+
+// parse decimal fixed point from string with locale after normalization.
+// Return value and error (nil if no error)
+func LocaleParseUDec128Normalized(lang, str string, precision uint, rounding bool,
+                    opts NormalizeOptions) (UDec128, error) {
+    return LocaleParseUDec128NormalizedBytes(lang, []byte(str), precision,
+                                             rounding, opts)
+}
+
+// parse decimal fixed point from bytes with locale after normalization.
+// Return value and error (nil if no error)
+func LocaleParseUDec128NormalizedBytes(lang string, str []byte, precision uint,
+                    rounding bool, opts NormalizeOptions) (UDec128, error) {
+    l := getLocFmt(lang)
+    ns := appendNormalized(make([]byte, 0, len(str)), l, str, opts)
+    return localeParseUDec128Bytes(l, l.Grouping, l.FracGrouping, ns,
+                                   precision, rounding)
+}
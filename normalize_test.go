@@ -0,0 +1,122 @@
+/*
+ * normalize_test.go - tests for normalization of Unicode input of numbers
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type NormalizeNumberTC struct {
+    lang string
+    str string
+    opts NormalizeOptions
+    expected string
+}
+
+func TestNormalizeNumber(t *testing.T) {
+    testCases := []NormalizeNumberTC {
+        NormalizeNumberTC{ "en", "１２３．５", NormalizeAll, "123.5" },
+        NormalizeNumberTC{ "en", "１２３．５", NormalizeDigits, "123．5" },
+        NormalizeNumberTC{ "en", "١٢٣٤٫٥", NormalizeAll, "1234.5" },
+        NormalizeNumberTC{ "de", "١٬٢٣٤٫٥", NormalizeAll, "1.234,5" },
+        NormalizeNumberTC{ "en", "\U0001d7d9\U0001d7da", NormalizeAll, "12" },
+        NormalizeNumberTC{ "en", "१२३", NormalizeAll, "123" },
+        NormalizeNumberTC{ "ar", "١٢٣", NormalizeAll, "١٢٣" },
+        NormalizeNumberTC{ "pl", "1\u202f234", NormalizeAll, "1\u00a0234" },
+        NormalizeNumberTC{ "pl", "1\u00a0234", NormalizeAll, "1\u00a0234" },
+        NormalizeNumberTC{ "en", "1\u2009234", NormalizeAll, "1 234" },
+        NormalizeNumberTC{ "en", "1\u2009234", NormalizeDigits, "1\u2009234" },
+        NormalizeNumberTC{ "en", "−5", NormalizeAll, "-5" },
+        NormalizeNumberTC{ "en", "–5", NormalizeAll, "-5" },
+        NormalizeNumberTC{ "en", "−5", NormalizeDigits, "−5" },
+        NormalizeNumberTC{ "de-CH", "1’234", NormalizeAll, "1’234" },
+        NormalizeNumberTC{ "en", "1’234", NormalizeAll, "1'234" },
+        NormalizeNumberTC{ "en", "1\u200b2\ufeff3\u200e", NormalizeAll, "123" },
+        NormalizeNumberTC{ "en", "1\u200b2", NormalizeSpaces, "1\u200b2" },
+    }
+    for i, tc := range testCases {
+        result := NormalizeNumber(tc.lang, tc.str, tc.opts)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: normalize(%q)->%q!=%q",
+                     i, tc.str, tc.expected, result)
+        }
+    }
+}
+
+type UDec128NormalizedTC struct {
+    lang string
+    str string
+    opts NormalizeOptions
+    expected UDec128
+    err error
+}
+
+func TestLocaleParseUDec128Normalized(t *testing.T) {
+    testCases := []UDec128NormalizedTC {
+        UDec128NormalizedTC{ "en", "１,２３４．５", NormalizeAll, UDec128{ 12345, 0 }, nil },
+        UDec128NormalizedTC{ "en", "١٢٣٤٫٥", NormalizeAll, UDec128{ 12345, 0 }, nil },
+        UDec128NormalizedTC{ "fr", "1\u202f234,5", NormalizeAll, UDec128{ 12345, 0 }, nil },
+        UDec128NormalizedTC{ "pl", "1\u2009234,5", NormalizeAll, UDec128{ 12345, 0 }, nil },
+        UDec128NormalizedTC{ "en", "1,2\u200b34.5", NormalizeAll, UDec128{ 12345, 0 }, nil },
+        UDec128NormalizedTC{ "en", "1,2\u200b34.5", NormalizeDigits, UDec128{},
+                    strconv.ErrSyntax },
+        UDec128NormalizedTC{ "en", "１２３．５", NormalizeDigits, UDec128{},
+                    strconv.ErrSyntax },
+        UDec128NormalizedTC{ "en", "−1.5", NormalizeAll, UDec128{},
+                    strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, err := LocaleParseUDec128Normalized(tc.lang, tc.str, 1, false, tc.opts)
+        if tc.expected!=result || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v!=%v,%v",
+                     i, tc.str, tc.expected, tc.err, result, err)
+        }
+        result, err = LocaleParseUDec128NormalizedBytes(tc.lang, []byte(tc.str), 1,
+                                                        false, tc.opts)
+        if tc.expected!=result || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parseBytes(%q)->%v,%v!=%v,%v",
+                     i, tc.str, tc.expected, tc.err, result, err)
+        }
+    }
+}
+
+func TestParserNormalize(t *testing.T) {
+    p := NewParser(ParserOptions{ Precision: 2, Normalize: NormalizeAll })
+    if v, err := p.Parse("１２．５"); v!=(UDec128{ 1250, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: plain: %v,%v", v, err)
+    }
+    if v, neg, err := p.ParseSigned("−3.25"); v!=(UDec128{ 325, 0 }) ||
+            !neg || err!=nil {
+        t.Errorf("Result mismatch: signed: %v,%v,%v", v, neg, err)
+    }
+    p = NewParser(ParserOptions{ Lang: "de", Precision: 2, Normalize: NormalizeAll })
+    if v, err := p.ParseBytes([]byte("１.２３４,５")); v!=(UDec128{ 123450, 0 }) ||
+            err!=nil {
+        t.Errorf("Result mismatch: de: %v,%v", v, err)
+    }
+    // without normalization
+    p = NewParser(ParserOptions{ Precision: 2 })
+    if _, err := p.Parse("１２．５"); err==nil {
+        t.Errorf("Error mismatch: no normalization")
+    }
+}
@@ -0,0 +1,170 @@
+/*
+ * round.go - rounding of decimal fixed points while formatting
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "github.com/matszpk/goint128"
+)
+
+// rounding mode used while reducing number of digits
+type RoundingMode uint8
+
+const (
+    // cut off digits (round toward zero)
+    RoundDown RoundingMode = iota
+    // round away from zero if any cut off digit is not zero
+    RoundUp
+    // round to nearest, halfway away from zero
+    RoundHalfUp
+    // round to nearest, halfway toward zero
+    RoundHalfDown
+    // round to nearest, halfway to even digit (banker's rounding)
+    RoundHalfEven
+)
+
+// return true if quotient should be incremented, r is remainder of division
+// by d (power of 10), sticky is true if lower cut off digits are not zero
+func roundIncrement(q goint128.UInt128, r, d uint64, sticky bool,
+                    mode RoundingMode) bool {
+    half := d>>1
+    switch mode {
+    case RoundUp:
+        return r!=0 || sticky
+    case RoundHalfUp:
+        return r>=half
+    case RoundHalfDown:
+        return r>half || (r==half && sticky)
+    case RoundHalfEven:
+        return r>half || (r==half && (sticky || q[0]&1!=0))
+    }
+    return false
+}
+
+// divide by 10**digits with rounding
+func udec128RoundDivPow10(a UDec128, digits uint, mode RoundingMode) UDec128 {
+    if digits==0 { return a }
+    v := goint128.UInt128(a)
+    sticky := false
+    for ; digits>18; digits -= 18 {
+        var r uint64
+        v, r = v.Div64(uint64_powers[18])
+        if r!=0 { sticky = true }
+    }
+    d := uint64_powers[digits]
+    q, r := v.Div64(d)
+    if roundIncrement(q, r, d, sticky, mode) {
+        q = q.Add64(1)
+    }
+    return UDec128(q)
+}
+
+// append formatted number to dst with rounding to displayPrecision digits
+// in fraction if displayPrecision is lesser than precision. Rounding
+// can carry into integer part (9.999 with displayPrecision 2 gives 10.00).
+func (a UDec128) AppendFormatRound(dst []byte, precision, displayPrecision uint,
+                        trimZeroes bool, mode RoundingMode) []byte {
+    if displayPrecision>=precision || mode==RoundDown {
+        return a.AppendFormat(dst, precision, displayPrecision, trimZeroes)
+    }
+    r := udec128RoundDivPow10(a, precision-displayPrecision, mode)
+    if displayPrecision==0 {
+        // keep form of FormatNew: integer part with comma and zero
+        if r.IsZero() { return append(dst, '0', '.', '0') }
+        dst = r.AppendFormat(dst, 0, 0, false)
+        return append(dst, '.', '0')
+    }
+    if r.IsZero() && !trimZeroes {
+        // rounded to zero, keep all digits of fraction
+        dst = append(dst, '0', '.')
+        for i := uint(0); i < displayPrecision; i++ {
+            dst = append(dst, '0')
+        }
+        return dst
+    }
+    return r.AppendFormat(dst, displayPrecision, displayPrecision, trimZeroes)
+}
+
+// append formatted number to dst rounded to exactly digits digits in
+// fraction (without comma if digits is zero)
+func (a UDec128) appendFormatFixed(dst []byte, precision, digits uint,
+                        mode RoundingMode) []byte {
+    start := len(dst)
+    dst = a.AppendFormatRound(dst, precision, digits, false, mode)
+    j := bytes.IndexByte(dst[start:], '.')
+    if digits==0 {
+        if j!=-1 { dst = dst[:start+j] }
+        return dst
+    }
+    if j==-1 {
+        j = len(dst)-start
+        dst = append(dst, '.')
+    }
+    for len(dst)-start-j-1 < int(digits) { dst = append(dst, '0') }
+    return dst
+}
+
+// format number with rounding to displayPrecision digits in fraction
+func (a UDec128) FormatRound(precision, displayPrecision uint, trimZeroes bool,
+                             mode RoundingMode) string {
+    var buf [64]byte
+    return string(a.AppendFormatRound(buf[:0], precision, displayPrecision,
+                                      trimZeroes, mode))
+}
+
+// format number to bytes with rounding to displayPrecision digits in fraction
+func (a UDec128) FormatRoundBytes(precision, displayPrecision uint, trimZeroes bool,
+                                  mode RoundingMode) []byte {
+    var buf [64]byte
+    s := a.AppendFormatRound(buf[:0], precision, displayPrecision, trimZeroes, mode)
+    return append([]byte(nil), s...)
+}
+
+// append formatted number including locale to dst with rounding
+// to displayPrecision digits in fraction
+func (a UDec128) AppendLocaleFormatRound(dst []byte, lang string,
+                    precision, displayPrecision uint, trimZeroes, noSep1000 bool,
+                    mode RoundingMode) []byte {
+    var buf [64]byte
+    s := a.AppendFormatRound(buf[:0], precision, displayPrecision, trimZeroes, mode)
+    l := getLocFmt(lang)
+    return appendLocalized(dst, l, localeGrouping(l, noSep1000), l.FracGrouping, s)
+}
+
+// format number including locale with rounding to displayPrecision digits in fraction
+func (a UDec128) LocaleFormatRound(lang string, precision, displayPrecision uint,
+                    trimZeroes, noSep1000 bool, mode RoundingMode) string {
+    var buf [128]byte
+    return string(a.AppendLocaleFormatRound(buf[:0], lang, precision,
+                        displayPrecision, trimZeroes, noSep1000, mode))
+}
+
+// format number including locale to bytes with rounding to displayPrecision
+// digits in fraction
+func (a UDec128) LocaleFormatRoundBytes(lang string, precision, displayPrecision uint,
+                    trimZeroes, noSep1000 bool, mode RoundingMode) []byte {
+    var buf [128]byte
+    s := a.AppendLocaleFormatRound(buf[:0], lang, precision, displayPrecision,
+                        trimZeroes, noSep1000, mode)
+    return append([]byte(nil), s...)
+}
@@ -0,0 +1,105 @@
+/*
+ * round_test.go - tests for rounding while formatting
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "testing"
+)
+
+type UDec128FmtRoundTC struct {
+    a UDec128
+    precision uint
+    dispPrecision uint
+    trimZeroes bool
+    mode RoundingMode
+    expected string
+}
+
+func TestUDec128FormatRound(t *testing.T) {
+    testCases := []UDec128FmtRoundTC {
+        UDec128FmtRoundTC{ UDec128{ 1999, 0 }, 3, 2, false, RoundDown, "1.99" },
+        UDec128FmtRoundTC{ UDec128{ 1999, 0 }, 3, 2, false, RoundHalfUp, "2.00" },
+        UDec128FmtRoundTC{ UDec128{ 1999, 0 }, 3, 2, true, RoundHalfUp, "2.0" },
+        UDec128FmtRoundTC{ UDec128{ 9999, 0 }, 3, 2, false, RoundHalfUp, "10.00" },
+        UDec128FmtRoundTC{ UDec128{ 9999, 0 }, 3, 0, false, RoundHalfUp, "10.0" },
+        UDec128FmtRoundTC{ UDec128{ 1994, 0 }, 3, 2, false, RoundHalfUp, "1.99" },
+        UDec128FmtRoundTC{ UDec128{ 1991, 0 }, 3, 2, false, RoundUp, "2.00" },
+        UDec128FmtRoundTC{ UDec128{ 1990, 0 }, 3, 2, false, RoundUp, "1.99" },
+        UDec128FmtRoundTC{ UDec128{ 1995, 0 }, 3, 2, false, RoundHalfDown, "1.99" },
+        UDec128FmtRoundTC{ UDec128{ 19951, 0 }, 4, 2, false, RoundHalfDown, "2.00" },
+        UDec128FmtRoundTC{ UDec128{ 1985, 0 }, 3, 2, false, RoundHalfEven, "1.98" },
+        UDec128FmtRoundTC{ UDec128{ 1995, 0 }, 3, 2, false, RoundHalfEven, "2.00" },
+        UDec128FmtRoundTC{ UDec128{ 19851, 0 }, 4, 2, false, RoundHalfEven, "1.99" },
+        UDec128FmtRoundTC{ UDec128{ 4, 0 }, 3, 2, false, RoundHalfUp, "0.00" },
+        UDec128FmtRoundTC{ UDec128{ 4, 0 }, 3, 2, true, RoundHalfUp, "0.0" },
+        UDec128FmtRoundTC{ UDec128{ 5, 0 }, 3, 2, false, RoundHalfUp, "0.01" },
+        UDec128FmtRoundTC{ UDec128{ 1999, 0 }, 3, 5, false, RoundHalfUp, "1.99900" },
+        UDec128FmtRoundTC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15, 10, false,
+            RoundHalfUp, "217224419425.1436933315" },
+        UDec128FmtRoundTC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15, 12, false,
+            RoundHalfUp, "217224419425.143693331510" },
+        UDec128FmtRoundTC{ UDec128{ 0x5f75348b0131b3af, 0xb3af0f }, 15, 13, false,
+            RoundHalfUp, "217224419425.1436933315102" },
+        // more than 18 digits to cut off
+        UDec128FmtRoundTC{ UDec128{ 0xffffffffffffffff, 0xffffffffffffffff }, 18, 0, false,
+            RoundHalfUp, "340282366920938463463.0" },
+        UDec128FmtRoundTC{ UDec128{ 0xffffffffffffffff, 0xffffffffffffffff }, 18, 0, false,
+            RoundUp, "340282366920938463464.0" },
+        UDec128FmtRoundTC{ UDec128{ 0xffffffffffffffff, 0xffffffffffffffff }, 18, 0, false,
+            RoundDown, "340282366920938463463.0" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        result := tc.a.FormatRound(tc.precision, tc.dispPrecision, tc.trimZeroes, tc.mode)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmtRound(%v,%v,%v,%v)->%v!=%v",
+                     i, tc.a, tc.precision, tc.dispPrecision, tc.mode,
+                     tc.expected, result)
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d: %v!=%v", i, a, tc.a)
+        }
+        resultBytes := tc.a.FormatRoundBytes(tc.precision, tc.dispPrecision,
+                                             tc.trimZeroes, tc.mode)
+        if tc.expected!=string(resultBytes) {
+            t.Errorf("Result mismatch: %d: fmtRoundBytes(%v,%v,%v,%v)->%v!=%v",
+                     i, tc.a, tc.precision, tc.dispPrecision, tc.mode,
+                     tc.expected, string(resultBytes))
+        }
+    }
+}
+
+func TestUDec128LocaleFormatRound(t *testing.T) {
+    a := UDec128{ 99999995, 0 }
+    result := a.LocaleFormatRound("de", 4, 3, false, false, RoundHalfUp)
+    if result!="10.000,000" {
+        t.Errorf("Result mismatch: locFmtRound(%v)->%v", a, result)
+    }
+    resultBytes := a.LocaleFormatRoundBytes("de", 4, 3, false, false, RoundHalfUp)
+    if string(resultBytes)!="10.000,000" {
+        t.Errorf("Result mismatch: locFmtRoundBytes(%v)->%v", a, string(resultBytes))
+    }
+    result = a.LocaleFormatRound("de", 4, 3, false, false, RoundDown)
+    if result!="9.999,999" {
+        t.Errorf("Result mismatch: locFmtRound(%v)->%v", a, result)
+    }
+}
@@ -0,0 +1,570 @@
+/*
+ * signs.go - locale signs, percent and permille
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// bidi control marks
+const (
+    // left-to-right mark
+    LRM rune = 0x200e
+    // right-to-left mark
+    RLM rune = 0x200f
+    // Arabic letter mark
+    ALM rune = 0x061c
+)
+
+// symbols of locale (signs, percent and permille)
+type LocSymbols struct {
+    // minus and plus signs, if zero then '-' and '+' are used
+    MinusSign, PlusSign rune
+    // put sign after number
+    SignAfter bool
+    // percent, permille and per ten thousand signs,
+    // if zero then '%', '‰' and '‱' are used
+    PercentSign, PermilleSign, PerMyriadSign rune
+    // pattern of percent: '#' is number and '%' is percent sign
+    // (for example "#%", "#\u00a0%" or "%#"). if empty then "#%" is used
+    PercentPattern string
+    // bidi mark (LRM or ALM) put before signs if bidi marks are enabled
+    BidiMark rune
+    // exponential symbol (for example "E" or "×10^"), if empty then "E" is used
+    Exponential string
+    // symbol before power of ten with superscript exponent (like '1,5×10³'),
+    // if zero then '×' is used
+    SuperscriptingExponent rune
+    // pattern of currency: '#' is number and '¤' is currency symbol
+    // (for example "¤#", "#\u00a0¤" or "¤\u00a0#"). if empty then "¤#" is used
+    CurrencyPattern string
+}
+
+// style of formatted number
+type NumberStyle uint8
+
+const (
+    // ordinary decimal number
+    StyleDecimal NumberStyle = iota
+    // percent (value multiplied by 100)
+    StylePercent
+    // permille (value multiplied by 1000)
+    StylePermille
+    // basis points (value multiplied by 10000)
+    StyleBasisPoints
+)
+
+// return number of digits that point is moved by style
+func (style NumberStyle) shift() int {
+    switch style {
+    case StylePercent:
+        return 2
+    case StylePermille:
+        return 3
+    case StyleBasisPoints:
+        return 4
+    }
+    return 0
+}
+
+func (ls *LocSymbols) minusSign() rune {
+    if ls.MinusSign==0 { return '-' }
+    return ls.MinusSign
+}
+
+func (ls *LocSymbols) plusSign() rune {
+    if ls.PlusSign==0 { return '+' }
+    return ls.PlusSign
+}
+
+// return symbol of style
+func (ls *LocSymbols) styleSign(style NumberStyle) rune {
+    switch style {
+    case StylePercent:
+        if ls.PercentSign==0 { return '%' }
+        return ls.PercentSign
+    case StylePermille:
+        if ls.PermilleSign==0 { return '‰' }
+        return ls.PermilleSign
+    case StyleBasisPoints:
+        if ls.PerMyriadSign==0 { return '‱' }
+        return ls.PerMyriadSign
+    }
+    return 0
+}
+
+func (ls *LocSymbols) percentPattern() string {
+    if ls.PercentPattern=="" { return "#%" }
+    return ls.PercentPattern
+}
+
+// symbols of built-in locales
+var builtinLocaleSymbols map[string]LocSymbols = map[string]LocSymbols{
+    "ar": LocSymbols{ PercentSign: '٪', PermilleSign: '؉', BidiMark: ALM,
+                Exponential: "أس" },
+    "bg": LocSymbols{ PercentPattern: "#%",
+                CurrencyPattern: "#\u00a0¤" },
+    "ca": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "cs": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "da": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "de": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "es": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "et": LocSymbols{ MinusSign: '−', PercentPattern: "#%",
+                CurrencyPattern: "#\u00a0¤" },
+    "fa": LocSymbols{ MinusSign: '−', PercentSign: '٪', PermilleSign: '؉',
+                BidiMark: LRM, Exponential: "×۱۰^" },
+    "fi": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "fr": LocSymbols{ PercentPattern: "# %",
+                CurrencyPattern: "#\u00a0¤" },
+    "he": LocSymbols{ BidiMark: LRM },
+    "hr": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "hu": LocSymbols{ CurrencyPattern: "#\u00a0¤" },
+    "it": LocSymbols{ CurrencyPattern: "#\u00a0¤" },
+    "kk": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "ky": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "lt": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "nb": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "no": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "nl": LocSymbols{ CurrencyPattern: "¤\u00a0#" },
+    "pl": LocSymbols{ CurrencyPattern: "#\u00a0¤" },
+    "pt": LocSymbols{ CurrencyPattern: "¤\u00a0#" },
+    "ro": LocSymbols{ CurrencyPattern: "#\u00a0¤" },
+    "ru": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "sk": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "sl": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "sq": LocSymbols{ PercentPattern: "#\u00a0%",
+                CurrencyPattern: "#\u00a0¤" },
+    "sv": LocSymbols{ MinusSign: '−', PercentPattern: "#\u00a0%",
+                Exponential: "×10^", CurrencyPattern: "#\u00a0¤" },
+    "tr": LocSymbols{ PercentPattern: "%#" },
+    "uk": LocSymbols{ PercentPattern: "#%",
+                CurrencyPattern: "#\u00a0¤" },
+    "ur": LocSymbols{ BidiMark: LRM },
+}
+
+// move decimal point in number formatted by AppendFormat to right by n digits.
+// if point is moved to the end and keepFrac is set then ".0" is kept.
+func shiftPointRight(dst, s []byte, n int, keepFrac bool) []byte {
+    commaIdx := bytes.IndexByte(s, '.')
+    if commaIdx==-1 { commaIdx = len(s) }
+    intPart := s[:commaIdx]
+    var frac []byte
+    if commaIdx<len(s) { frac = s[commaIdx+1:] }
+    start := len(dst)
+    dst = append(dst, intPart...)
+    for i := 0; i < n; i++ {
+        if i<len(frac) {
+            dst = append(dst, frac[i])
+        } else {
+            dst = append(dst, '0')
+        }
+    }
+    // skip leading zeroes
+    nz := start
+    for nz+1<len(dst) && dst[nz]=='0' { nz++ }
+    if nz!=start { dst = append(dst[:start], dst[nz:]...) }
+    if n<len(frac) {
+        dst = append(dst, '.')
+        dst = append(dst, frac[n:]...)
+    } else if keepFrac {
+        dst = append(dst, '.', '0')
+    }
+    return dst
+}
+
+// move decimal point in ASCII number left by n digits
+func shiftPointLeft(s []byte, n int) []byte {
+    commaIdx := bytes.IndexByte(s, '.')
+    digits := make([]byte, 0, len(s)+n+1)
+    if commaIdx==-1 {
+        commaIdx = len(s)
+        digits = append(digits, s...)
+    } else {
+        digits = append(digits, s[:commaIdx]...)
+        digits = append(digits, s[commaIdx+1:]...)
+    }
+    pointPos := commaIdx-n
+    out := make([]byte, 0, len(digits)+n+2)
+    if pointPos<=0 {
+        out = append(out, '0', '.')
+        for ; pointPos<0; pointPos++ { out = append(out, '0') }
+        return append(out, digits...)
+    }
+    out = append(out, digits[:pointPos]...)
+    if pointPos<len(digits) {
+        out = append(out, '.')
+        out = append(out, digits[pointPos:]...)
+    }
+    return out
+}
+
+// append sign (with optional bidi mark) to dst
+func (ls *LocSymbols) appendSign(dst []byte, sign rune, bidi bool) []byte {
+    if bidi && ls.BidiMark!=0 { dst = appendRune(dst, ls.BidiMark) }
+    return appendRune(dst, sign)
+}
+
+// append prefix of number: sign (if placed before number) and
+// percent sign (if placed before number)
+func (ls *LocSymbols) appendPrefix(dst []byte, sign rune, style NumberStyle,
+                        bidi bool) []byte {
+    if sign!=0 && !ls.SignAfter { dst = ls.appendSign(dst, sign, bidi) }
+    if style!=StyleDecimal {
+        pattern := ls.percentPattern()
+        if i := strings.IndexByte(pattern, '#'); i>0 {
+            dst = ls.appendStylePart(dst, pattern[:i], style, bidi)
+        }
+    }
+    return dst
+}
+
+// append suffix of number: percent sign and sign (if placed after number)
+func (ls *LocSymbols) appendSuffix(dst []byte, sign rune, style NumberStyle,
+                        bidi bool) []byte {
+    if style!=StyleDecimal {
+        pattern := ls.percentPattern()
+        if i := strings.IndexByte(pattern, '#'); i!=-1 {
+            dst = ls.appendStylePart(dst, pattern[i+1:], style, bidi)
+        }
+    }
+    if sign!=0 && ls.SignAfter { dst = ls.appendSign(dst, sign, bidi) }
+    return dst
+}
+
+// append part of percent pattern with replaced percent sign
+func (ls *LocSymbols) appendStylePart(dst []byte, part string, style NumberStyle,
+                        bidi bool) []byte {
+    for _, r := range part {
+        if r=='%' {
+            if bidi && ls.BidiMark!=0 { dst = appendRune(dst, ls.BidiMark) }
+            dst = appendRune(dst, ls.styleSign(style))
+        } else {
+            dst = appendRune(dst, r)
+        }
+    }
+    return dst
+}
+
+// append formatted number with sign and style including locale to dst
+// and return extended buffer. If bidi is set then bidi mark of locale is
+// put before signs.
+func (a UDec128) AppendLocaleFormatStyle(dst []byte, lang string,
+                    precision, displayPrecision uint, trimZeroes, noSep1000 bool,
+                    negative bool, style NumberStyle, bidi bool) []byte {
+    l := getLocFmt(lang)
+    var sign rune
+    if negative { sign = l.Symbols.minusSign() }
+    dst = l.Symbols.appendPrefix(dst, sign, style, bidi)
+    var buf [128]byte
+    s := appendStyleNumber(buf[:0], a, precision, displayPrecision, trimZeroes,
+                           RoundDown, style)
+    dst = appendLocalized(dst, l, localeGrouping(l, noSep1000), l.FracGrouping, s)
+    return l.Symbols.appendSuffix(dst, sign, style, bidi)
+}
+
+// format number with sign and style including locale
+func (a UDec128) LocaleFormatStyle(lang string, precision, displayPrecision uint,
+                    trimZeroes, noSep1000, negative bool, style NumberStyle,
+                    bidi bool) string {
+    var buf [128]byte
+    return string(a.AppendLocaleFormatStyle(buf[:0], lang, precision,
+                displayPrecision, trimZeroes, noSep1000, negative, style, bidi))
+}
+
+// append number in ASCII formatted with style. displayPrecision is number
+// of digits in fraction of percent (or permille) value
+func appendStyleNumber(dst []byte, a UDec128, precision, displayPrecision uint,
+                    trimZeroes bool, mode RoundingMode, style NumberStyle) []byte {
+    shift := uint(style.shift())
+    if shift==0 {
+        return a.AppendFormatRound(dst, precision, displayPrecision, trimZeroes, mode)
+    }
+    var buf [64]byte
+    s := a.AppendFormatRound(buf[:0], precision, displayPrecision+shift,
+                             trimZeroes, mode)
+    return shiftPointRight(dst, s, int(shift), precision>shift)
+}
+
+// state of parsing number with locale
+type localeParseState struct {
+    os []byte
+    afterComma bool
+    // digit or comma has been found
+    started bool
+    // sign or style sign after number has been found
+    ended bool
+    negative, hasSign, hasStyleSign bool
+    // check positions of separators
+    strict bool
+    // position of current rune (in runes)
+    pos int
+    // positions of digits of integer part (only in strict mode)
+    intPos []int
+    // separators in integer part (only in strict mode)
+    seps []groupSep
+    // number of digits in fraction
+    fracDigits int
+    // previous rune was separator (only in strict mode)
+    lastSep, lastSepSpace, fracSepValid bool
+    lastSepPos int
+    // position of comma (only in strict mode)
+    commaPos int
+    // previous runes are spaces and position of first of them
+    // (only in strict mode)
+    lastSpace bool
+    spacePos int
+    // exponent found at end of number
+    exp int
+    hasExp bool
+}
+
+// return error for current rune
+func (st *localeParseState) fail(msg string) error {
+    return st.failAt(st.pos, msg)
+}
+
+// return error for rune at position. In strict mode ParseError is returned
+func (st *localeParseState) failAt(pos int, msg string) error {
+    if !st.strict { return strconv.ErrSyntax }
+    return &ParseError{ Pos: pos, Msg: msg }
+}
+
+// put digit to number
+func (st *localeParseState) putDigit(d byte) error {
+    if st.ended { return st.fail("digit after end of number") }
+    if st.strict && st.lastSep && st.afterComma && !st.fracSepValid {
+        return st.failAt(st.lastSepPos, "misplaced separator in fraction")
+    }
+    st.os = append(st.os, d)
+    st.started = true
+    if st.strict {
+        if st.afterComma {
+            st.fracDigits++
+        } else {
+            st.intPos = append(st.intPos, st.pos)
+        }
+    }
+    st.lastSep = false
+    return nil
+}
+
+// process rune of parsed number. return error if rune is not allowed.
+func (st *localeParseState) putRune(l *LocFmt, g Grouping, fg FracGrouping,
+                    style NumberStyle, r rune) error {
+    err := st.processRune(l, g, fg, style, r)
+    if st.strict {
+        if !isSpaceRune(r) {
+            st.lastSpace = false
+        } else if !st.lastSpace {
+            st.lastSpace = true
+            st.spacePos = st.pos
+        }
+    }
+    st.pos++
+    return err
+}
+
+func (st *localeParseState) processRune(l *LocFmt, g Grouping, fg FracGrouping,
+                    style NumberStyle, r rune) error {
+    ls := &l.Symbols
+    switch {
+    case r>='0' && r<='9':
+        // if standard digits
+        return st.putDigit(byte(r))
+    case r==LRM || r==RLM || r==ALM:
+        // skip bidi marks
+    case st.afterComma && !st.ended && fg.isSep(r):
+        // skip separator of fractional groups
+        if st.strict {
+            if st.lastSep || st.fracDigits==0 {
+                return st.fail("misplaced separator in fraction")
+            }
+            st.fracSepValid = fg.Size!=0 && st.fracDigits%int(fg.Size)==0
+            // space can be also put before sign
+            if !st.fracSepValid && !isSpaceRune(r) {
+                return st.fail("misplaced separator in fraction")
+            }
+            st.setLastSep(r)
+        }
+    case r==ls.minusSign() || r==ls.plusSign() || r=='-' || r=='+' || r=='−':
+        if st.hasSign { return st.fail("repeated sign") }
+        if err := st.endNumber(g); err!=nil { return err }
+        st.hasSign = true
+        st.negative = r!=ls.plusSign() && r!='+'
+    case style!=StyleDecimal && (r==ls.styleSign(style) ||
+                (style==StylePercent && (r=='%' || r=='٪'))):
+        if st.hasStyleSign { return st.fail("repeated percent sign") }
+        if err := st.endNumber(g); err!=nil { return err }
+        st.hasStyleSign = true
+    case isSpaceRune(r) && (!st.started || st.ended ||
+                (r!=l.Sep1000 && r!=l.Sep1000_2)):
+        // spaces between number and signs
+        return st.endNumber(g)
+    case r==l.Comma:
+        if st.ended { return st.fail("comma after end of number") }
+        if st.afterComma { return st.fail("repeated comma") }
+        if st.strict {
+            if st.lastSep { return st.failAt(st.lastSepPos, "separator before comma") }
+            if err := st.checkGrouping(g); err!=nil { return err }
+        }
+        st.os = append(st.os, '.')
+        st.commaPos = st.pos
+        st.afterComma = true
+        st.started = true
+    case r==l.Sep1000 || r==l.Sep1000_2:
+        // separators are not allowed without grouping
+        if !g.Enabled() || st.ended { return st.fail("unexpected separator") }
+        if st.strict {
+            if st.afterComma { return st.fail("separator after comma") }
+            st.seps = append(st.seps, groupSep{ len(st.intPos), st.pos })
+            st.setLastSep(r)
+        }
+    default:
+        // if non-standard digit
+        dig:=0
+        found := false
+        for ; dig<=9; dig++ {
+            if l.Digits[dig]==r {
+                found = true
+                break
+            }
+        }
+        if !found { return st.fail("invalid character") }
+        return st.putDigit('0'+byte(dig))
+    }
+    return nil
+}
+
+func (st *localeParseState) setLastSep(r rune) {
+    st.lastSep = true
+    st.lastSepSpace = isSpaceRune(r)
+    st.lastSepPos = st.pos
+}
+
+// mark end of number if sign, percent sign or space is after number
+func (st *localeParseState) endNumber(g Grouping) error {
+    if !st.started || st.ended { return nil }
+    st.ended = true
+    if !st.strict { return nil }
+    if st.lastSep {
+        if !st.lastSepSpace {
+            return st.failAt(st.lastSepPos, "separator at end of number")
+        }
+        // space between number and sign
+        if !st.afterComma { st.seps = st.seps[:len(st.seps)-1] }
+        st.lastSep = false
+    }
+    if !st.afterComma { return st.checkGrouping(g) }
+    return nil
+}
+
+// finish parsing and return value
+func (st *localeParseState) finish(g Grouping, style NumberStyle, precision uint,
+                    rounding bool) (UDec128, error) {
+    if err := st.endNumber(g); err!=nil { return UDec128{}, err }
+    if st.strict {
+        if len(st.intPos)+st.fracDigits==0 { return UDec128{}, st.failAt(0, "no digits") }
+        if st.afterComma && st.fracDigits==0 {
+            return UDec128{}, st.failAt(st.commaPos, "comma at end of number")
+        }
+        if st.lastSpace { return UDec128{}, st.failAt(st.spacePos, "trailing space") }
+    }
+    os := st.os
+    if shift := style.shift(); shift!=0 { os = shiftPointLeft(os, shift) }
+    if st.hasExp {
+        os = append(os, 'e')
+        os = strconv.AppendInt(os, int64(st.exp), 10)
+    }
+    return ParseUDec128Bytes(os, precision, rounding)
+}
+
+// parse number with sign and style including locale. Return value, true
+// if number is negative and error (nil if no error)
+func LocaleParseUDec128Style(lang, str string, precision uint, rounding bool,
+                    style NumberStyle) (UDec128, bool, error) {
+    l := getLocFmt(lang)
+    return localeParseUDec128Style(l, l.Grouping, l.FracGrouping, str,
+                                   precision, rounding, style, false)
+}
+
+func localeParseUDec128Style(l *LocFmt, g Grouping, fg FracGrouping, str string,
+                    precision uint, rounding bool,
+                    style NumberStyle, strict bool) (UDec128, bool, error) {
+    st := localeParseState{ os: make([]byte, 0, len(str)+8), strict: strict }
+    if len(str)==0 { return UDec128{}, false, st.fail("empty string") }
+    var n int
+    n, st.exp, st.hasExp = localeExponent(l, []byte(str))
+    for _, r := range str[:n] {
+        if err := st.putRune(l, g, fg, style, r); err!=nil {
+            return UDec128{}, false, err
+        }
+    }
+    v, err := st.finish(g, style, precision, rounding)
+    return v, st.negative, err
+}
+
+// parse number with sign and style including locale. Return value, true
+// if number is negative and error (nil if no error)
+func LocaleParseUDec128StyleBytes(lang string, str []byte, precision uint,
+                    rounding bool, style NumberStyle) (UDec128, bool, error) {
+    l := getLocFmt(lang)
+    return localeParseUDec128StyleBytes(l, l.Grouping, l.FracGrouping, str,
+                                        precision, rounding, style, false)
+}
+
+func localeParseUDec128StyleBytes(l *LocFmt, g Grouping, fg FracGrouping,
+                    strInput []byte, precision uint, rounding bool,
+                    style NumberStyle, strict bool) (UDec128, bool, error) {
+    st := localeParseState{ os: make([]byte, 0, len(strInput)+8), strict: strict }
+    if len(strInput)==0 { return UDec128{}, false, st.fail("empty string") }
+    var n int
+    n, st.exp, st.hasExp = localeExponent(l, strInput)
+    str := strInput[:n]
+    for len(str)>0 {
+        r, size := utf8.DecodeRune(str)
+        if err := st.putRune(l, g, fg, style, r); err!=nil {
+            return UDec128{}, false, err
+        }
+        str = str[size:]
+    }
+    v, err := st.finish(g, style, precision, rounding)
+    return v, st.negative, err
+}
@@ -0,0 +1,236 @@
+/*
+ * signs_test.go - tests for locale signs, percent and permille
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "strconv"
+    "testing"
+)
+
+type ShiftPointTC struct {
+    s string
+    n int
+    keepFrac bool
+    expected string
+}
+
+func TestShiftPointRight(t *testing.T) {
+    testCases := []ShiftPointTC {
+        ShiftPointTC{ "0.125", 2, true, "12.5" },
+        ShiftPointTC{ "0.12", 2, true, "12.0" },
+        ShiftPointTC{ "0.12", 2, false, "12" },
+        ShiftPointTC{ "0.001", 2, true, "0.1" },
+        ShiftPointTC{ "1.5", 3, false, "1500" },
+        ShiftPointTC{ "123", 2, false, "12300" },
+        ShiftPointTC{ "0.0", 2, true, "0.0" },
+    }
+    for i, tc := range testCases {
+        result := string(shiftPointRight(nil, []byte(tc.s), tc.n, tc.keepFrac))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: shiftRight(%v,%v)->%v!=%v",
+                     i, tc.s, tc.n, tc.expected, result)
+        }
+    }
+}
+
+func TestShiftPointLeft(t *testing.T) {
+    testCases := []ShiftPointTC {
+        ShiftPointTC{ "12.5", 2, false, "0.125" },
+        ShiftPointTC{ "12", 2, false, "0.12" },
+        ShiftPointTC{ "1", 3, false, "0.001" },
+        ShiftPointTC{ "1234.5", 2, false, "12.345" },
+        ShiftPointTC{ "12345", 4, false, "1.2345" },
+        ShiftPointTC{ ".5", 2, false, "0.005" },
+    }
+    for i, tc := range testCases {
+        result := string(shiftPointLeft([]byte(tc.s), tc.n))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: shiftLeft(%v,%v)->%v!=%v",
+                     i, tc.s, tc.n, tc.expected, result)
+        }
+    }
+}
+
+type UDec128StyleTC struct {
+    lang string
+    a UDec128
+    precision, displayPrecision uint
+    negative bool
+    style NumberStyle
+    bidi bool
+    expected string
+}
+
+func TestUDec128LocaleFormatStyle(t *testing.T) {
+    testCases := []UDec128StyleTC {
+        UDec128StyleTC{ "en", UDec128{ 1234500, 0 }, 3, 1, true, StyleDecimal, false,
+                    "-1,234.5" },
+        UDec128StyleTC{ "en", UDec128{ 125, 0 }, 3, 1, false, StylePercent, false,
+                    "12.5%" },
+        UDec128StyleTC{ "fr", UDec128{ 125, 0 }, 3, 1, false, StylePercent, false,
+                    "12,5\u202f%" },
+        UDec128StyleTC{ "de", UDec128{ 125, 0 }, 3, 1, true, StylePercent, false,
+                    "-12,5\u00a0%" },
+        UDec128StyleTC{ "tr", UDec128{ 125, 0 }, 3, 1, false, StylePercent, false,
+                    "%12,5" },
+        UDec128StyleTC{ "en", UDec128{ 125, 0 }, 4, 1, false, StylePermille, false,
+                    "12.5‰" },
+        UDec128StyleTC{ "en", UDec128{ 125, 0 }, 4, 0, false, StyleBasisPoints, false,
+                    "125‱" },
+        UDec128StyleTC{ "en", UDec128{ 5, 0 }, 1, 0, false, StylePercent, false,
+                    "50%" },
+        UDec128StyleTC{ "sv", UDec128{ 12345678, 0 }, 3, 3, true, StyleDecimal, false,
+                    "−12\u00a0345,678" },
+        UDec128StyleTC{ "he", UDec128{ 1234500, 0 }, 3, 1, true, StyleDecimal, true,
+                    "\u200e-1,234.5" },
+        UDec128StyleTC{ "he", UDec128{ 1234500, 0 }, 3, 1, true, StyleDecimal, false,
+                    "-1,234.5" },
+        UDec128StyleTC{ "ar", UDec128{ 125, 0 }, 3, 1, true, StylePercent, true,
+                    "\u061c-١٢٫٥\u061c٪" },
+    }
+    for i, tc := range testCases {
+        a := tc.a
+        result := tc.a.LocaleFormatStyle(tc.lang, tc.precision, tc.displayPrecision,
+                        false, false, tc.negative, tc.style, tc.bidi)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: fmt(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.a, tc.expected, result)
+        }
+        if tc.a!=a {
+            t.Errorf("Argument has been modified: %d: %v!=%v", i, a, tc.a)
+        }
+        v, negative, err := LocaleParseUDec128Style(tc.lang, result, tc.precision,
+                        false, tc.style)
+        if v!=tc.a || negative!=tc.negative || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%q)->%v,%v,%v",
+                     i, result, v, negative, err)
+        }
+        v, negative, err = LocaleParseUDec128StyleBytes(tc.lang, []byte(result),
+                        tc.precision, false, tc.style)
+        if v!=tc.a || negative!=tc.negative || err!=nil {
+            t.Errorf("Result mismatch: %d: parseBytes(%q)->%v,%v,%v",
+                     i, result, v, negative, err)
+        }
+    }
+}
+
+type UDec128ParseStyleTC struct {
+    lang string
+    str string
+    precision uint
+    style NumberStyle
+    expected UDec128
+    negative bool
+    err error
+}
+
+func TestLocaleParseUDec128Style(t *testing.T) {
+    testCases := []UDec128ParseStyleTC {
+        UDec128ParseStyleTC{ "en", "+12.5", 1, StyleDecimal, UDec128{ 125, 0 },
+                    false, nil },
+        UDec128ParseStyleTC{ "en", "12.5-", 1, StyleDecimal, UDec128{ 125, 0 },
+                    true, nil },
+        UDec128ParseStyleTC{ "en", "−12.5", 1, StyleDecimal, UDec128{ 125, 0 },
+                    true, nil },
+        UDec128ParseStyleTC{ "fr", "12,5 %", 3, StylePercent, UDec128{ 125, 0 },
+                    false, nil },
+        UDec128ParseStyleTC{ "en", "12.5", 3, StylePercent, UDec128{ 125, 0 },
+                    false, nil },
+        UDec128ParseStyleTC{ "en", "12.5%", 1, StyleDecimal, UDec128{},
+                    false, strconv.ErrSyntax },
+        UDec128ParseStyleTC{ "en", "--12.5", 1, StyleDecimal, UDec128{},
+                    false, strconv.ErrSyntax },
+        UDec128ParseStyleTC{ "en", "12%5", 3, StylePercent, UDec128{},
+                    false, strconv.ErrSyntax },
+        UDec128ParseStyleTC{ "en", "1-2", 1, StyleDecimal, UDec128{},
+                    false, strconv.ErrSyntax },
+    }
+    for i, tc := range testCases {
+        result, negative, err := LocaleParseUDec128Style(tc.lang, tc.str,
+                        tc.precision, false, tc.style)
+        if tc.expected!=result || tc.negative!=negative || tc.err!=err {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v,%v,%v!=%v,%v,%v",
+                     i, tc.str, tc.expected, tc.negative, tc.err,
+                     result, negative, err)
+        }
+    }
+    // unsigned parsing accepts plus sign and bidi marks
+    if v, err := LocaleParseUDec128("he", "\u200e+1,234.5", 1, false);
+            v!=(UDec128{ 12345, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: parse plus: %v,%v", v, err)
+    }
+    if _, err := LocaleParseUDec128("en", "-1,234.5", 1, false); err!=strconv.ErrSyntax {
+        t.Errorf("Negative value accepted: %v", err)
+    }
+}
+
+func TestFormatterSigned(t *testing.T) {
+    f := NewFormatter(FormatterOptions{ Lang: "ar", Precision: 3, DisplayPrecision: 1,
+                Style: StylePercent, BidiMarks: true })
+    if s := f.FormatSigned(UDec128{ 125, 0 }, true); s!="\u061c-١٢٫٥\u061c٪" {
+        t.Errorf("Result mismatch: ar: %q", s)
+    }
+    f = NewFormatter(FormatterOptions{ Precision: 3, DisplayPrecision: 3,
+                Width: 10, PadChar: '0' })
+    if s := f.FormatSigned(UDec128{ 1234500, 0 }, true); s!="-01234.500" {
+        t.Errorf("Result mismatch: zero padding: %q", s)
+    }
+    f = NewFormatter(FormatterOptions{ Lang: "sv", Precision: 2, DisplayPrecision: 2,
+                PlusSign: true })
+    if s := f.FormatSigned(UDec128{ 125, 0 }, true); s!="−1,25" {
+        t.Errorf("Result mismatch: sv: %q", s)
+    }
+    p := NewParser(ParserOptions{ Lang: "fr", Precision: 3, Style: StylePercent })
+    if v, negative, err := p.ParseSigned("-12,5\u202f%");
+            v!=(UDec128{ 125, 0 }) || !negative || err!=nil {
+        t.Errorf("Result mismatch: parse fr: %v,%v,%v", v, negative, err)
+    }
+    if _, err := p.Parse("-12,5\u202f%"); err!=strconv.ErrSyntax {
+        t.Errorf("Negative value accepted: %v", err)
+    }
+    p = NewParser(ParserOptions{ Precision: 2 })
+    if v, negative, err := p.ParseSignedBytes([]byte("-1.25"));
+            v!=(UDec128{ 125, 0 }) || !negative || err!=nil {
+        t.Errorf("Result mismatch: parse: %v,%v,%v", v, negative, err)
+    }
+}
+
+type CLDRPercentPatternTC struct {
+    pattern string
+    expected string
+}
+
+func TestCLDRPercentPattern(t *testing.T) {
+    testCases := []CLDRPercentPatternTC {
+        CLDRPercentPatternTC{ "#,##0%", "#%" },
+        CLDRPercentPatternTC{ "#,##0\u00a0%", "#\u00a0%" },
+        CLDRPercentPatternTC{ "%#,##0", "%#" },
+        CLDRPercentPatternTC{ "\u200e%\u200e#,##0;-#,##0%", "%#" },
+    }
+    for i, tc := range testCases {
+        result := cldrPercentPattern(tc.pattern)
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: pattern(%q)->%q!=%q",
+                     i, tc.pattern, tc.expected, result)
+        }
+    }
+}
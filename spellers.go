@@ -0,0 +1,619 @@
+/*
+ * spellers.go - built-in spellers of numbers in words
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+var builtinSpellers map[string]Speller = map[string]Speller{
+    "en": englishSpeller{},
+    "en-IN": englishSpeller{ indian: true },
+    "de": germanSpeller{},
+    "fr": frenchSpeller{},
+    "es": spanishSpeller{},
+    "pl": polishSpeller{},
+}
+
+// English
+
+var englishOnes = [20]string{ "zero", "one", "two", "three", "four", "five", "six",
+    "seven", "eight", "nine", "ten", "eleven", "twelve", "thirteen", "fourteen",
+    "fifteen", "sixteen", "seventeen", "eighteen", "nineteen" }
+
+var englishTens = [10]string{ "", "", "twenty", "thirty", "forty", "fifty", "sixty",
+    "seventy", "eighty", "ninety" }
+
+// short scale: 10^3, 10^6, ..., 10^36
+var englishScales = [13]string{ "", "thousand", "million", "billion", "trillion",
+    "quadrillion", "quintillion", "sextillion", "septillion", "octillion",
+    "nonillion", "decillion", "undecillion" }
+
+type englishSpeller struct {
+    // Indian numbering system (lakh, crore)
+    indian bool
+}
+
+// append number from 1 to 999
+func appendEnglish999(dst []byte, start, n int) []byte {
+    if n>=100 {
+        dst = appendWord(dst, start, englishOnes[n/100])
+        dst = appendWord(dst, start, "hundred")
+        n %= 100
+    }
+    if n==0 { return dst }
+    if n<20 { return appendWord(dst, start, englishOnes[n]) }
+    dst = appendWord(dst, start, englishTens[n/10])
+    if n%10!=0 {
+        dst = append(dst, '-')
+        dst = append(dst, englishOnes[n%10]...)
+    }
+    return dst
+}
+
+// append number in Indian numbering system: thousand, lakh, crore,
+// higher parts are counted in crores (like 'one lakh crore')
+func appendIndianEnglish(dst []byte, start int, digits []byte) []byte {
+    if len(digits)>7 {
+        crores := digits[:len(digits)-7]
+        if !isZeroDigits(crores) {
+            dst = appendIndianEnglish(dst, start, crores)
+            dst = appendWord(dst, start, "crore")
+        }
+        digits = digits[len(digits)-7:]
+    }
+    v := digitGroups(digits, 7)[0]
+    if n := v/100000; n!=0 {
+        dst = appendEnglish999(dst, start, n)
+        dst = appendWord(dst, start, "lakh")
+    }
+    if n := (v/1000)%100; n!=0 {
+        dst = appendEnglish999(dst, start, n)
+        dst = appendWord(dst, start, "thousand")
+    }
+    return appendEnglish999(dst, start, v%1000)
+}
+
+func (s englishSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    if isZeroDigits(digits) { return append(dst, englishOnes[0]...) }
+    start := len(dst)
+    if s.indian { return appendIndianEnglish(dst, start, digits) }
+    groups := digitGroups(digits, 3)
+    for i := len(groups)-1; i>=0; i-- {
+        if groups[i]==0 { continue }
+        dst = appendEnglish999(dst, start, groups[i])
+        if i!=0 { dst = appendWord(dst, start, englishScales[i]) }
+    }
+    return dst
+}
+
+func (s englishSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    c := PluralOther
+    if _, one := lastDigits(digits, 1); one { c = PluralOne }
+    dst = append(dst, ' ')
+    return append(dst, unit.Form(c)...)
+}
+
+func (s englishSpeller) Conjunction() string {
+    return "and"
+}
+
+// German
+
+var germanOnes = [20]string{ "null", "eins", "zwei", "drei", "vier", "fünf", "sechs",
+    "sieben", "acht", "neun", "zehn", "elf", "zwölf", "dreizehn", "vierzehn",
+    "fünfzehn", "sechzehn", "siebzehn", "achtzehn", "neunzehn" }
+
+var germanTens = [10]string{ "", "", "zwanzig", "dreißig", "vierzig", "fünfzig",
+    "sechzig", "siebzig", "achtzig", "neunzig" }
+
+// singular and plural of scales 10^6, 10^9, ..., 10^36
+var germanScales = [13][2]string{ {}, {},
+    { "Million", "Millionen" }, { "Milliarde", "Milliarden" },
+    { "Billion", "Billionen" }, { "Billiarde", "Billiarden" },
+    { "Trillion", "Trillionen" }, { "Trilliarde", "Trilliarden" },
+    { "Quadrillion", "Quadrillionen" }, { "Quadrilliarde", "Quadrilliarden" },
+    { "Quintillion", "Quintillionen" }, { "Quintilliarde", "Quintilliarden" },
+    { "Sextillion", "Sextillionen" } }
+
+type germanSpeller struct{}
+
+// append number from 1 to 999 as one word. one is form of final one
+func appendGerman999(dst []byte, n int, one string) []byte {
+    if n>=100 {
+        if n/100==1 {
+            dst = append(dst, "ein"...)
+        } else {
+            dst = append(dst, germanOnes[n/100]...)
+        }
+        dst = append(dst, "hundert"...)
+        n %= 100
+    }
+    switch {
+    case n==0:
+    case n==1:
+        dst = append(dst, one...)
+    case n<20:
+        dst = append(dst, germanOnes[n]...)
+    default:
+        if u := n%10; u==1 {
+            dst = append(dst, "einund"...)
+        } else if u!=0 {
+            dst = append(dst, germanOnes[u]...)
+            dst = append(dst, "und"...)
+        }
+        dst = append(dst, germanTens[n/10]...)
+    }
+    return dst
+}
+
+func (s germanSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    if isZeroDigits(digits) { return append(dst, germanOnes[0]...) }
+    start := len(dst)
+    groups := digitGroups(digits, 3)
+    for i := len(groups)-1; i>=2; i-- {
+        g := groups[i]
+        if g==0 { continue }
+        if len(dst)>start { dst = append(dst, ' ') }
+        if g==1 {
+            dst = append(dst, "eine "...)
+            dst = append(dst, germanScales[i][0]...)
+        } else {
+            dst = appendGerman999(dst, g, "eine")
+            dst = append(dst, ' ')
+            dst = append(dst, germanScales[i][1]...)
+        }
+    }
+    // thousands and units are written as one word
+    if len(dst)>start && (len(groups)>1 && groups[1]!=0 || groups[0]!=0) {
+        dst = append(dst, ' ')
+    }
+    if len(groups)>1 && groups[1]!=0 {
+        dst = appendGerman999(dst, groups[1], "ein")
+        dst = append(dst, "tausend"...)
+    }
+    one := "ein"
+    switch gender {
+    case GenderFeminine:
+        one = "eine"
+    case GenderNone:
+        one = "eins"
+    }
+    return appendGerman999(dst, groups[0], one)
+}
+
+func (s germanSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    c := PluralOther
+    if _, one := lastDigits(digits, 1); one { c = PluralOne }
+    dst = append(dst, ' ')
+    return append(dst, unit.Form(c)...)
+}
+
+func (s germanSpeller) Conjunction() string {
+    return "und"
+}
+
+// French
+
+var frenchOnes = [17]string{ "zéro", "un", "deux", "trois", "quatre", "cinq", "six",
+    "sept", "huit", "neuf", "dix", "onze", "douze", "treize", "quatorze", "quinze",
+    "seize" }
+
+var frenchTens = [7]string{ "", "", "vingt", "trente", "quarante", "cinquante",
+    "soixante" }
+
+// scales 10^6, 10^9, ..., 10^36 (plural by adding 's')
+var frenchScales = [13]string{ "", "mille", "million", "milliard", "billion",
+    "billiard", "trillion", "trilliard", "quadrillion", "quadrilliard",
+    "quintillion", "quintilliard", "sextillion" }
+
+type frenchSpeller struct{}
+
+// append number from 1 to 99. one is form of final one. If final is set
+// then 'quatre-vingts' has plural form
+func appendFrench99(dst []byte, n int, one string, final bool) []byte {
+    switch {
+    case n==1:
+        return append(dst, one...)
+    case n<17:
+        return append(dst, frenchOnes[n]...)
+    case n<20:
+        dst = append(dst, "dix-"...)
+        return append(dst, frenchOnes[n-10]...)
+    case n<70:
+        dst = append(dst, frenchTens[n/10]...)
+        if u := n%10; u==1 {
+            dst = append(dst, " et "...)
+            dst = append(dst, one...)
+        } else if u!=0 {
+            dst = append(dst, '-')
+            dst = append(dst, frenchOnes[u]...)
+        }
+        return dst
+    case n<80:
+        dst = append(dst, "soixante"...)
+        if n==71 { return append(dst, " et onze"...) }
+        dst = append(dst, '-')
+        return appendFrench99(dst, n-60, one, final)
+    }
+    dst = append(dst, "quatre-vingt"...)
+    if n==80 {
+        if final { dst = append(dst, 's') }
+        return dst
+    }
+    dst = append(dst, '-')
+    return appendFrench99(dst, n-80, one, final)
+}
+
+// append number from 1 to 999
+func appendFrench999(dst []byte, start, n int, one string, final bool) []byte {
+    if n>=100 {
+        if n/100>1 { dst = appendWord(dst, start, frenchOnes[n/100]) }
+        dst = appendWord(dst, start, "cent")
+        if n%100==0 && n/100>1 && final { dst = append(dst, 's') }
+        n %= 100
+    }
+    if n==0 { return dst }
+    if len(dst)>start { dst = append(dst, ' ') }
+    return appendFrench99(dst, n, one, final)
+}
+
+func (s frenchSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    if isZeroDigits(digits) { return append(dst, frenchOnes[0]...) }
+    start := len(dst)
+    groups := digitGroups(digits, 3)
+    for i := len(groups)-1; i>=0; i-- {
+        g := groups[i]
+        switch {
+        case g==0:
+        case i==0:
+            one := "un"
+            if gender==GenderFeminine { one = "une" }
+            dst = appendFrench999(dst, start, g, one, true)
+        case i==1:
+            // 'mille' is invariable and is put without 'un'
+            if g!=1 { dst = appendFrench999(dst, start, g, "un", false) }
+            dst = appendWord(dst, start, frenchScales[1])
+        default:
+            dst = appendFrench999(dst, start, g, "un", true)
+            dst = appendWord(dst, start, frenchScales[i])
+            if g>1 { dst = append(dst, 's') }
+        }
+    }
+    return dst
+}
+
+// return true if word starts with vowel or 'h' (elision of 'de')
+func frenchElides(word string) bool {
+    for _, r := range word {
+        switch r {
+        case 'a', 'e', 'i', 'o', 'u', 'y', 'h', 'â', 'é', 'è', 'ê', 'î', 'ô', 'û',
+                'A', 'E', 'I', 'O', 'U', 'Y', 'H', 'É':
+            return true
+        }
+        return false
+    }
+    return false
+}
+
+// return true if number is multiple of million ('un million de', 'un millón de')
+func isMillionMultiple(digits []byte) bool {
+    return len(digits)>6 && isZeroDigits(digits[len(digits)-6:])
+}
+
+func (s frenchSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    c := PluralOther
+    // zero and one are singular
+    if v, _ := lastDigits(digits, 2); len(digits)==1 && v<=1 { c = PluralOne }
+    name := unit.Form(c)
+    if isMillionMultiple(digits) {
+        if frenchElides(name) {
+            dst = append(dst, " d'"...)
+            return append(dst, name...)
+        }
+        dst = append(dst, " de"...)
+    }
+    dst = append(dst, ' ')
+    return append(dst, name...)
+}
+
+func (s frenchSpeller) Conjunction() string {
+    return "et"
+}
+
+// Spanish
+
+var spanishOnes = [30]string{ "cero", "uno", "dos", "tres", "cuatro", "cinco", "seis",
+    "siete", "ocho", "nueve", "diez", "once", "doce", "trece", "catorce", "quince",
+    "dieciséis", "diecisiete", "dieciocho", "diecinueve", "veinte", "veintiuno",
+    "veintidós", "veintitrés", "veinticuatro", "veinticinco", "veintiséis",
+    "veintisiete", "veintiocho", "veintinueve" }
+
+var spanishTens = [10]string{ "", "", "", "treinta", "cuarenta", "cincuenta",
+    "sesenta", "setenta", "ochenta", "noventa" }
+
+// masculine and feminine hundreds
+var spanishHundreds = [10][2]string{ {}, { "ciento", "ciento" },
+    { "doscientos", "doscientas" }, { "trescientos", "trescientas" },
+    { "cuatrocientos", "cuatrocientas" }, { "quinientos", "quinientas" },
+    { "seiscientos", "seiscientas" }, { "setecientos", "setecientas" },
+    { "ochocientos", "ochocientas" }, { "novecientos", "novecientas" } }
+
+// long scale: singular and plural of 10^6, 10^12, ..., 10^36
+var spanishScales = [7][2]string{ {}, { "millón", "millones" },
+    { "billón", "billones" }, { "trillón", "trillones" },
+    { "cuatrillón", "cuatrillones" }, { "quintillón", "quintillones" },
+    { "sextillón", "sextillones" } }
+
+type spanishSpeller struct{}
+
+// form of one: 'un' before noun, 'una' before feminine noun,
+// 'uno' without noun
+const (
+    spanishUn = iota
+    spanishUna
+    spanishUno
+)
+
+// append number from 1 to 99
+func appendSpanish99(dst []byte, n int, form int) []byte {
+    switch {
+    case n==1 || n==21:
+        if n==21 { dst = append(dst, "veinti"...) }
+        switch {
+        case form==spanishUna:
+            return append(dst, "una"...)
+        case form==spanishUno:
+            return append(dst, "uno"...)
+        case n==21:
+            return append(dst, "ún"...)
+        }
+        return append(dst, "un"...)
+    case n<30:
+        return append(dst, spanishOnes[n]...)
+    }
+    dst = append(dst, spanishTens[n/10]...)
+    if u := n%10; u!=0 {
+        dst = append(dst, " y "...)
+        if u==1 { return appendSpanish99(dst, u, form) }
+        dst = append(dst, spanishOnes[u]...)
+    }
+    return dst
+}
+
+// append number from 1 to 999
+func appendSpanish999(dst []byte, start, n int, form int) []byte {
+    if n==100 { return appendWord(dst, start, "cien") }
+    if n>=100 {
+        h := 0
+        if form==spanishUna { h = 1 }
+        dst = appendWord(dst, start, spanishHundreds[n/100][h])
+        n %= 100
+    }
+    if n==0 { return dst }
+    if len(dst)>start { dst = append(dst, ' ') }
+    return appendSpanish99(dst, n, form)
+}
+
+// append number from 1 to 999999
+func appendSpanish6(dst []byte, start, n int, form int) []byte {
+    if t := n/1000; t==1 {
+        dst = appendWord(dst, start, "mil")
+    } else if t!=0 {
+        // 'un' is used before 'mil' (like 'veintiún mil')
+        tform := form
+        if tform==spanishUno { tform = spanishUn }
+        dst = appendSpanish999(dst, start, t, tform)
+        dst = appendWord(dst, start, "mil")
+    }
+    if n%1000==0 { return dst }
+    return appendSpanish999(dst, start, n%1000, form)
+}
+
+func (s spanishSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    if isZeroDigits(digits) { return append(dst, spanishOnes[0]...) }
+    start := len(dst)
+    groups := digitGroups(digits, 6)
+    for i := len(groups)-1; i>=1; i-- {
+        g := groups[i]
+        if g==0 { continue }
+        if g==1 {
+            dst = appendWord(dst, start, "un")
+            dst = appendWord(dst, start, spanishScales[i][0])
+        } else {
+            dst = appendSpanish6(dst, start, g, spanishUn)
+            dst = appendWord(dst, start, spanishScales[i][1])
+        }
+    }
+    form := spanishUn
+    switch gender {
+    case GenderFeminine:
+        form = spanishUna
+    case GenderNone:
+        form = spanishUno
+    }
+    if groups[0]!=0 { dst = appendSpanish6(dst, start, groups[0], form) }
+    return dst
+}
+
+func (s spanishSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    c := PluralOther
+    if _, one := lastDigits(digits, 1); one { c = PluralOne }
+    if isMillionMultiple(digits) { dst = append(dst, " de"...) }
+    dst = append(dst, ' ')
+    return append(dst, unit.Form(c)...)
+}
+
+func (s spanishSpeller) Conjunction() string {
+    return "con"
+}
+
+// Polish
+
+var polishOnes = [20]string{ "zero", "jeden", "dwa", "trzy", "cztery", "pięć", "sześć",
+    "siedem", "osiem", "dziewięć", "dziesięć", "jedenaście", "dwanaście",
+    "trzynaście", "czternaście", "piętnaście", "szesnaście", "siedemnaście",
+    "osiemnaście", "dziewiętnaście" }
+
+var polishTens = [10]string{ "", "", "dwadzieścia", "trzydzieści", "czterdzieści",
+    "pięćdziesiąt", "sześćdziesiąt", "siedemdziesiąt", "osiemdziesiąt",
+    "dziewięćdziesiąt" }
+
+var polishHundreds = [10]string{ "", "sto", "dwieście", "trzysta", "czterysta",
+    "pięćset", "sześćset", "siedemset", "osiemset", "dziewięćset" }
+
+// genitive forms (one is 'jeden' in compound numbers)
+var polishOnesGenitive = [20]string{ "zera", "jeden", "dwóch", "trzech", "czterech",
+    "pięciu", "sześciu", "siedmiu", "ośmiu", "dziewięciu", "dziesięciu",
+    "jedenastu", "dwunastu", "trzynastu", "czternastu", "piętnastu", "szesnastu",
+    "siedemnastu", "osiemnastu", "dziewiętnastu" }
+
+var polishTensGenitive = [10]string{ "", "", "dwudziestu", "trzydziestu",
+    "czterdziestu", "pięćdziesięciu", "sześćdziesięciu", "siedemdziesięciu",
+    "osiemdziesięciu", "dziewięćdziesięciu" }
+
+var polishHundredsGenitive = [10]string{ "", "stu", "dwustu", "trzystu", "czterystu",
+    "pięciuset", "sześciuset", "siedmiuset", "ośmiuset", "dziewięciuset" }
+
+// forms of scales 10^3, 10^6, ..., 10^36 for one, few and many
+var polishScales = [13][3]string{ {},
+    { "tysiąc", "tysiące", "tysięcy" },
+    { "milion", "miliony", "milionów" }, { "miliard", "miliardy", "miliardów" },
+    { "bilion", "biliony", "bilionów" }, { "biliard", "biliardy", "biliardów" },
+    { "trylion", "tryliony", "trylionów" }, { "tryliard", "tryliardy", "tryliardów" },
+    { "kwadrylion", "kwadryliony", "kwadrylionów" },
+    { "kwadryliard", "kwadryliardy", "kwadryliardów" },
+    { "kwintylion", "kwintyliony", "kwintylionów" },
+    { "kwintyliard", "kwintyliardy", "kwintyliardów" },
+    { "sekstylion", "sekstyliony", "sekstylionów" } }
+
+// genitive singular of scales 10^3, 10^6, ..., 10^36 (genitive plural
+// is same as form for many)
+var polishScalesGenitive = [13]string{ "", "tysiąca", "miliona", "miliarda",
+    "biliona", "biliarda", "tryliona", "tryliarda", "kwadryliona", "kwadryliarda",
+    "kwintyliona", "kwintyliarda", "sekstyliona" }
+
+type polishSpeller struct{}
+
+// return plural category of number: one, few (2-4, 22-24, ...) or many.
+// v is value of last two digits
+func polishPlural(v int, one bool) PluralCategory {
+    if one { return PluralOne }
+    if u := v%10; u>=2 && u<=4 && (v%100<12 || v%100>14) { return PluralFew }
+    return PluralMany
+}
+
+// append number from 1 to 999
+func appendPolish999(dst []byte, start, n int, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    ones, tens, hundreds := &polishOnes, &polishTens, &polishHundreds
+    if gcase==CaseGenitive {
+        ones, tens, hundreds = &polishOnesGenitive, &polishTensGenitive,
+                &polishHundredsGenitive
+    }
+    if n>=100 {
+        dst = appendWord(dst, start, hundreds[n/100])
+        n %= 100
+    }
+    if n>=20 {
+        dst = appendWord(dst, start, tens[n/10])
+        n %= 10
+    }
+    switch {
+    case n==0:
+    case n==2 && gender==GenderFeminine && gcase==CaseNominative:
+        dst = appendWord(dst, start, "dwie")
+    default:
+        dst = appendWord(dst, start, ones[n])
+    }
+    return dst
+}
+
+// return form of scale i for group g
+func polishScale(i, g int, gcase GrammaticalCase) string {
+    if gcase==CaseGenitive {
+        if g==1 { return polishScalesGenitive[i] }
+        return polishScales[i][2]
+    }
+    return polishScales[i][polishPlural(g, g==1)-PluralOne]
+}
+
+func (s polishSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    if isZeroDigits(digits) {
+        if gcase==CaseGenitive { return append(dst, polishOnesGenitive[0]...) }
+        return append(dst, polishOnes[0]...)
+    }
+    if _, one := lastDigits(digits, 1); one {
+        if gcase==CaseGenitive {
+            if gender==GenderFeminine { return append(dst, "jednej"...) }
+            return append(dst, "jednego"...)
+        }
+        switch gender {
+        case GenderFeminine:
+            return append(dst, "jedna"...)
+        case GenderNeuter:
+            return append(dst, "jedno"...)
+        }
+        return append(dst, polishOnes[1]...)
+    }
+    start := len(dst)
+    groups := digitGroups(digits, 3)
+    for i := len(groups)-1; i>=1; i-- {
+        g := groups[i]
+        if g==0 { continue }
+        // 'tysiąc' and 'milion' are put without 'jeden'
+        if g!=1 { dst = appendPolish999(dst, start, g, GenderMasculine, gcase) }
+        dst = appendWord(dst, start, polishScale(i, g, gcase))
+    }
+    if groups[0]!=0 { dst = appendPolish999(dst, start, groups[0], gender, gcase) }
+    return dst
+}
+
+func (s polishSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    dst = append(dst, ' ')
+    c := polishPlural(lastDigits(digits, 2))
+    if gcase==CaseGenitive {
+        // noun is in genitive singular after one, otherwise in genitive plural
+        if c==PluralOne {
+            if unit.OneGenitive!="" { return append(dst, unit.OneGenitive...) }
+        } else {
+            c = PluralMany
+        }
+    }
+    return append(dst, unit.Form(c)...)
+}
+
+func (s polishSpeller) Conjunction() string {
+    return "i"
+}
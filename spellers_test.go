@@ -0,0 +1,223 @@
+/*
+ * spellers_test.go - tests for built-in spellers of numbers in words
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "testing"
+)
+
+type SpellerTC struct {
+    lang string
+    digits string
+    gender Gender
+    expected string
+}
+
+func TestSpellerAppendWords(t *testing.T) {
+    testCases := []SpellerTC {
+        SpellerTC{ "en", "0", GenderMasculine, "zero" },
+        SpellerTC{ "en", "115", GenderMasculine, "one hundred fifteen" },
+        SpellerTC{ "en", "1000001", GenderMasculine, "one million one" },
+        SpellerTC{ "en", "340282366920938463463374607431768211455", GenderMasculine,
+            "three hundred forty undecillion two hundred eighty-two decillion " +
+            "three hundred sixty-six nonillion nine hundred twenty octillion " +
+            "nine hundred thirty-eight septillion four hundred sixty-three " +
+            "sextillion four hundred sixty-three quintillion three hundred " +
+            "seventy-four quadrillion six hundred seven trillion four hundred " +
+            "thirty-one billion seven hundred sixty-eight million two hundred " +
+            "eleven thousand four hundred fifty-five" },
+        SpellerTC{ "en-IN", "123456789", GenderMasculine,
+            "twelve crore thirty-four lakh fifty-six thousand seven hundred eighty-nine" },
+        SpellerTC{ "en-IN", "100000000000", GenderMasculine, "ten thousand crore" },
+        SpellerTC{ "en-IN", "100000", GenderMasculine, "one lakh" },
+        SpellerTC{ "de", "0", GenderMasculine, "null" },
+        SpellerTC{ "de", "1", GenderMasculine, "ein" },
+        SpellerTC{ "de", "1", GenderFeminine, "eine" },
+        SpellerTC{ "de", "31", GenderMasculine, "einunddreißig" },
+        SpellerTC{ "de", "101", GenderNeuter, "einhundertein" },
+        SpellerTC{ "de", "1", GenderNone, "eins" },
+        SpellerTC{ "de", "101", GenderNone, "einhunderteins" },
+        SpellerTC{ "de", "1001", GenderNone, "eintausendeins" },
+        SpellerTC{ "de", "21", GenderNone, "einundzwanzig" },
+        SpellerTC{ "de", "1999", GenderMasculine,
+            "eintausendneunhundertneunundneunzig" },
+        SpellerTC{ "de", "1000000", GenderMasculine, "eine Million" },
+        SpellerTC{ "de", "2001000", GenderMasculine, "zwei Millionen eintausend" },
+        SpellerTC{ "de", "3000000000", GenderMasculine, "drei Milliarden" },
+        SpellerTC{ "fr", "21", GenderMasculine, "vingt et un" },
+        SpellerTC{ "fr", "21", GenderFeminine, "vingt et une" },
+        SpellerTC{ "fr", "71", GenderMasculine, "soixante et onze" },
+        SpellerTC{ "fr", "77", GenderMasculine, "soixante-dix-sept" },
+        SpellerTC{ "fr", "80", GenderMasculine, "quatre-vingts" },
+        SpellerTC{ "fr", "81", GenderMasculine, "quatre-vingt-un" },
+        SpellerTC{ "fr", "91", GenderMasculine, "quatre-vingt-onze" },
+        SpellerTC{ "fr", "200", GenderMasculine, "deux cents" },
+        SpellerTC{ "fr", "201", GenderMasculine, "deux cent un" },
+        SpellerTC{ "fr", "80000", GenderMasculine, "quatre-vingt mille" },
+        SpellerTC{ "fr", "200000", GenderMasculine, "deux cent mille" },
+        SpellerTC{ "fr", "1000", GenderMasculine, "mille" },
+        SpellerTC{ "fr", "80000000", GenderMasculine, "quatre-vingts millions" },
+        SpellerTC{ "es", "1", GenderMasculine, "un" },
+        SpellerTC{ "es", "1", GenderFeminine, "una" },
+        SpellerTC{ "es", "21", GenderMasculine, "veintiún" },
+        SpellerTC{ "es", "21", GenderFeminine, "veintiuna" },
+        SpellerTC{ "es", "100", GenderMasculine, "cien" },
+        SpellerTC{ "es", "101", GenderMasculine, "ciento un" },
+        SpellerTC{ "es", "1", GenderNone, "uno" },
+        SpellerTC{ "es", "21", GenderNone, "veintiuno" },
+        SpellerTC{ "es", "31", GenderNone, "treinta y uno" },
+        SpellerTC{ "es", "101", GenderNone, "ciento uno" },
+        SpellerTC{ "es", "1001", GenderNone, "mil uno" },
+        SpellerTC{ "es", "201", GenderNone, "doscientos uno" },
+        SpellerTC{ "es", "21000", GenderNone, "veintiún mil" },
+        SpellerTC{ "es", "1000001", GenderNone, "un millón uno" },
+        SpellerTC{ "es", "500", GenderFeminine, "quinientas" },
+        SpellerTC{ "es", "1000", GenderMasculine, "mil" },
+        SpellerTC{ "es", "2000", GenderMasculine, "dos mil" },
+        SpellerTC{ "es", "1000000", GenderMasculine, "un millón" },
+        SpellerTC{ "es", "1000000000", GenderMasculine, "mil millones" },
+        SpellerTC{ "es", "2000000000000", GenderMasculine, "dos billones" },
+        SpellerTC{ "pl", "1", GenderMasculine, "jeden" },
+        SpellerTC{ "pl", "1", GenderFeminine, "jedna" },
+        SpellerTC{ "pl", "1", GenderNeuter, "jedno" },
+        SpellerTC{ "pl", "2", GenderFeminine, "dwie" },
+        SpellerTC{ "pl", "12", GenderFeminine, "dwanaście" },
+        SpellerTC{ "pl", "1000", GenderMasculine, "tysiąc" },
+        SpellerTC{ "pl", "2000", GenderMasculine, "dwa tysiące" },
+        SpellerTC{ "pl", "5000", GenderMasculine, "pięć tysięcy" },
+        SpellerTC{ "pl", "12000", GenderMasculine, "dwanaście tysięcy" },
+        SpellerTC{ "pl", "22000", GenderMasculine, "dwadzieścia dwa tysiące" },
+        SpellerTC{ "pl", "1000001", GenderMasculine, "milion jeden" },
+        SpellerTC{ "pl", "2000000000", GenderMasculine, "dwa miliardy" },
+    }
+    for i, tc := range testCases {
+        sp, err := LookupSpeller(tc.lang)
+        if err!=nil {
+            t.Errorf("Unexpected error: %d: %v", i, err)
+            continue
+        }
+        result := string(sp.AppendWords(nil, []byte(tc.digits), tc.gender,
+                                        CaseNominative))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: words(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.digits, tc.expected, result)
+        }
+    }
+}
+
+type SpellerCountTC struct {
+    lang string
+    digits string
+    unit UnitName
+    expected string
+}
+
+func TestSpellerAppendCount(t *testing.T) {
+    euroFr := UnitName{ One: "euro", Other: "euros" }
+    livreFr := UnitName{ One: "livre", Other: "livres", Gender: GenderFeminine }
+    pesoEs := UnitName{ One: "peso", Other: "pesos" }
+    zlotyPl := UnitName{ One: "złoty", Few: "złote", Many: "złotych" }
+    koronaPl := UnitName{ One: "korona", Few: "korony", Many: "koron",
+                Gender: GenderFeminine }
+    testCases := []SpellerCountTC {
+        SpellerCountTC{ "de", "1", UnitName{ Other: "Euro" }, "ein Euro" },
+        SpellerCountTC{ "de", "1", UnitName{ Other: "Mark", Gender: GenderFeminine },
+            "eine Mark" },
+        SpellerCountTC{ "de", "2", UnitName{ One: "Franken", Other: "Franken" },
+            "zwei Franken" },
+        SpellerCountTC{ "fr", "0", euroFr, "zéro euro" },
+        SpellerCountTC{ "fr", "1", euroFr, "un euro" },
+        SpellerCountTC{ "fr", "2", euroFr, "deux euros" },
+        SpellerCountTC{ "fr", "1000000", euroFr, "un million d'euros" },
+        SpellerCountTC{ "fr", "2000000", livreFr, "deux millions de livres" },
+        SpellerCountTC{ "fr", "2000001", livreFr, "deux millions une livres" },
+        SpellerCountTC{ "fr", "21", livreFr, "vingt et une livres" },
+        SpellerCountTC{ "es", "1", pesoEs, "un peso" },
+        SpellerCountTC{ "es", "21", pesoEs, "veintiún pesos" },
+        SpellerCountTC{ "es", "1000000", pesoEs, "un millón de pesos" },
+        SpellerCountTC{ "es", "1000001", pesoEs, "un millón un pesos" },
+        SpellerCountTC{ "pl", "0", zlotyPl, "zero złotych" },
+        SpellerCountTC{ "pl", "1", zlotyPl, "jeden złoty" },
+        SpellerCountTC{ "pl", "2", zlotyPl, "dwa złote" },
+        SpellerCountTC{ "pl", "5", zlotyPl, "pięć złotych" },
+        SpellerCountTC{ "pl", "12", zlotyPl, "dwanaście złotych" },
+        SpellerCountTC{ "pl", "22", zlotyPl, "dwadzieścia dwa złote" },
+        SpellerCountTC{ "pl", "101", zlotyPl, "sto jeden złotych" },
+        SpellerCountTC{ "pl", "1", koronaPl, "jedna korona" },
+        SpellerCountTC{ "pl", "2", koronaPl, "dwie korony" },
+        SpellerCountTC{ "pl", "1000", koronaPl, "tysiąc koron" },
+        SpellerCountTC{ "en", "1", UnitName{ One: "pound", Other: "pounds" }, "one pound" },
+        SpellerCountTC{ "en", "1000001", UnitName{ One: "pound", Other: "pounds" },
+            "one million one pounds" },
+    }
+    for i, tc := range testCases {
+        sp, err := LookupSpeller(tc.lang)
+        if err!=nil {
+            t.Errorf("Unexpected error: %d: %v", i, err)
+            continue
+        }
+        result := string(sp.AppendCount(nil, []byte(tc.digits), &tc.unit,
+                                        CaseNominative))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: count(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.digits, tc.expected, result)
+        }
+    }
+}
+
+func TestSpellerGenitive(t *testing.T) {
+    zlotyPl := UnitName{ One: "złoty", Few: "złote", Many: "złotych",
+                OneGenitive: "złotego" }
+    koronaPl := UnitName{ One: "korona", Few: "korony", Many: "koron",
+                OneGenitive: "korony", Gender: GenderFeminine }
+    testCases := []SpellerCountTC {
+        SpellerCountTC{ "pl", "0", zlotyPl, "zera złotych" },
+        SpellerCountTC{ "pl", "1", zlotyPl, "jednego złotego" },
+        SpellerCountTC{ "pl", "1", koronaPl, "jednej korony" },
+        SpellerCountTC{ "pl", "2", zlotyPl, "dwóch złotych" },
+        SpellerCountTC{ "pl", "2", koronaPl, "dwóch koron" },
+        SpellerCountTC{ "pl", "5", zlotyPl, "pięciu złotych" },
+        SpellerCountTC{ "pl", "22", zlotyPl, "dwudziestu dwóch złotych" },
+        SpellerCountTC{ "pl", "101", zlotyPl, "stu jeden złotych" },
+        SpellerCountTC{ "pl", "245", zlotyPl, "dwustu czterdziestu pięciu złotych" },
+        SpellerCountTC{ "pl", "1000", zlotyPl, "tysiąca złotych" },
+        SpellerCountTC{ "pl", "3000", zlotyPl, "trzech tysięcy złotych" },
+        SpellerCountTC{ "pl", "2000000", zlotyPl, "dwóch milionów złotych" },
+        SpellerCountTC{ "pl", "1000001", zlotyPl, "miliona jeden złotych" },
+        // languages without declension of numerals ignore case
+        SpellerCountTC{ "en", "2", UnitName{ One: "pound", Other: "pounds" },
+            "two pounds" },
+    }
+    for i, tc := range testCases {
+        sp, err := LookupSpeller(tc.lang)
+        if err!=nil {
+            t.Errorf("Unexpected error: %d: %v", i, err)
+            continue
+        }
+        result := string(sp.AppendCount(nil, []byte(tc.digits), &tc.unit,
+                                        CaseGenitive))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: count(%v,%v)->%q!=%q",
+                     i, tc.lang, tc.digits, tc.expected, result)
+        }
+    }
+}
@@ -0,0 +1,185 @@
+/*
+ * stream.go - streaming scanning of decimals from reader
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bufio"
+    "io"
+    "strconv"
+)
+
+// error of parsing field of stream. It wraps error of parsing
+// (strconv.ErrSyntax or strconv.ErrRange)
+type ScanError struct {
+    // line and column of field (from one, column in bytes)
+    Line, Col int
+    // field as found in input
+    Field string
+    Err error
+}
+
+func (e *ScanError) Error() string {
+    return "godec128: "+e.Err.Error()+" at line "+strconv.Itoa(e.Line)+
+            ", column "+strconv.Itoa(e.Col)
+}
+
+func (e *ScanError) Unwrap() error {
+    return e.Err
+}
+
+// return true if byte is space around field
+func isFieldSpace(c byte) bool {
+    return c==' ' || c=='\t' || c=='\r'
+}
+
+// split next field. Return number of bytes to advance, start of field and
+// field (nil if no field). Fields are separated by delimiter or newline,
+// spaces around fields and empty lines are skipped. If delimiter is space
+// then repeated delimiters are treated as one.
+func splitDecimal(delim byte, data []byte, atEOF bool) (int, int, []byte) {
+    start := 0
+    for start < len(data) && (isFieldSpace(data[start]) || data[start]=='\n' ||
+                (data[start]==delim && isFieldSpace(delim))) {
+        start++
+    }
+    if start==len(data) { return start, start, nil }
+    end := start
+    for end < len(data) && data[end]!=delim && data[end]!='\n' { end++ }
+    if end==len(data) && !atEOF {
+        // request more data
+        return start, start, nil
+    }
+    advance := end
+    if end<len(data) && data[end]==delim { advance++ }
+    for end>start && isFieldSpace(data[end-1]) { end-- }
+    return advance, start, data[start:end]
+}
+
+// return split function for bufio.Scanner that splits input to fields
+// separated by delimiter or newline (like '1.5,2.25\n3').
+func SplitDecimals(delim byte) bufio.SplitFunc {
+    return func(data []byte, atEOF bool) (int, []byte, error) {
+        advance, _, token := splitDecimal(delim, data, atEOF)
+        return advance, token, nil
+    }
+}
+
+// scanner that reads successive decimals from reader
+type DecimalScanner struct {
+    sc *bufio.Scanner
+    precision uint
+    rounding bool
+    // position of next byte of input
+    line, col int
+    // position of current field
+    tokLine, tokCol int
+    value UDec128
+    err error
+}
+
+// create new scanner of decimals separated by delimiter or newline
+func NewDecimalScanner(r io.Reader, delim byte, precision uint,
+                    rounding bool) *DecimalScanner {
+    s := &DecimalScanner{ sc: bufio.NewScanner(r), precision: precision,
+                rounding: rounding, line: 1, col: 1 }
+    s.sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+        advance, start, token := splitDecimal(delim, data, atEOF)
+        if token!=nil {
+            s.advancePos(data[:start])
+            s.tokLine, s.tokCol = s.line, s.col
+            s.advancePos(data[start:advance])
+        } else {
+            s.advancePos(data[:advance])
+        }
+        return advance, token, nil
+    })
+    return s
+}
+
+// update position after consumed bytes
+func (s *DecimalScanner) advancePos(data []byte) {
+    for _, c := range data {
+        if c=='\n' {
+            s.line++
+            s.col = 1
+        } else {
+            s.col++
+        }
+    }
+}
+
+// set buffer of scanner (see bufio.Scanner.Buffer). Must be called
+// before first scanning.
+func (s *DecimalScanner) Buffer(buf []byte, max int) {
+    s.sc.Buffer(buf, max)
+}
+
+// parse next decimal. Return false if end of input or error.
+func (s *DecimalScanner) Scan() bool {
+    if s.err!=nil { return false }
+    if !s.sc.Scan() {
+        s.err = s.sc.Err()
+        return false
+    }
+    field := s.sc.Bytes()
+    v, err := ParseUDec128Bytes(field, s.precision, s.rounding)
+    if err!=nil {
+        // error of parsing exponent is reported as bare error
+        if nerr, ok := err.(*strconv.NumError); ok { err = nerr.Err }
+        s.err = &ScanError{ s.tokLine, s.tokCol, string(field), err }
+        return false
+    }
+    s.value = v
+    return true
+}
+
+// return last parsed decimal
+func (s *DecimalScanner) Value() UDec128 {
+    return s.value
+}
+
+// return line and column (from one, column in bytes) of last field
+func (s *DecimalScanner) Pos() (int, int) {
+    return s.tokLine, s.tokCol
+}
+
+// return first error (nil if end of input)
+func (s *DecimalScanner) Err() error {
+    return s.err
+}
+
+// read successive decimals into dst. Fields are parsed directly from
+// buffer of scanner. Return number of read decimals and error. If end of
+// input is reached and no decimal is read then io.EOF is returned.
+func (s *DecimalScanner) Read(dst []UDec128) (int, error) {
+    n := 0
+    for n < len(dst) && s.Scan() {
+        dst[n] = s.value
+        n++
+    }
+    if n<len(dst) {
+        if s.err!=nil { return n, s.err }
+        if n==0 { return 0, io.EOF }
+    }
+    return n, nil
+}
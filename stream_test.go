@@ -0,0 +1,150 @@
+/*
+ * stream_test.go - tests for streaming scanning of decimals
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+
+package godec128
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "testing/iotest"
+)
+
+type SplitDecimalsTC struct {
+    delim byte
+    str string
+    expected []string
+}
+
+func TestSplitDecimals(t *testing.T) {
+    testCases := []SplitDecimalsTC {
+        SplitDecimalsTC{ ',', "1.5,2.25\n3", []string{ "1.5", "2.25", "3" } },
+        SplitDecimalsTC{ ',', " 1.5 , 2 \r\n\n 3,\n", []string{ "1.5", "2", "3" } },
+        SplitDecimalsTC{ ',', "1,,2", []string{ "1", "", "2" } },
+        SplitDecimalsTC{ ' ', "1   2\t3\n\n4 ", []string{ "1", "2\t3", "4" } },
+        SplitDecimalsTC{ '\t', "1\t\t2\n", []string{ "1", "2" } },
+        SplitDecimalsTC{ ';', "", nil },
+        SplitDecimalsTC{ ';', " \n \n", nil },
+    }
+    for i, tc := range testCases {
+        sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tc.str)))
+        sc.Split(SplitDecimals(tc.delim))
+        var result []string
+        for sc.Scan() {
+            result = append(result, sc.Text())
+        }
+        if sc.Err()!=nil || !reflect.DeepEqual(tc.expected, result) {
+            t.Errorf("Result mismatch: %d: %v: %v->%v,%v", i, tc, tc.expected,
+                     result, sc.Err())
+        }
+    }
+}
+
+type DecimalScannerTC struct {
+    str string
+    expected []UDec128
+    expectedLine, expectedCol int
+    expectedError error
+}
+
+func TestDecimalScanner(t *testing.T) {
+    testCases := []DecimalScannerTC {
+        DecimalScannerTC{ "1.5,2.25\n3", []UDec128{ UDec128{ 150, 0 },
+                    UDec128{ 225, 0 }, UDec128{ 300, 0 } }, 2, 1, nil },
+        DecimalScannerTC{ "1.555, 0.001\n", []UDec128{ UDec128{ 156, 0 },
+                    UDec128{ 0, 0 } }, 1, 8, nil },
+        DecimalScannerTC{ "1,2\n\n 3,x4,5", []UDec128{ UDec128{ 100, 0 },
+                    UDec128{ 200, 0 }, UDec128{ 300, 0 } }, 3, 4, strconv.ErrSyntax },
+        DecimalScannerTC{ "1,,2", []UDec128{ UDec128{ 100, 0 } }, 1, 3,
+                    strconv.ErrSyntax },
+        DecimalScannerTC{ "1e40", nil, 1, 1, strconv.ErrRange },
+        DecimalScannerTC{ "", nil, 0, 0, nil },
+    }
+    for i, tc := range testCases {
+        s := NewDecimalScanner(iotest.OneByteReader(strings.NewReader(tc.str)), ',',
+                               2, true)
+        var result []UDec128
+        for s.Scan() {
+            result = append(result, s.Value())
+        }
+        line, col := s.Pos()
+        err := s.Err()
+        if !reflect.DeepEqual(tc.expected, result) || tc.expectedLine!=line ||
+            tc.expectedCol!=col || !errors.Is(err, tc.expectedError) ||
+            (tc.expectedError==nil && err!=nil) {
+            t.Errorf("Result mismatch: %d: %v: %v,%d,%d,%v->%v,%d,%d,%v", i, tc,
+                     tc.expected, tc.expectedLine, tc.expectedCol, tc.expectedError,
+                     result, line, col, err)
+        }
+    }
+}
+
+func TestDecimalScannerError(t *testing.T) {
+    s := NewDecimalScanner(strings.NewReader("1\n2;3x"), ';', 2, false)
+    for s.Scan() {}
+    var serr *ScanError
+    if !errors.As(s.Err(), &serr) || serr.Line!=2 || serr.Col!=3 || serr.Field!="3x" {
+        t.Errorf("Result mismatch: %v", s.Err())
+    }
+    if s.Err().Error()!="godec128: invalid syntax at line 2, column 3" {
+        t.Errorf("Result mismatch: %v", s.Err().Error())
+    }
+    // malformed exponents
+    for str, expected := range map[string]error{ "1e+x": strconv.ErrSyntax,
+                "1e1000": strconv.ErrRange } {
+        s = NewDecimalScanner(strings.NewReader(str), ';', 2, false)
+        for s.Scan() {}
+        if !errors.As(s.Err(), &serr) || serr.Err!=expected {
+            t.Errorf("Result mismatch: %v: %v", str, s.Err())
+        }
+    }
+}
+
+func TestDecimalScannerRead(t *testing.T) {
+    s := NewDecimalScanner(strings.NewReader("1 2 3\n4 5"), ' ', 1, false)
+    dst := make([]UDec128, 2)
+    var result []UDec128
+    var err error
+    for {
+        var n int
+        n, err = s.Read(dst)
+        result = append(result, dst[:n]...)
+        if err!=nil { break }
+    }
+    expected := []UDec128{ UDec128{ 10, 0 }, UDec128{ 20, 0 }, UDec128{ 30, 0 },
+                UDec128{ 40, 0 }, UDec128{ 50, 0 } }
+    if err!=io.EOF || !reflect.DeepEqual(expected, result) {
+        t.Errorf("Result mismatch: %v,%v", result, err)
+    }
+    s = NewDecimalScanner(strings.NewReader("1 2 a 4"), ' ', 1, false)
+    dst = make([]UDec128, 4)
+    if n, err := s.Read(dst); n!=2 || !errors.Is(err, strconv.ErrSyntax) {
+        t.Errorf("Result mismatch: %v,%v", n, err)
+    }
+    if n, err := s.Read(dst); n!=0 || !errors.Is(err, strconv.ErrSyntax) {
+        t.Errorf("Result mismatch: %v,%v", n, err)
+    }
+}
@@ -0,0 +1,112 @@
+/*
+ * strict.go - strict parsing with locale
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "strconv"
+)
+
+// error of strict parsing. It wraps strconv.ErrSyntax
+type ParseError struct {
+    // position of offending character (in runes, from zero)
+    Pos int
+    // description of error
+    Msg string
+}
+
+func (e *ParseError) Error() string {
+    return "godec128: "+e.Msg+" at position "+strconv.Itoa(e.Pos)
+}
+
+func (e *ParseError) Unwrap() error {
+    return strconv.ErrSyntax
+}
+
+// separator of groups in integer part
+type groupSep struct {
+    // number of digits before separator
+    digits int
+    // position of separator (in runes)
+    pos int
+}
+
+// return true if rune is space that can be used as separator
+func isSpaceRune(r rune) bool {
+    return r==' ' || r==0xa0 || r==0x2009 || r==0x202f
+}
+
+// check positions of separators in integer part. Number without any
+// separators is always accepted. Separators are not accepted in number
+// shorter than required by minimal grouping digits.
+func (st *localeParseState) checkGrouping(g Grouping) error {
+    if len(st.seps)==0 { return nil }
+    n := len(st.intPos)
+    if st.seps[0].digits==0 {
+        return st.failAt(st.seps[0].pos, "separator before digits")
+    }
+    if !g.applies(n) {
+        return st.failAt(st.seps[0].pos, "separator in too short number")
+    }
+    j := 0
+    for k := 1; k < n; k++ {
+        boundary := g.isBoundary(n-k)
+        if j<len(st.seps) && st.seps[j].digits==k {
+            if !boundary { return st.failAt(st.seps[j].pos, "misplaced separator") }
+            j++
+            if j<len(st.seps) && st.seps[j].digits==k {
+                return st.failAt(st.seps[j].pos, "repeated separator")
+            }
+        } else if boundary {
+            return st.failAt(st.intPos[k], "missing separator")
+        }
+    }
+    if j<len(st.seps) {
+        return st.failAt(st.seps[j].pos, "separator at end of number")
+    }
+    return nil
+}
+
+// parse decimal fixed point from string with checking positions of separators
+// against grouping of locale. Separators after comma, repeated comma, comma
+// at end of number and trailing spaces are not allowed. Return value and
+// error (nil if no error). If string is malformed then ParseError with
+// position of offending character is returned.
+func LocaleParseUDec128Strict(lang, str string, precision uint,
+                    rounding bool) (UDec128, error) {
+    l := getLocFmt(lang)
+    v, negative, err := localeParseUDec128Style(l, l.Grouping, l.FracGrouping, str,
+                                precision, rounding, StyleDecimal, true)
+    if err==nil && negative { return UDec128{}, &ParseError{ 0, "negative number" } }
+    return v, err
+}
+
+// parse decimal fixed point from bytes with checking positions of separators
+// against grouping of locale.
+func LocaleParseUDec128StrictBytes(lang string, str []byte, precision uint,
+                    rounding bool) (UDec128, error) {
+    l := getLocFmt(lang)
+    v, negative, err := localeParseUDec128StyleBytes(l, l.Grouping, l.FracGrouping,
+                                str, precision, rounding, StyleDecimal, true)
+    if err==nil && negative { return UDec128{}, &ParseError{ 0, "negative number" } }
+    return v, err
+}
@@ -0,0 +1,130 @@
+/*
+ * strict_test.go - tests for strict parsing with locale
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "errors"
+    "strconv"
+    "testing"
+)
+
+type UDec128StrictTC struct {
+    lang string
+    str string
+    precision uint
+    expected UDec128
+    // position of error, -1 if no error
+    errPos int
+}
+
+func TestLocaleParseUDec128Strict(t *testing.T) {
+    testCases := []UDec128StrictTC {
+        UDec128StrictTC{ "en", "1,234,567.5", 1, UDec128{ 12345675, 0 }, -1 },
+        UDec128StrictTC{ "en", "1234567.5", 1, UDec128{ 12345675, 0 }, -1 },
+        UDec128StrictTC{ "en", "123.5", 1, UDec128{ 1235, 0 }, -1 },
+        UDec128StrictTC{ "en", "1,2,3,4.5", 1, UDec128{}, 3 },
+        UDec128StrictTC{ "en", "12,34,5", 1, UDec128{}, 5 },
+        UDec128StrictTC{ "en", "1234,567", 1, UDec128{}, 1 },
+        UDec128StrictTC{ "en", "1,,234", 1, UDec128{}, 2 },
+        UDec128StrictTC{ "en", ",123", 1, UDec128{}, 0 },
+        UDec128StrictTC{ "en", "123,", 1, UDec128{}, 3 },
+        UDec128StrictTC{ "en", "1,234,.5", 1, UDec128{}, 5 },
+        UDec128StrictTC{ "en", "1.2.3", 1, UDec128{}, 3 },
+        UDec128StrictTC{ "en", "1.234,5", 4, UDec128{}, 5 },
+        UDec128StrictTC{ "en", "1x2", 1, UDec128{}, 1 },
+        UDec128StrictTC{ "en", "", 1, UDec128{}, 0 },
+        UDec128StrictTC{ "en", ",", 1, UDec128{}, 0 },
+        UDec128StrictTC{ "en", ".", 1, UDec128{}, 0 },
+        UDec128StrictTC{ "en", "1,234.", 1, UDec128{}, 5 },
+        UDec128StrictTC{ "en", "1,234 ", 1, UDec128{}, 5 },
+        UDec128StrictTC{ "en", "1.5  ", 1, UDec128{}, 3 },
+        UDec128StrictTC{ "en-IN", "12,34,567.5", 1, UDec128{ 12345675, 0 }, -1 },
+        UDec128StrictTC{ "en-IN", "1,234,567.5", 1, UDec128{}, 1 },
+        UDec128StrictTC{ "en-IN", "12,34,5", 1, UDec128{}, 5 },
+        UDec128StrictTC{ "de", "1.234.567,5", 1, UDec128{ 12345675, 0 }, -1 },
+        UDec128StrictTC{ "de", "1.234,5,6", 2, UDec128{}, 7 },
+        UDec128StrictTC{ "pl", "12 345", 0, UDec128{ 12345, 0 }, -1 },
+        UDec128StrictTC{ "pl", "1234", 0, UDec128{ 1234, 0 }, -1 },
+        UDec128StrictTC{ "pl", "1 234", 0, UDec128{}, 1 },
+        UDec128StrictTC{ "pl", "12 34", 0, UDec128{}, 2 },
+        UDec128StrictTC{ "ar", "١٬٢٣٤٫٥", 1, UDec128{ 12345, 0 }, -1 },
+        UDec128StrictTC{ "ar", "١٢٬٣٤٫٥", 1, UDec128{}, 1 },
+    }
+    for i, tc := range testCases {
+        result, err := LocaleParseUDec128Strict(tc.lang, tc.str, tc.precision, false)
+        checkStrictResult(t, i, tc, result, err)
+        result, err = LocaleParseUDec128StrictBytes(tc.lang, []byte(tc.str),
+                                                    tc.precision, false)
+        checkStrictResult(t, i, tc, result, err)
+    }
+}
+
+func checkStrictResult(t *testing.T, i int, tc UDec128StrictTC,
+                       result UDec128, err error) {
+    if tc.errPos<0 {
+        if tc.expected!=result || err!=nil {
+            t.Errorf("Result mismatch: %d: parse(%v)->%v!=%v,%v",
+                     i, tc.str, tc.expected, result, err)
+        }
+        return
+    }
+    var perr *ParseError
+    if !errors.As(err, &perr) || perr.Pos!=tc.errPos ||
+            !errors.Is(err, strconv.ErrSyntax) {
+        t.Errorf("Error mismatch: %d: parse(%v)->%v!=%v", i, tc.str, tc.errPos, err)
+    }
+}
+
+func TestLocaleParseUDec128StrictFrac(t *testing.T) {
+    p := NewParser(ParserOptions{ Lang: "en", Precision: 8, Strict: true,
+                FracGrouping: &FracGrouping{ 3, 0 } })
+    if v, err := p.Parse("0.123 456 78"); v!=(UDec128{ 12345678, 0 }) ||
+            err!=nil {
+        t.Errorf("Result mismatch: frac: %v,%v", v, err)
+    }
+    var perr *ParseError
+    if _, err := p.Parse("0.12 3456"); !errors.As(err, &perr) || perr.Pos!=4 {
+        t.Errorf("Error mismatch: frac: %v", err)
+    }
+    if _, err := p.Parse("-1.5"); !errors.As(err, &perr) {
+        t.Errorf("Error mismatch: negative: %v", err)
+    }
+    // no fractional grouping
+    if _, err := LocaleParseUDec128Strict("en", "0.123 456", 6, false);
+            !errors.As(err, &perr) || perr.Pos!=6 {
+        t.Errorf("Error mismatch: no frac grouping: %v", err)
+    }
+    // space before percent sign
+    p = NewParser(ParserOptions{ Lang: "fr", Precision: 3, Strict: true,
+                Style: StylePercent })
+    if v, err := p.Parse("12,5 %"); v!=(UDec128{ 125, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: percent: %v,%v", v, err)
+    }
+    if v, err := p.Parse("12 %"); v!=(UDec128{ 120, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: percent: %v,%v", v, err)
+    }
+    // lenient parsing does not check separators
+    if v, err := LocaleParseUDec128("en", "1,2,3,4.5", 1, false);
+            v!=(UDec128{ 12345, 0 }) || err!=nil {
+        t.Errorf("Result mismatch: lenient: %v,%v", v, err)
+    }
+}
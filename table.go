@@ -0,0 +1,175 @@
+/*
+ * table.go - formatting columns aligned to decimal point
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "io"
+    "unicode"
+    "unicode/utf8"
+)
+
+// ranges of East Asian wide and fullwidth characters
+var wideRuneRanges [][2]rune = [][2]rune{
+    { 0x1100, 0x115f },
+    { 0x231a, 0x231b },
+    { 0x2e80, 0x303e },
+    { 0x3041, 0x33ff },
+    { 0x3400, 0x4dbf },
+    { 0x4e00, 0x9fff },
+    { 0xa000, 0xa4cf },
+    { 0xac00, 0xd7a3 },
+    { 0xf900, 0xfaff },
+    { 0xfe30, 0xfe4f },
+    { 0xff00, 0xff60 },
+    { 0xffe0, 0xffe6 },
+    { 0x1f300, 0x1f64f },
+    { 0x20000, 0x3fffd },
+}
+
+// return display width of rune in terminal: 0 for combining and format
+// characters, 2 for East Asian wide characters, otherwise 1
+func runeDisplayWidth(r rune) int {
+    if r < 0x300 { return 1 }
+    if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) { return 0 }
+    for _, rr := range wideRuneRanges {
+        if r < rr[0] { break }
+        if r <= rr[1] { return 2 }
+    }
+    return 1
+}
+
+// return display width of UTF-8 string
+func displayWidth(s []byte) int {
+    w := 0
+    for len(s)>0 {
+        r, size := utf8.DecodeRune(s)
+        w += runeDisplayWidth(r)
+        s = s[size:]
+    }
+    return w
+}
+
+// column of table
+type TableColumn struct {
+    // header of column (can be empty)
+    Header string
+    // formatter of values. Width and padding of formatter should be not set.
+    // If nil then values are formatted without locale and precision.
+    Formatter *Formatter
+}
+
+// table formatter that writes columns of values aligned to decimal point
+type Table struct {
+    Columns []TableColumn
+    // string put between columns
+    ColumnSep string
+    // fill character, if zero then space is used. Wide fill character
+    // takes two columns.
+    Fill rune
+}
+
+// formatter used for columns without formatter
+var defaultTableFormatter = NewFormatter(FormatterOptions{})
+
+// append fill characters that take width columns. If fill character is
+// wide then remaining column is filled by space.
+func appendFill(dst []byte, fill rune, width int) []byte {
+    fw := runeDisplayWidth(fill)
+    if fw==0 { fw = 1 }
+    dst = appendPadding(dst, fill, width/fw)
+    return appendPadding(dst, ' ', width%fw)
+}
+
+// formatted cell split at comma
+type tableCell struct {
+    s []byte
+    commaPos int
+    leftWidth, rightWidth int
+}
+
+// write rows of values to writer. Values of every column are aligned to comma
+// by display width (East Asian wide characters are counted as two columns).
+// If row has fewer values than columns, then remaining cells are filled.
+func (t *Table) Write(w io.Writer, rows [][]UDec128) error {
+    fill := t.Fill
+    if fill==0 { fill = ' ' }
+    ncols := len(t.Columns)
+    cells := make([]tableCell, len(rows)*ncols)
+    leftWidths := make([]int, ncols)
+    rightWidths := make([]int, ncols)
+    for c, col := range t.Columns {
+        f := col.Formatter
+        if f==nil { f = defaultTableFormatter }
+        var commaBuf [utf8.UTFMax]byte
+        comma := commaBuf[:utf8.EncodeRune(commaBuf[:], f.commaRune())]
+        for r, row := range rows {
+            if c>=len(row) { continue }
+            cell := &cells[r*ncols+c]
+            cell.s = f.AppendFormat(nil, row[c])
+            cell.commaPos = bytes.LastIndex(cell.s, comma)
+            if cell.commaPos==-1 { cell.commaPos = len(cell.s) }
+            cell.leftWidth = displayWidth(cell.s[:cell.commaPos])
+            cell.rightWidth = displayWidth(cell.s[cell.commaPos:])
+            if cell.leftWidth>leftWidths[c] { leftWidths[c] = cell.leftWidth }
+            if cell.rightWidth>rightWidths[c] { rightWidths[c] = cell.rightWidth }
+        }
+        // header is aligned to right side of column
+        hw := displayWidth([]byte(col.Header))
+        if hw>leftWidths[c]+rightWidths[c] {
+            leftWidths[c] = hw-rightWidths[c]
+        }
+    }
+    var line []byte
+    hasHeader := false
+    for _, col := range t.Columns {
+        if col.Header!="" { hasHeader = true }
+    }
+    if hasHeader {
+        for c, col := range t.Columns {
+            if c!=0 { line = append(line, t.ColumnSep...) }
+            hw := displayWidth([]byte(col.Header))
+            line = appendFill(line, fill, leftWidths[c]+rightWidths[c]-hw)
+            line = append(line, col.Header...)
+        }
+        line = append(line, '\n')
+        if _, err := w.Write(line); err!=nil { return err }
+    }
+    for r := range rows {
+        line = line[:0]
+        for c := 0; c < ncols; c++ {
+            if c!=0 { line = append(line, t.ColumnSep...) }
+            cell := &cells[r*ncols+c]
+            if cell.s==nil {
+                line = appendFill(line, fill, leftWidths[c]+rightWidths[c])
+                continue
+            }
+            line = appendFill(line, fill, leftWidths[c]-cell.leftWidth)
+            line = append(line, cell.s...)
+            line = appendFill(line, fill, rightWidths[c]-cell.rightWidth)
+        }
+        line = append(line, '\n')
+        if _, err := w.Write(line); err!=nil { return err }
+    }
+    return nil
+}
@@ -0,0 +1,110 @@
+/*
+ * table_test.go - tests for formatting columns aligned to decimal point
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "bytes"
+    "testing"
+)
+
+type DisplayWidthTC struct {
+    s string
+    expected int
+}
+
+func TestDisplayWidth(t *testing.T) {
+    testCases := []DisplayWidthTC {
+        DisplayWidthTC{ "1,234.5", 7 },
+        DisplayWidthTC{ "١٬٢٣٤٫٥", 7 },
+        DisplayWidthTC{ "１２３", 6 },
+        DisplayWidthTC{ "一万二千", 8 },
+        DisplayWidthTC{ "\u200e12", 2 },
+    }
+    for i, tc := range testCases {
+        result := displayWidth([]byte(tc.s))
+        if tc.expected!=result {
+            t.Errorf("Result mismatch: %d: width(%v)->%v!=%v", i, tc.s, tc.expected, result)
+        }
+    }
+}
+
+func TestTableWrite(t *testing.T) {
+    table := Table{
+        Columns: []TableColumn{
+            TableColumn{ "Amount", NewFormatter(FormatterOptions{ Lang: "en",
+                        Precision: 3, DisplayPrecision: 3, TrimZeroes: true }) },
+            TableColumn{ "Kwota", NewFormatter(FormatterOptions{ Lang: "ar",
+                        Precision: 2, DisplayPrecision: 2 }) },
+        },
+        ColumnSep: " | ",
+    }
+    rows := [][]UDec128{
+        { UDec128{ 1234500, 0 }, UDec128{ 12, 0 } },
+        { UDec128{ 5, 0 }, UDec128{ 123456, 0 } },
+        { UDec128{ 100000000, 0 } },
+    }
+    var out bytes.Buffer
+    if err := table.Write(&out, rows); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    expected := "     Amount |    Kwota\n" +
+                "  1,234.5   |     ٠٫١٢\n" +
+                "      0.005 | ١٬٢٣٤٫٥٦\n" +
+                "100,000.0   |         \n"
+    if out.String()!=expected {
+        t.Errorf("Result mismatch:\n%v!=\n%v", expected, out.String())
+    }
+
+    table.Fill = '.'
+    table.Columns[0].Header = ""
+    table.Columns[1].Header = ""
+    out.Reset()
+    if err := table.Write(&out, rows[:2]); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    expected = "1,234.5.. | ....٠٫١٢\n" +
+               "....0.005 | ١٬٢٣٤٫٥٦\n"
+    if out.String()!=expected {
+        t.Errorf("Result mismatch:\n%v!=\n%v", expected, out.String())
+    }
+
+    table = Table{
+        Columns: []TableColumn{
+            TableColumn{ "A", nil },
+            TableColumn{ "B", NewFormatter(FormatterOptions{ Precision: 1,
+                        DisplayPrecision: 1 }) },
+        },
+        ColumnSep: "|",
+        Fill: '\u30fb',
+    }
+    out.Reset()
+    if err := table.Write(&out, [][]UDec128{ { UDec128{ 12345, 0 }, UDec128{ 5, 0 } },
+                { UDec128{ 7, 0 }, UDec128{ 123, 0 } } }); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    expected = "\u30fb\u30fbA|\u30fb B\n" +
+               "12345| 0.5\n" +
+               "\u30fb\u30fb7|12.3\n"
+    if out.String()!=expected {
+        t.Errorf("Result mismatch:\n%v!=\n%v", expected, out.String())
+    }
+}
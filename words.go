@@ -0,0 +1,265 @@
+/*
+ * words.go - spelling amounts in words
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+// Package to operate on 128-bit decimal fixed point
+package godec128
+
+import (
+    "bytes"
+    "sync"
+)
+
+// grammatical gender of counted noun
+type Gender uint8
+
+const (
+    GenderMasculine Gender = iota
+    GenderFeminine
+    GenderNeuter
+    // no counted noun: standalone (counting) form of number
+    // (like German 'eins' or Spanish 'uno')
+    GenderNone
+)
+
+// grammatical case of spelled number (for languages with declension
+// of numerals)
+type GrammaticalCase uint8
+
+const (
+    // nominative case (like Polish 'dwa złote')
+    CaseNominative GrammaticalCase = iota
+    // genitive case (like Polish 'dwóch złotych')
+    CaseGenitive
+)
+
+// plural category of number (like in CLDR plural rules)
+type PluralCategory uint8
+
+const (
+    PluralOther PluralCategory = iota
+    PluralOne
+    PluralFew
+    PluralMany
+)
+
+// forms of name of unit (for example currency unit) for plural categories
+type UnitName struct {
+    One, Few, Many, Other string
+    // form of one in genitive case (like Polish 'złotego'). If empty then
+    // One is used. Other forms in genitive case are taken from Many
+    OneGenitive string
+    // grammatical gender of name
+    Gender Gender
+}
+
+// return form of name for plural category. If form is empty then Other is used
+func (u *UnitName) Form(c PluralCategory) string {
+    var s string
+    switch c {
+    case PluralOne:
+        s = u.One
+    case PluralFew:
+        s = u.Few
+    case PluralMany:
+        s = u.Many
+    }
+    if s=="" { return u.Other }
+    return s
+}
+
+func (u *UnitName) isEmpty() bool {
+    return u.One=="" && u.Few=="" && u.Many=="" && u.Other==""
+}
+
+// names of currency unit and subunit
+type CurrencyNames struct {
+    Unit UnitName
+    // name of subunit. If empty then subunits are put as fraction (like '56/100')
+    Subunit UnitName
+}
+
+// speller of numbers in words for language. Integers are given as ASCII
+// digits without leading zeroes (zero is given as '0').
+type Speller interface {
+    // append integer spelled in words, gender is gender of counted noun
+    // or GenderNone if no noun follows. Spellers of languages without
+    // declension of numerals ignore grammatical case
+    AppendWords(dst []byte, digits []byte, gender Gender,
+                gcase GrammaticalCase) []byte
+    // append integer spelled in words followed by name of unit in proper form
+    AppendCount(dst []byte, digits []byte, unit *UnitName,
+                gcase GrammaticalCase) []byte
+    // word put between units and subunits (like 'and')
+    Conjunction() string
+}
+
+type spellerRegistry struct {
+    sync.RWMutex
+    custom map[string]Speller
+}
+
+var spellers = spellerRegistry{ custom: make(map[string]Speller) }
+
+// register speller for language. Registered speller overrides built-in
+// speller for same language.
+func RegisterSpeller(lang string, s Speller) error {
+    t, ok := parseLangTag(lang)
+    if !ok || s==nil { return ErrInvalidLocale }
+    spellers.Lock()
+    spellers.custom[t.String()] = s
+    spellers.Unlock()
+    return nil
+}
+
+// unregister speller registered by RegisterSpeller
+func UnregisterSpeller(lang string) {
+    t, ok := parseLangTag(lang)
+    if !ok { return }
+    spellers.Lock()
+    delete(spellers.custom, t.String())
+    spellers.Unlock()
+}
+
+// return speller for language tag. Spellers are tried from most specific
+// language tag (like 'en-IN') to most general ('en'). If no speller
+// is available then ErrUnknownLocale is returned.
+func LookupSpeller(lang string) (Speller, error) {
+    t, ok := parseLangTag(lang)
+    if !ok { return nil, ErrUnknownLocale }
+    spellers.RLock()
+    defer spellers.RUnlock()
+    for _, name := range t.fallbacks() {
+        if s, ok := spellers.custom[name]; ok { return s, nil }
+        if s, ok := builtinSpellers[name]; ok { return s, nil }
+    }
+    return nil, ErrUnknownLocale
+}
+
+// options of spelling amount in words
+type WordsOptions struct {
+    // language of speller
+    Lang string
+    // number of digits in fraction of value
+    Precision uint
+    // number of digits of subunits (or fraction). Amount is rounded
+    // to this number of digits
+    FracDigits uint
+    // rounding mode used if FracDigits is lesser than Precision
+    Rounding RoundingMode
+    // grammatical case of amount (like genitive in 'kwota dwóch złotych')
+    Case GrammaticalCase
+    // names of currency unit and subunit. If nil then amount is spelled
+    // without units and fraction is put as number (like 'and 56/100')
+    Currency *CurrencyNames
+}
+
+// append amount spelled in words to dst and return extended buffer,
+// like 'one thousand two hundred thirty-four dollars and fifty-six cents'
+// or 'one thousand two hundred thirty-four and 56/100'. Zero subunits are
+// not spelled. If no speller for language then ErrUnknownLocale is returned.
+func (a UDec128) AppendWords(dst []byte, opts WordsOptions) ([]byte, error) {
+    sp, err := LookupSpeller(opts.Lang)
+    if err!=nil { return dst, err }
+    var buf [64]byte
+    s := a.AppendFormatRound(buf[:0], opts.Precision, opts.FracDigits, false,
+                             opts.Rounding)
+    commaIdx := bytes.IndexByte(s, '.')
+    if commaIdx==-1 { commaIdx = len(s) }
+    intPart := s[:commaIdx]
+    // fraction with FracDigits digits (zero is formatted as '0.0')
+    frac := make([]byte, opts.FracDigits)
+    for i := range frac {
+        frac[i] = '0'
+        if commaIdx+1+i<len(s) { frac[i] = s[commaIdx+1+i] }
+    }
+    cur := opts.Currency
+    if cur!=nil {
+        dst = sp.AppendCount(dst, intPart, &cur.Unit, opts.Case)
+    } else {
+        dst = sp.AppendWords(dst, intPart, GenderNone, opts.Case)
+    }
+    if len(frac)==0 { return dst, nil }
+    if cur!=nil && !cur.Subunit.isEmpty() {
+        if isZeroDigits(frac) { return dst, nil }
+        // skip leading zeroes
+        i := 0
+        for ; frac[i]=='0'; i++ { }
+        dst = append(dst, ' ')
+        dst = append(dst, sp.Conjunction()...)
+        dst = append(dst, ' ')
+        return sp.AppendCount(dst, frac[i:], &cur.Subunit, opts.Case), nil
+    }
+    dst = append(dst, ' ')
+    dst = append(dst, sp.Conjunction()...)
+    dst = append(dst, ' ')
+    dst = append(dst, frac...)
+    dst = append(dst, '/', '1')
+    for range frac { dst = append(dst, '0') }
+    return dst, nil
+}
+
+// spell amount in words
+func (a UDec128) Words(opts WordsOptions) (string, error) {
+    var buf [256]byte
+    s, err := a.AppendWords(buf[:0], opts)
+    return string(s), err
+}
+
+// split integer given as ASCII digits into groups of size digits.
+// First group is least significant group
+func digitGroups(digits []byte, size int) []int {
+    groups := make([]int, 0, (len(digits)+size-1)/size)
+    for end := len(digits); end>0; end -= size {
+        start := end-size
+        if start<0 { start = 0 }
+        v := 0
+        for _, c := range digits[start:end] { v = v*10 + int(c-'0') }
+        groups = append(groups, v)
+    }
+    return groups
+}
+
+// return true if integer given as ASCII digits is zero
+func isZeroDigits(digits []byte) bool {
+    for _, c := range digits {
+        if c!='0' { return false }
+    }
+    return true
+}
+
+// return value of last n digits and true if integer is one
+func lastDigits(digits []byte, n int) (int, bool) {
+    start := len(digits)-n
+    if start<0 { start = 0 }
+    v := 0
+    for _, c := range digits[start:] { v = v*10 + int(c-'0') }
+    one := v==1
+    for _, c := range digits[:start] {
+        if c!='0' { one = false }
+    }
+    return v, one
+}
+
+// append word to words started at start, words are separated by space
+func appendWord(dst []byte, start int, word string) []byte {
+    if len(dst)>start { dst = append(dst, ' ') }
+    return append(dst, word...)
+}
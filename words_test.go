@@ -0,0 +1,168 @@
+/*
+ * words_test.go - tests for spelling amounts in words
+ *
+ * godec128 - go dec128 (for 128-bit decimal fixed point) library
+ * Copyright (C) 2020  Mateusz Szpakowski
+ *
+ * This library is free software; you can redistribute it and/or
+ * modify it under the terms of the GNU Lesser General Public
+ * License as published by the Free Software Foundation; either
+ * version 2.1 of the License, or (at your option) any later version.
+ *
+ * This library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+ * Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public
+ * License along with this library; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301  USA
+ */
+
+package godec128
+
+import (
+    "testing"
+)
+
+var dollarNames = &CurrencyNames{ UnitName{ One: "dollar", Other: "dollars" },
+            UnitName{ One: "cent", Other: "cents" } }
+
+type UDec128WordsTC struct {
+    value UDec128
+    opts WordsOptions
+    expected string
+}
+
+func TestUDec128Words(t *testing.T) {
+    testCases := []UDec128WordsTC {
+        UDec128WordsTC{ UDec128{ 123456, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2 },
+            "one thousand two hundred thirty-four and 56/100" },
+        UDec128WordsTC{ UDec128{ 123456, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2, Currency: dollarNames },
+            "one thousand two hundred thirty-four dollars and fifty-six cents" },
+        UDec128WordsTC{ UDec128{ 100, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2, Currency: dollarNames },
+            "one dollar" },
+        UDec128WordsTC{ UDec128{ 101, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2, Currency: dollarNames },
+            "one dollar and one cent" },
+        UDec128WordsTC{ UDec128{ 0, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2 },
+            "zero and 00/100" },
+        UDec128WordsTC{ UDec128{ 0, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2, Currency: dollarNames },
+            "zero dollars" },
+        UDec128WordsTC{ UDec128{ 5, 0 },
+            WordsOptions{ Lang: "en", Precision: 0, FracDigits: 0 }, "five" },
+        UDec128WordsTC{ UDec128{ 5, 0 },
+            WordsOptions{ Lang: "en", Precision: 0, FracDigits: 2 }, "five and 00/100" },
+        UDec128WordsTC{ UDec128{ 12345, 0 },
+            WordsOptions{ Lang: "en", Precision: 3, FracDigits: 2 },
+            "twelve and 34/100" },
+        UDec128WordsTC{ UDec128{ 12345, 0 },
+            WordsOptions{ Lang: "en", Precision: 3, FracDigits: 2,
+                Rounding: RoundHalfUp }, "twelve and 35/100" },
+        UDec128WordsTC{ UDec128{ 99999, 0 },
+            WordsOptions{ Lang: "en", Precision: 3, FracDigits: 2,
+                Rounding: RoundHalfUp, Currency: dollarNames }, "one hundred dollars" },
+        UDec128WordsTC{ UDec128{ 1234, 0 },
+            WordsOptions{ Lang: "en", Precision: 0, FracDigits: 0, Currency: dollarNames },
+            "one thousand two hundred thirty-four dollars" },
+        // currency without subunit names
+        UDec128WordsTC{ UDec128{ 1234, 0 },
+            WordsOptions{ Lang: "en", Precision: 2, FracDigits: 2,
+                Currency: &CurrencyNames{ Unit: dollarNames.Unit } },
+            "twelve dollars and 34/100" },
+        UDec128WordsTC{ UDec128{ 10000000, 0 },
+            WordsOptions{ Lang: "en-IN", Precision: 0, FracDigits: 0 }, "one crore" },
+        // standalone forms without unit
+        UDec128WordsTC{ UDec128{ 10125, 0 },
+            WordsOptions{ Lang: "de", Precision: 2, FracDigits: 2 },
+            "einhunderteins und 25/100" },
+        UDec128WordsTC{ UDec128{ 21, 0 },
+            WordsOptions{ Lang: "es", Precision: 0, FracDigits: 0 }, "veintiuno" },
+        UDec128WordsTC{ UDec128{ 1001, 0 },
+            WordsOptions{ Lang: "es", Precision: 0, FracDigits: 0 }, "mil uno" },
+        // genitive case
+        UDec128WordsTC{ UDec128{ 20250, 0 },
+            WordsOptions{ Lang: "pl", Precision: 2, FracDigits: 2, Case: CaseGenitive,
+                Currency: &CurrencyNames{
+                    UnitName{ One: "złoty", Few: "złote", Many: "złotych",
+                        OneGenitive: "złotego" },
+                    UnitName{ One: "grosz", Few: "grosze", Many: "groszy",
+                        OneGenitive: "grosza" } } },
+            "dwustu dwóch złotych i pięćdziesięciu groszy" },
+        UDec128WordsTC{ UDec128{ 123456, 0 },
+            WordsOptions{ Lang: "en-GB", Precision: 0, FracDigits: 0 },
+            "one hundred twenty-three thousand four hundred fifty-six" },
+    }
+    for i, tc := range testCases {
+        result, err := tc.value.Words(tc.opts)
+        if tc.expected!=result || err!=nil {
+            t.Errorf("Result mismatch: %d: words(%v)->%q!=%q,%v",
+                     i, tc.value, tc.expected, result, err)
+        }
+    }
+    if _, err := (UDec128{ 1, 0 }).Words(WordsOptions{ Lang: "xx" });
+            err!=ErrUnknownLocale {
+        t.Errorf("Error mismatch: unknown: %v", err)
+    }
+    buf := []byte("Amount: ")
+    buf, err := UDec128{ 2, 0 }.AppendWords(buf, WordsOptions{ Lang: "en" })
+    if string(buf)!="Amount: two" || err!=nil {
+        t.Errorf("Result mismatch: append: %q,%v", buf, err)
+    }
+}
+
+// speller of digits (for testing)
+type digitSpeller struct{}
+
+func (s digitSpeller) AppendWords(dst []byte, digits []byte, gender Gender,
+                    gcase GrammaticalCase) []byte {
+    for i, c := range digits {
+        if i!=0 { dst = append(dst, '-') }
+        dst = append(dst, englishOnes[c-'0']...)
+    }
+    return dst
+}
+
+func (s digitSpeller) AppendCount(dst []byte, digits []byte, unit *UnitName,
+                    gcase GrammaticalCase) []byte {
+    dst = s.AppendWords(dst, digits, unit.Gender, gcase)
+    dst = append(dst, ' ')
+    return append(dst, unit.Form(PluralOther)...)
+}
+
+func (s digitSpeller) Conjunction() string {
+    return "&"
+}
+
+func TestRegisterSpeller(t *testing.T) {
+    if err := RegisterSpeller("en-XX", digitSpeller{}); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    defer UnregisterSpeller("en-XX")
+    if err := RegisterSpeller("!!", digitSpeller{}); err!=ErrInvalidLocale {
+        t.Errorf("Error mismatch: invalid: %v", err)
+    }
+    result, err := UDec128{ 1205, 0 }.Words(WordsOptions{ Lang: "en_XX.UTF-8",
+                Precision: 2, FracDigits: 2, Currency: dollarNames })
+    if result!="one-two dollars & five cents" || err!=nil {
+        t.Errorf("Result mismatch: custom: %q,%v", result, err)
+    }
+    // built-in speller can be overriden
+    if err := RegisterSpeller("de", digitSpeller{}); err!=nil {
+        t.Fatalf("Unexpected error: %v", err)
+    }
+    result, err = UDec128{ 12, 0 }.Words(WordsOptions{ Lang: "de-AT" })
+    UnregisterSpeller("de")
+    if result!="one-two" || err!=nil {
+        t.Errorf("Result mismatch: override: %q,%v", result, err)
+    }
+    result, err = UDec128{ 12, 0 }.Words(WordsOptions{ Lang: "de-AT" })
+    if result!="zwölf" || err!=nil {
+        t.Errorf("Result mismatch: unregister: %q,%v", result, err)
+    }
+}